@@ -0,0 +1,311 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cjwagner/httpcache"
+	"github.com/sirupsen/logrus"
+)
+
+// invalidationGracePeriod is how long after a change of a resource responses
+// are not trusted to be fresh, as GitHub may serve stale data for a short
+// while after sending the webhook.
+const invalidationGracePeriod = 10 * time.Second
+
+// webhookDeliveryWindow is how long after the last webhook of a repo its cache
+// entries are trusted to be fresh. The entries of the repos whose webhooks
+// don't reach ghproxy are always revalidated.
+const webhookDeliveryWindow = time.Hour
+
+var (
+	issueResourceRe  = regexp.MustCompile(`/repos/([^/]+)/([^/]+)/(?:pulls|issues)/(\d+)(?:/.*)?$`)
+	pullRequestRe    = regexp.MustCompile(`/repos/[^/]+/[^/]+/pulls/\d+$`)
+	labelsResourceRe = regexp.MustCompile(`/repos/([^/]+)/([^/]+)/labels(?:/.*)?$`)
+	commitResourceRe = regexp.MustCompile(`/repos/([^/]+)/([^/]+)/(?:commits/([0-9a-f]{40})/status(?:es)?|statuses/([0-9a-f]{40}))$`)
+)
+
+func repoResource(org, repo string) string {
+	return strings.ToLower(org + "/" + repo)
+}
+
+func issueResource(org, repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repoResource(org, repo), number)
+}
+
+func commitResource(org, repo, sha string) string {
+	return repoResource(org, repo) + "@" + strings.ToLower(sha)
+}
+
+// resourcesForPath returns the resources a request path depends on. Only the
+// resources whose changes GitHub sends webhooks for are tracked, so no
+// resources are returned for any other path.
+func resourcesForPath(path string) []string {
+	if m := issueResourceRe.FindStringSubmatch(path); m != nil {
+		return []string{repoResource(m[1], m[2]), repoResource(m[1], m[2]) + "#" + m[3]}
+	}
+	if m := labelsResourceRe.FindStringSubmatch(path); m != nil {
+		return []string{repoResource(m[1], m[2])}
+	}
+	if m := commitResourceRe.FindStringSubmatch(path); m != nil {
+		sha := m[3]
+		if sha == "" {
+			sha = m[4]
+		}
+		return []string{repoResource(m[1], m[2]), commitResource(m[1], m[2], sha)}
+	}
+	return nil
+}
+
+// webhookEvent holds the fields of the GitHub webhooks used to find the
+// resources they change.
+type webhookEvent struct {
+	Repo struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Number int `json:"number"`
+	Issue  *struct {
+		Number int `json:"number"`
+	} `json:"issue"`
+	PullRequest *struct {
+		Number int `json:"number"`
+	} `json:"pull_request"`
+	SHA string `json:"sha"`
+}
+
+// FreshnessTracker tracks the cache entries that are known to be fresh, which
+// are served without being revalidated with GitHub. An entry is fresh if it
+// was validated less than a TTL ago and no webhook reported a change of the
+// resources it depends on since then: pull requests, issues, labels and
+// commit statuses. The TTL bounds the staleness caused by lost webhooks.
+// Only the entries of the repos that delivered a webhook recently are trusted.
+//
+// The state is held in memory, so every replica of ghproxy sharing a cache
+// would have to receive all the webhooks.
+type FreshnessTracker struct {
+	lock sync.Mutex
+	ttl  time.Duration
+	now  func() time.Time
+	// validated holds the time the entries were last validated at per cache
+	// partition and URL.
+	validated map[string]time.Time
+	// changed holds the time the resources were last changed at.
+	changed map[string]time.Time
+	// delivered holds the time the repos last delivered a webhook at.
+	delivered map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewFreshnessTracker returns a FreshnessTracker trusting validated entries
+// for the given TTL.
+func NewFreshnessTracker(ttl time.Duration) *FreshnessTracker {
+	return &FreshnessTracker{
+		ttl:       ttl,
+		now:       time.Now,
+		validated: map[string]time.Time{},
+		changed:   map[string]time.Time{},
+		delivered: map[string]time.Time{},
+	}
+}
+
+// Invalidate marks the resources changed by a GitHub webhook as stale. Events
+// that don't change any tracked resource only record that their repo delivers
+// webhooks.
+func (t *FreshnessTracker) Invalidate(eventType string, payload []byte) error {
+	var event webhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal %s event: %w", eventType, err)
+	}
+	org, repo, found := strings.Cut(event.Repo.FullName, "/")
+	if !found {
+		return nil
+	}
+
+	var resource string
+	switch eventType {
+	case "pull_request", "pull_request_review", "pull_request_review_comment":
+		number := event.Number
+		if event.PullRequest != nil {
+			number = event.PullRequest.Number
+		}
+		resource = issueResource(org, repo, number)
+	case "issues", "issue_comment":
+		if event.Issue != nil {
+			resource = issueResource(org, repo, event.Issue.Number)
+		}
+	case "status":
+		resource = commitResource(org, repo, event.SHA)
+	case "label", "repository":
+		// Changes of labels apply to the issues and pull requests using them.
+		resource = repoResource(org, repo)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	t.delivered[repoResource(org, repo)] = now
+	if resource != "" {
+		t.changed[resource] = now
+	}
+	t.prune()
+	return nil
+}
+
+func validatedKey(partition string, req *http.Request) string {
+	return partition + " " + req.URL.String()
+}
+
+// isFresh returns whether the cache entry for the request is known to be fresh.
+func (t *FreshnessTracker) isFresh(partition string, req *http.Request) bool {
+	resources := resourcesForPath(req.URL.Path)
+	if len(resources) == 0 {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	validatedAt, ok := t.validated[validatedKey(partition, req)]
+	if !ok || t.now().Sub(validatedAt) > t.ttl {
+		return false
+	}
+	// The first resource is always the repo.
+	if deliveredAt, ok := t.delivered[resources[0]]; !ok || t.now().Sub(deliveredAt) > webhookDeliveryWindow {
+		return false
+	}
+	for _, resource := range resources {
+		if changedAt, ok := t.changed[resource]; ok && !validatedAt.After(changedAt.Add(invalidationGracePeriod)) {
+			return false
+		}
+	}
+	return true
+}
+
+// markValidated records that the cache entry for the request was validated
+// with GitHub at the given time.
+func (t *FreshnessTracker) markValidated(partition string, req *http.Request, validatedAt time.Time) {
+	if len(resourcesForPath(req.URL.Path)) == 0 {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.validated[validatedKey(partition, req)] = validatedAt
+	t.prune()
+}
+
+// prune drops the entries older than the TTL, which can't affect the
+// freshness anymore. It must be called with the lock held.
+func (t *FreshnessTracker) prune() {
+	now := t.now()
+	if now.Sub(t.lastPrune) < t.ttl {
+		return
+	}
+	t.lastPrune = now
+	for key, validatedAt := range t.validated {
+		if now.Sub(validatedAt) > t.ttl {
+			delete(t.validated, key)
+		}
+	}
+	for resource, changedAt := range t.changed {
+		if now.Sub(changedAt) > t.ttl+invalidationGracePeriod {
+			delete(t.changed, resource)
+		}
+	}
+	for repo, deliveredAt := range t.delivered {
+		if now.Sub(deliveredAt) > webhookDeliveryWindow {
+			delete(t.delivered, repo)
+		}
+	}
+}
+
+// freshnessTransport serves the cache entries known to be fresh without
+// revalidating them and records when the other entries get validated.
+type freshnessTransport struct {
+	partition string
+	tracker   *FreshnessTracker
+	cache     httpcache.Cache
+	delegate  http.RoundTripper
+}
+
+func (f *freshnessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return f.delegate.RoundTrip(req)
+	}
+
+	if f.tracker.isFresh(f.partition, req) {
+		resp, err := httpcache.CachedResponse(f.cache, req)
+		if err != nil {
+			logrus.WithField("cache-key", req.URL.String()).WithError(err).Warn("Failed to load fresh cache entry.")
+		} else if resp != nil && varyMatches(resp, req) {
+			if req.Body != nil {
+				req.Body.Close() // Since we won't pass the request we must close it.
+			}
+			resp.Header.Set(CacheModeHeader, string(ModeFresh))
+			return resp, nil
+		}
+	}
+
+	validatedAt := f.tracker.now()
+	resp, err := f.delegate.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK || cacheResponseMode(resp.Header) == ModeNoStore {
+		return resp, err
+	}
+	if pullRequestRe.MatchString(req.URL.Path) {
+		// GitHub computes the mergeability of pull requests in the background
+		// without sending a webhook once it is done, so the response is only
+		// trusted once it is known.
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		var pr struct {
+			State     string `json:"state"`
+			Mergeable *bool  `json:"mergeable"`
+		}
+		if err := json.Unmarshal(body, &pr); err != nil || (pr.State == "open" && pr.Mergeable == nil) {
+			return resp, nil
+		}
+	}
+	f.tracker.markValidated(f.partition, req, validatedAt)
+	return resp, nil
+}
+
+// varyMatches mirrors the check httpcache does before using a cache entry:
+// the request headers listed in the Vary header of the response must match
+// the ones of the request the response was cached for.
+func varyMatches(cachedResp *http.Response, req *http.Request) bool {
+	for _, vary := range cachedResp.Header.Values("Vary") {
+		for _, header := range strings.Split(vary, ",") {
+			header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+			if header != "" && req.Header.Get(header) != cachedResp.Header.Get("X-Varied-"+header) {
+				return false
+			}
+		}
+	}
+	return true
+}
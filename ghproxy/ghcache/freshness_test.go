@@ -0,0 +1,243 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cjwagner/httpcache"
+)
+
+const sha = "0123456789abcdef0123456789abcdef01234567"
+
+type webhook struct {
+	eventType string
+	payload   string
+}
+
+func TestFreshnessTracker(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		changedBefore []webhook
+		wait          time.Duration
+		changedAfter  []webhook
+		age           time.Duration
+		// noDelivery skips the webhook showing that the repo delivers webhooks.
+		noDelivery bool
+		expected   bool
+	}{
+		{
+			name:     "validated entry is fresh",
+			path:     "/repos/org/repo/pulls/1",
+			expected: true,
+		},
+		{
+			name: "untracked entry is never fresh",
+			path: "/repos/org/repo/git/refs/heads/main",
+		},
+		{
+			name: "entry of a repo without webhooks is never fresh",
+			path: "/repos/org/other/pulls/1",
+		},
+		{
+			name:          "entry of a repo without recent webhooks is never fresh",
+			path:          "/repos/org/repo/pulls/1",
+			changedBefore: []webhook{{"push", `{"repository":{"full_name":"org/repo"}}`}},
+			wait:          webhookDeliveryWindow + time.Second,
+			noDelivery:    true,
+		},
+		{
+			name: "entry is stale after the TTL",
+			path: "/repos/org/repo/pulls/1",
+			age:  time.Minute + time.Second,
+		},
+		{
+			name:         "pull request event invalidates the pull request",
+			path:         "/repos/org/repo/pulls/1/files",
+			changedAfter: []webhook{{"pull_request", `{"number":1,"pull_request":{"number":1},"repository":{"full_name":"org/repo"}}`}},
+		},
+		{
+			name:         "issue comment invalidates the labels of the issue",
+			path:         "/repos/org/repo/issues/1/labels",
+			changedAfter: []webhook{{"issue_comment", `{"issue":{"number":1},"repository":{"full_name":"org/repo"}}`}},
+		},
+		{
+			name:         "event of another pull request keeps the entry fresh",
+			path:         "/repos/org/repo/pulls/1",
+			changedAfter: []webhook{{"pull_request", `{"number":2,"pull_request":{"number":2},"repository":{"full_name":"org/repo"}}`}},
+			expected:     true,
+		},
+		{
+			name:         "event of another repository keeps the entry fresh",
+			path:         "/repos/org/repo/pulls/1",
+			changedAfter: []webhook{{"pull_request", `{"number":1,"pull_request":{"number":1},"repository":{"full_name":"org/other"}}`}},
+			expected:     true,
+		},
+		{
+			name:         "repositories are case insensitive",
+			path:         "/repos/org/repo/issues/1",
+			changedAfter: []webhook{{"issues", `{"issue":{"number":1},"repository":{"full_name":"Org/Repo"}}`}},
+		},
+		{
+			name:         "label event invalidates the issues of the repository",
+			path:         "/repos/org/repo/issues/1/labels",
+			changedAfter: []webhook{{"label", `{"repository":{"full_name":"org/repo"}}`}},
+		},
+		{
+			name:         "status event invalidates the combined status",
+			path:         "/repos/org/repo/commits/" + sha + "/status",
+			changedAfter: []webhook{{"status", `{"sha":"` + sha + `","repository":{"full_name":"org/repo"}}`}},
+		},
+		{
+			name:         "untracked event keeps the entry fresh",
+			path:         "/repos/org/repo/statuses/" + sha,
+			changedAfter: []webhook{{"push", `{"repository":{"full_name":"org/repo"}}`}},
+			expected:     true,
+		},
+		{
+			name:          "entry validated right after a change is not trusted",
+			path:          "/repos/org/repo/pulls/1",
+			changedBefore: []webhook{{"pull_request", `{"number":1,"pull_request":{"number":1},"repository":{"full_name":"org/repo"}}`}},
+			wait:          time.Second,
+		},
+		{
+			name:          "entry validated after the grace period of a change is fresh",
+			path:          "/repos/org/repo/pulls/1",
+			changedBefore: []webhook{{"pull_request", `{"number":1,"pull_request":{"number":1},"repository":{"full_name":"org/repo"}}`}},
+			wait:          invalidationGracePeriod + time.Second,
+			expected:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+			tracker := NewFreshnessTracker(time.Minute)
+			tracker.now = func() time.Time { return now }
+			invalidate := func(webhooks []webhook) {
+				for _, hook := range webhooks {
+					if err := tracker.Invalidate(hook.eventType, []byte(hook.payload)); err != nil {
+						t.Fatalf("Failed to invalidate: %v", err)
+					}
+				}
+			}
+			req := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: tc.path}}
+
+			if !tc.noDelivery {
+				invalidate([]webhook{{"ping", `{"repository":{"full_name":"org/repo"}}`}})
+			}
+			invalidate(tc.changedBefore)
+			now = now.Add(tc.wait)
+			tracker.markValidated("partition", req, now)
+			invalidate(tc.changedAfter)
+			now = now.Add(tc.age)
+
+			if actual := tracker.isFresh("partition", req); actual != tc.expected {
+				t.Errorf("Expected fresh to be %t, got %t.", tc.expected, actual)
+			}
+			if tracker.isFresh("other-partition", req) {
+				t.Error("Expected the entry not to be fresh in another partition.")
+			}
+		})
+	}
+}
+
+// fakeUpstream counts the requests and responds with an open pull request.
+type fakeUpstream struct {
+	hits      int
+	mergeable string
+}
+
+func (f *fakeUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.hits++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Cache-Control": []string{"no-cache"}, "Etag": []string{`"etag"`}},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"state":"open","mergeable":` + f.mergeable + `}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestFreshnessTransport(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mergeable    string
+		expectedHits int
+		expectedMode CacheResponseMode
+	}{
+		{
+			name:         "fresh entry is served from the cache",
+			mergeable:    "true",
+			expectedHits: 1,
+			expectedMode: ModeFresh,
+		},
+		{
+			name:         "pull request of unknown mergeability is revalidated",
+			mergeable:    "null",
+			expectedHits: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			upstream := &fakeUpstream{mergeable: tc.mergeable}
+			cache := httpcache.NewMemoryCache()
+			cacheTransport := httpcache.NewTransport(cache)
+			cacheTransport.Transport = upstream
+			tracker := NewFreshnessTracker(time.Minute)
+			if err := tracker.Invalidate("ping", []byte(`{"repository":{"full_name":"org/repo"}}`)); err != nil {
+				t.Fatalf("Failed to invalidate: %v", err)
+			}
+			transport := &freshnessTransport{
+				partition: "partition",
+				tracker:   tracker,
+				cache:     cache,
+				delegate:  cacheTransport,
+			}
+
+			var resp *http.Response
+			for i := 0; i < 2; i++ {
+				req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org/repo/pulls/1", nil)
+				if err != nil {
+					t.Fatalf("Failed to create request: %v", err)
+				}
+				if resp, err = transport.RoundTrip(req); err != nil {
+					t.Fatalf("Failed to run request: %v", err)
+				}
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("Failed to read the response: %v", err)
+				}
+				if expected := `{"state":"open","mergeable":` + tc.mergeable + `}`; string(body) != expected {
+					t.Errorf("Expected body %q, got %q.", expected, string(body))
+				}
+			}
+			if upstream.hits != tc.expectedHits {
+				t.Errorf("Expected %d requests upstream, got %d.", tc.expectedHits, upstream.hits)
+			}
+			if mode := CacheResponseMode(resp.Header.Get(CacheModeHeader)); mode != tc.expectedMode {
+				t.Errorf("Expected cache mode %q, got %q.", tc.expectedMode, mode)
+			}
+		})
+	}
+}
@@ -67,6 +67,8 @@ const (
 	// free (no API tokens used).
 	ModeCoalesced   CacheResponseMode = "COALESCED"   // coalesced request, this is a copied response
 	ModeRevalidated CacheResponseMode = "REVALIDATED" // cached value revalidated and returned
+	ModeFresh       CacheResponseMode = "FRESH"       // cached value known to be fresh from webhooks and returned
+	ModeCached      CacheResponseMode = "CACHED"      // cached GraphQL response younger than the TTL returned
 
 	// cacheEntryCreationDateHeader contains the creation date of the cache entry
 	cacheEntryCreationDateHeader = "X-PROW-REQUEST-DATE"
@@ -126,6 +128,10 @@ func CacheModeIsFree(mode CacheResponseMode) bool {
 		return true
 	case ModeRevalidated:
 		return true
+	case ModeFresh:
+		return true
+	case ModeCached:
+		return true
 	case ModeError:
 		// In this case we did not successfully communicate with the GH API, so no
 		// token is used, but we also don't return a response, so ModeError won't
@@ -165,6 +171,9 @@ func init() {
 }
 
 func cacheResponseMode(headers http.Header) CacheResponseMode {
+	if headers.Get(CacheModeHeader) == string(ModeFresh) {
+		return ModeFresh
+	}
 	if strings.Contains(headers.Get("Cache-Control"), "no-store") {
 		return ModeNoStore
 	}
@@ -284,7 +293,7 @@ func (c *throttlingTransport) holdRequest(req *http.Request) {
 	tokenBudgetName := c.getTokenBudgetName(req)
 	getReq := req.Method == http.MethodGet
 	var duration time.Duration
-	if strings.HasPrefix(req.URL.Path, "graphql") || strings.HasPrefix(req.URL.Path, "/graphql") {
+	if isGraphQLPath(req.URL.Path) {
 		duration = c.registryApiV4.getRequestWaitDuration(tokenBudgetName, getReq)
 		ghmetrics.CollectGitHubRequestWaitDurationMetrics(tokenBudgetName, req.Method, apiV4, duration)
 	} else {
@@ -367,7 +376,7 @@ func (u upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 
 	apiVersion := apiV3
-	if strings.HasPrefix(req.URL.Path, "graphql") || strings.HasPrefix(req.URL.Path, "/graphql") {
+	if isGraphQLPath(req.URL.Path) {
 		resp.Header.Set("Cache-Control", "no-store")
 		apiVersion = apiV4
 	}
@@ -383,7 +392,7 @@ const LogMessageWithDiskPartitionFields = "Not using a partitioned cache because
 // NewDiskCache creates a GitHub cache RoundTripper that is backed by a disk
 // cache.
 // It supports a partitioned cache.
-func NewDiskCache(roundTripper http.RoundTripper, cacheDir string, cacheSizeGB, maxConcurrency int, legacyDisablePartitioningByAuthHeader bool, cachePruneInterval time.Duration, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+func NewDiskCache(roundTripper http.RoundTripper, cacheDir string, cacheSizeGB, maxConcurrency int, legacyDisablePartitioningByAuthHeader bool, cachePruneInterval time.Duration, throttlingTimes RequestThrottlingTimes, freshness *FreshnessTracker, graphQLCacheTTL time.Duration) http.RoundTripper {
 	if legacyDisablePartitioningByAuthHeader {
 		diskCache := diskcache.NewWithDiskv(
 			diskv.New(diskv.Options{
@@ -400,6 +409,8 @@ func NewDiskCache(roundTripper http.RoundTripper, cacheDir string, cacheSizeGB,
 			},
 			maxConcurrency,
 			throttlingTimes,
+			freshness,
+			graphQLCacheTTL,
 		)
 	}
 
@@ -424,6 +435,8 @@ func NewDiskCache(roundTripper http.RoundTripper, cacheDir string, cacheSizeGB,
 		},
 		maxConcurrency,
 		throttlingTimes,
+		freshness,
+		graphQLCacheTTL,
 	)
 }
 
@@ -499,28 +512,44 @@ type cachePartitionMetadata struct {
 // NewMemCache creates a GitHub cache RoundTripper that is backed by a memory
 // cache.
 // It supports a partitioned cache.
-func NewMemCache(roundTripper http.RoundTripper, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+func NewMemCache(roundTripper http.RoundTripper, maxConcurrency int, throttlingTimes RequestThrottlingTimes, freshness *FreshnessTracker, graphQLCacheTTL time.Duration) http.RoundTripper {
 	return NewFromCache(roundTripper,
 		func(_ string, _ *time.Time) httpcache.Cache { return httpcache.NewMemoryCache() },
 		maxConcurrency,
-		throttlingTimes)
+		throttlingTimes,
+		freshness,
+		graphQLCacheTTL)
 }
 
 // CachePartitionCreator creates a new cache partition using the given key
 type CachePartitionCreator func(partitionKey string, expiresAt *time.Time) httpcache.Cache
 
 // NewFromCache creates a GitHub cache RoundTripper that is backed by the
-// specified httpcache.Cache implementation.
-func NewFromCache(roundTripper http.RoundTripper, cache CachePartitionCreator, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+// specified httpcache.Cache implementation. If freshness is not nil, the
+// cache entries it knows to be fresh are served without revalidation.
+// Concurrent identical GraphQL queries are coalesced and, if graphQLCacheTTL
+// is positive, their successful responses are cached for that long.
+func NewFromCache(roundTripper http.RoundTripper, cache CachePartitionCreator, maxConcurrency int, throttlingTimes RequestThrottlingTimes, freshness *FreshnessTracker, graphQLCacheTTL time.Duration) http.RoundTripper {
 	hasher := ghmetrics.NewCachingHasher()
 	return newPartitioningRoundTripper(func(partitionKey string, expiresAt *time.Time) http.RoundTripper {
-		cacheTransport := httpcache.NewTransport(cache(partitionKey, expiresAt))
+		partitionCache := cache(partitionKey, expiresAt)
+		cacheTransport := httpcache.NewTransport(partitionCache)
 		cacheTransport.Transport = newThrottlingTransport(maxConcurrency, upstreamTransport{roundTripper: roundTripper, hasher: hasher}, hasher, throttlingTimes)
-		return &requestCoalescer{
+		var requestExecutor http.RoundTripper = cacheTransport
+		if freshness != nil {
+			requestExecutor = &freshnessTransport{
+				partition: partitionKey,
+				tracker:   freshness,
+				cache:     partitionCache,
+				delegate:  cacheTransport,
+			}
+		}
+		coalescer := &requestCoalescer{
 			cache:           make(map[string]*firstRequest),
-			requestExecutor: cacheTransport,
+			requestExecutor: requestExecutor,
 			hasher:          hasher,
 		}
+		return newGraphQLCache(graphQLCacheTTL, requestExecutor, coalescer, hasher)
 	})
 }
 
@@ -529,7 +558,7 @@ func NewFromCache(roundTripper http.RoundTripper, cache CachePartitionCreator, m
 // Important note: The redis implementation does not support partitioning the cache
 // which means that requests to the same path from different tokens will invalidate
 // each other.
-func NewRedisCache(roundTripper http.RoundTripper, redisAddress string, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+func NewRedisCache(roundTripper http.RoundTripper, redisAddress string, maxConcurrency int, throttlingTimes RequestThrottlingTimes, freshness *FreshnessTracker, graphQLCacheTTL time.Duration) http.RoundTripper {
 	conn, err := redis.Dial("tcp", redisAddress)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error connecting to Redis")
@@ -538,5 +567,7 @@ func NewRedisCache(roundTripper http.RoundTripper, redisAddress string, maxConcu
 	return NewFromCache(roundTripper,
 		func(_ string, _ *time.Time) httpcache.Cache { return redisCache },
 		maxConcurrency,
-		throttlingTimes)
+		throttlingTimes,
+		freshness,
+		graphQLCacheTTL)
 }
@@ -0,0 +1,257 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/ghproxy/ghmetrics"
+)
+
+var (
+	graphQLNamedOperationRe = regexp.MustCompile(`^\s*(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+	graphQLFirstFieldRe     = regexp.MustCompile(`\{\s*([_A-Za-z][_0-9A-Za-z]*)`)
+	graphQLMutationRe       = regexp.MustCompile(`^\s*(?:mutation|subscription)\b`)
+)
+
+func isGraphQLPath(path string) bool {
+	return strings.HasPrefix(path, "graphql") || strings.HasPrefix(path, "/graphql")
+}
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	OperationName string          `json:"operationName,omitempty"`
+}
+
+// name returns the name of the operation of the request. The GitHub client of
+// Prow sends anonymous queries, which are named after their first field.
+func (r graphQLRequest) name() string {
+	if r.OperationName != "" {
+		return r.OperationName
+	}
+	if m := graphQLNamedOperationRe.FindStringSubmatch(r.Query); m != nil {
+		return m[1]
+	}
+	if m := graphQLFirstFieldRe.FindStringSubmatch(r.Query); m != nil {
+		return m[1]
+	}
+	return "unknown"
+}
+
+// key returns the cache key of the request. Queries are normalized by
+// collapsing their whitespace and variables by sorting their keys, so that
+// equivalent requests share their cache entry.
+func (r graphQLRequest) key(accept string) (string, error) {
+	var variables interface{}
+	if len(r.Variables) > 0 {
+		if err := json.Unmarshal(r.Variables, &variables); err != nil {
+			return "", fmt.Errorf("failed to unmarshal variables: %w", err)
+		}
+	}
+	normalizedVariables, err := json.Marshal(variables)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal variables: %w", err)
+	}
+	hash := sha256.New()
+	for _, part := range []string{accept, r.OperationName, strings.Join(strings.Fields(r.Query), " "), string(normalizedVariables)} {
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// graphQLEntry is a GraphQL response that is either being fetched or cached.
+type graphQLEntry struct {
+	// done is closed once the response is fetched.
+	done      chan struct{}
+	resp      []byte
+	err       error
+	expiresAt time.Time
+}
+
+// graphQLCache caches successful responses to GraphQL queries for a short
+// TTL and coalesces concurrent identical queries. Unlike the REST API,
+// GraphQL responses can't be revalidated with conditional requests, so a
+// cache hit may be up to a TTL old. Requests that aren't GraphQL queries are
+// passed to the delegate.
+type graphQLCache struct {
+	lock      sync.Mutex
+	entries   map[string]*graphQLEntry
+	ttl       time.Duration
+	now       func() time.Time
+	lastPrune time.Time
+
+	// requestExecutor executes the GraphQL queries that can't be answered
+	// from the cache.
+	requestExecutor http.RoundTripper
+	// delegate handles all other requests.
+	delegate http.RoundTripper
+
+	hasher ghmetrics.Hasher
+}
+
+func newGraphQLCache(ttl time.Duration, requestExecutor, delegate http.RoundTripper, hasher ghmetrics.Hasher) *graphQLCache {
+	return &graphQLCache{
+		entries:         map[string]*graphQLEntry{},
+		ttl:             ttl,
+		now:             time.Now,
+		requestExecutor: requestExecutor,
+		delegate:        delegate,
+		hasher:          hasher,
+	}
+}
+
+func (g *graphQLCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || !isGraphQLPath(req.URL.Path) || req.Body == nil {
+		return g.delegate.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	// The body was consumed, so it must be restored for the request to be
+	// sent upstream.
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+
+	var query graphQLRequest
+	if err := json.Unmarshal(body, &query); err != nil || graphQLMutationRe.MatchString(query.Query) {
+		return g.delegate.RoundTrip(req)
+	}
+	key, err := query.key(req.Header.Get("Accept"))
+	if err != nil {
+		logrus.WithError(err).Debug("Not caching GraphQL query.")
+		return g.delegate.RoundTrip(req)
+	}
+
+	var tokenBudgetName string
+	if val := req.Header.Get(TokenBudgetIdentifierHeader); val != "" {
+		tokenBudgetName = val
+	} else {
+		tokenBudgetName = g.hasher.Hash(req)
+	}
+	cacheMode, resp, err := g.roundTrip(req, key)
+	collectMetrics(cacheMode, req, resp, tokenBudgetName)
+	ghmetrics.CollectGraphQLCacheRequestMetrics(string(cacheMode), query.name(), req.Header.Get("User-Agent"))
+	return resp, err
+}
+
+func (g *graphQLCache) roundTrip(req *http.Request, key string) (CacheResponseMode, *http.Response, error) {
+	g.lock.Lock()
+	entry, ok := g.entries[key]
+	if ok && entry.expiresAt.IsZero() {
+		// Identical query in flight, share its response.
+		g.lock.Unlock()
+		<-entry.done
+		return g.response(ModeCoalesced, entry)
+	}
+	if ok && g.now().Before(entry.expiresAt) {
+		g.lock.Unlock()
+		return g.response(ModeCached, entry)
+	}
+	entry = &graphQLEntry{done: make(chan struct{})}
+	g.entries[key] = entry
+	g.prune()
+	g.lock.Unlock()
+
+	resp, err := g.requestExecutor.RoundTrip(req)
+	cacheMode := ModeNoStore
+	if err != nil {
+		entry.err = err
+	} else {
+		if g.ttl > 0 && cacheableGraphQLResponse(resp) {
+			cacheMode = ModeMiss
+		}
+		entry.resp, entry.err = httputil.DumpResponse(resp, true)
+	}
+
+	g.lock.Lock()
+	if cacheMode == ModeMiss {
+		entry.expiresAt = g.now().Add(g.ttl)
+	} else {
+		delete(g.entries, key)
+	}
+	g.lock.Unlock()
+	// Wake up the coalesced requests only after updating the entry, so that
+	// no new request subscribes to it once it is no longer in flight.
+	close(entry.done)
+
+	if err != nil {
+		logrus.WithField("cache-key", key).WithError(err).Warn("Error from cache transport layer.")
+		return ModeError, nil, err
+	}
+	return cacheMode, resp, nil
+}
+
+func (g *graphQLCache) response(cacheMode CacheResponseMode, entry *graphQLEntry) (CacheResponseMode, *http.Response, error) {
+	if entry.err != nil {
+		return ModeError, nil, entry.err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.resp)), nil)
+	if err != nil {
+		logrus.WithError(err).Error("Error loading response.")
+		return ModeError, nil, err
+	}
+	return cacheMode, resp, nil
+}
+
+// prune drops the expired entries. It must be called with the lock held.
+func (g *graphQLCache) prune() {
+	now := g.now()
+	if now.Sub(g.lastPrune) < g.ttl {
+		return
+	}
+	g.lastPrune = now
+	for key, entry := range g.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(g.entries, key)
+		}
+	}
+}
+
+// cacheableGraphQLResponse returns whether the response is successful. GitHub
+// reports GraphQL errors with a 200 status code, so the body must be checked.
+func cacheableGraphQLResponse(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	var result struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	return json.Unmarshal(body, &result) == nil && len(result.Errors) == 0
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/test-infra/ghproxy/ghmetrics"
+)
+
+const searchQuery = `{"query":"query($query:String!$searchCursor:String){search(type: ISSUE, first: 37, after: $searchCursor, query: $query){issueCount}}","variables":{"query":"is:pr","searchCursor":null}}`
+
+func TestGraphQLRequestName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		request  graphQLRequest
+		expected string
+	}{
+		{
+			name:     "anonymous query is named after its first field",
+			request:  graphQLRequest{Query: "query($query:String!){search(query: $query){issueCount}}"},
+			expected: "search",
+		},
+		{
+			name:     "query without operation type",
+			request:  graphQLRequest{Query: "{ viewer { login } }"},
+			expected: "viewer",
+		},
+		{
+			name:     "named query",
+			request:  graphQLRequest{Query: "query Blockers($query: String!) { search(query: $query) { issueCount } }"},
+			expected: "Blockers",
+		},
+		{
+			name:     "operation name",
+			request:  graphQLRequest{Query: "query A { viewer { login } } query B { rateLimit { cost } }", OperationName: "B"},
+			expected: "B",
+		},
+		{
+			name:     "invalid query",
+			request:  graphQLRequest{Query: "nonsense"},
+			expected: "unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.request.name(); actual != tc.expected {
+				t.Errorf("Expected name %q, got %q.", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGraphQLRequestKey(t *testing.T) {
+	base := graphQLRequest{Query: "query($a: String!, $b: Int!) { search(query: $a, first: $b) { issueCount } }", Variables: []byte(`{"a":"is:pr","b":10}`)}
+	testCases := []struct {
+		name     string
+		request  graphQLRequest
+		accept   string
+		expected bool
+	}{
+		{
+			name:     "identical request",
+			request:  base,
+			expected: true,
+		},
+		{
+			name:     "whitespace and variable order are ignored",
+			request:  graphQLRequest{Query: "query($a: String!, $b: Int!) {\n  search(query: $a, first: $b) {\n    issueCount\n  }\n}", Variables: []byte(`{ "b": 10, "a": "is:pr" }`)},
+			expected: true,
+		},
+		{
+			name:    "different variables",
+			request: graphQLRequest{Query: base.Query, Variables: []byte(`{"a":"is:issue","b":10}`)},
+		},
+		{
+			name:    "different query",
+			request: graphQLRequest{Query: "query($a: String!, $b: Int!) { search(query: $a, first: $b) { nodes { id } } }", Variables: base.Variables},
+		},
+		{
+			name:    "different media type",
+			request: base,
+			accept:  "application/vnd.github.merge-info-preview+json",
+		},
+	}
+
+	expected, err := base.key("")
+	if err != nil {
+		t.Fatalf("Failed to compute key: %v", err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.request.key(tc.accept)
+			if err != nil {
+				t.Fatalf("Failed to compute key: %v", err)
+			}
+			if (actual == expected) != tc.expected {
+				t.Errorf("Expected keys to be equal to be %t, got keys %q and %q.", tc.expected, expected, actual)
+			}
+		})
+	}
+}
+
+// fakeGraphQLExecutor counts the requests and responds with a fixed body. If
+// release is set, it waits for it to be closed before responding.
+type fakeGraphQLExecutor struct {
+	lock    sync.Mutex
+	hits    int
+	body    string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *fakeGraphQLExecutor) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.lock.Lock()
+	f.hits++
+	f.lock.Unlock()
+	if f.release != nil {
+		close(f.started)
+		<-f.release
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewBufferString(f.body)),
+	}, nil
+}
+
+func graphQLPost(t *testing.T, transport http.RoundTripper, body string) CacheResponseMode {
+	req, err := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", bytes.NewBufferString(body))
+	if err != nil {
+		t.Errorf("Failed to create request: %v", err)
+		return ""
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Errorf("Failed to run request: %v", err)
+		return ""
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("Failed to read the response: %v", err)
+	}
+	return CacheResponseMode(resp.Header.Get(CacheModeHeader))
+}
+
+func TestGraphQLCache(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ttl           time.Duration
+		response      string
+		requests      []string
+		wait          time.Duration
+		expectedModes []CacheResponseMode
+		expectedHits  int
+	}{
+		{
+			name:          "identical queries are cached",
+			ttl:           time.Minute,
+			response:      `{"data":{"search":{"issueCount":1}}}`,
+			requests:      []string{searchQuery, searchQuery},
+			expectedModes: []CacheResponseMode{ModeMiss, ModeCached},
+			expectedHits:  1,
+		},
+		{
+			name:          "expired responses are fetched again",
+			ttl:           time.Minute,
+			response:      `{"data":{"search":{"issueCount":1}}}`,
+			requests:      []string{searchQuery, searchQuery},
+			wait:          time.Minute,
+			expectedModes: []CacheResponseMode{ModeMiss, ModeMiss},
+			expectedHits:  2,
+		},
+		{
+			name:          "responses are not cached without a TTL",
+			response:      `{"data":{"search":{"issueCount":1}}}`,
+			requests:      []string{searchQuery, searchQuery},
+			expectedModes: []CacheResponseMode{ModeNoStore, ModeNoStore},
+			expectedHits:  2,
+		},
+		{
+			name:          "responses with errors are not cached",
+			ttl:           time.Minute,
+			response:      `{"errors":[{"message":"Something went wrong"}]}`,
+			requests:      []string{searchQuery, searchQuery},
+			expectedModes: []CacheResponseMode{ModeNoStore, ModeNoStore},
+			expectedHits:  2,
+		},
+		{
+			name:          "mutations are passed to the delegate",
+			ttl:           time.Minute,
+			response:      `{"data":{}}`,
+			requests:      []string{`{"query":"mutation($input:AddCommentInput!){addComment(input: $input){clientMutationId}}"}`},
+			expectedModes: []CacheResponseMode{ModeSkip},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+			executor := &fakeGraphQLExecutor{body: tc.response}
+			hasher := ghmetrics.NewCachingHasher()
+			delegate := &requestCoalescer{
+				cache:           make(map[string]*firstRequest),
+				requestExecutor: &fakeGraphQLExecutor{body: tc.response},
+				hasher:          hasher,
+			}
+			cache := newGraphQLCache(tc.ttl, executor, delegate, hasher)
+			cache.now = func() time.Time { return now }
+
+			var modes []CacheResponseMode
+			for _, body := range tc.requests {
+				modes = append(modes, graphQLPost(t, cache, body))
+				now = now.Add(tc.wait)
+			}
+			if diff := cmp.Diff(tc.expectedModes, modes); diff != "" {
+				t.Errorf("Cache modes mismatch (-want +got):\n%s", diff)
+			}
+			if executor.hits != tc.expectedHits {
+				t.Errorf("Expected %d requests upstream, got %d.", tc.expectedHits, executor.hits)
+			}
+		})
+	}
+}
+
+func TestGraphQLCacheCoalescing(t *testing.T) {
+	t.Parallel()
+	executor := &fakeGraphQLExecutor{
+		body:    `{"data":{"search":{"issueCount":1}}}`,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	hasher := ghmetrics.NewCachingHasher()
+	cache := newGraphQLCache(time.Minute, executor, nil, hasher)
+
+	modes := make(chan CacheResponseMode, 10)
+	wg := sync.WaitGroup{}
+	wg.Add(10)
+	go func() {
+		modes <- graphQLPost(t, cache, searchQuery)
+		wg.Done()
+	}()
+	<-executor.started
+	for i := 0; i < 9; i++ {
+		go func() {
+			modes <- graphQLPost(t, cache, searchQuery)
+			wg.Done()
+		}()
+	}
+	// Requests that don't subscribe before the response is received are
+	// answered from the cache instead, so this doesn't need to be exact.
+	time.Sleep(100 * time.Millisecond)
+	close(executor.release)
+	wg.Wait()
+	close(modes)
+
+	if executor.hits != 1 {
+		t.Errorf("Expected a single request upstream, got %d.", executor.hits)
+	}
+	counts := map[CacheResponseMode]int{}
+	for mode := range modes {
+		counts[mode]++
+	}
+	if counts[ModeMiss] != 1 || counts[ModeCoalesced]+counts[ModeCached] != 9 {
+		t.Errorf("Expected a single miss and nine coalesced or cached responses, got %v.", counts)
+	}
+}
@@ -79,6 +79,16 @@ var cacheCounter = prometheus.NewCounterVec(
 	[]string{"mode", "path", "user_agent", "token_hash"},
 )
 
+// graphQLCacheCounter provides the 'ghcache_graphql_responses' counter vec
+// that is indexed by the cache response mode and the name of the query.
+var graphQLCacheCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ghcache_graphql_responses",
+		Help: "How many GraphQL cache responses of each cache response mode there are per query.",
+	},
+	[]string{"mode", "query_name", "user_agent"},
+)
+
 // timeoutDuration provides the 'github_request_timeouts' histogram that keeps
 // track of the timeouts of GitHub requests by API path.
 var timeoutDuration = prometheus.NewHistogramVec(
@@ -110,6 +120,7 @@ func init() {
 	prometheus.MustRegister(ghRequestDurationHistVec)
 	prometheus.MustRegister(ghRequestWaitDurationHistVec)
 	prometheus.MustRegister(cacheCounter)
+	prometheus.MustRegister(graphQLCacheCounter)
 	prometheus.MustRegister(timeoutDuration)
 	prometheus.MustRegister(cacheEntryAge)
 }
@@ -179,6 +190,11 @@ func CollectCacheRequestMetrics(mode, path, userAgent, tokenHash string) {
 	cacheCounter.With(prometheus.Labels{"mode": mode, "path": simplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent), "token_hash": tokenHash}).Inc()
 }
 
+// CollectGraphQLCacheRequestMetrics records a cache outcome for a GraphQL query
+func CollectGraphQLCacheRequestMetrics(mode, queryName, userAgent string) {
+	graphQLCacheCounter.With(prometheus.Labels{"mode": mode, "query_name": queryName, "user_agent": userAgentWithoutVersion(userAgent)}).Inc()
+}
+
 func CollectCacheEntryAgeMetrics(age float64, path, userAgent, tokenHash string) {
 	cacheEntryAge.With(prometheus.Labels{"path": simplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent), "token_hash": tokenHash}).Observe(age)
 }
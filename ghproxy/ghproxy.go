@@ -36,10 +36,12 @@ import (
 	"k8s.io/test-infra/greenhouse/diskutil"
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/github"
 	"k8s.io/test-infra/prow/interrupts"
 	"k8s.io/test-infra/prow/logrusutil"
 	"k8s.io/test-infra/prow/metrics"
 	"k8s.io/test-infra/prow/pjutil"
+	"k8s.io/test-infra/prow/secret"
 )
 
 var (
@@ -81,7 +83,9 @@ func init() {
 // GitHub reverse proxy HTTP cache RoundTripper stack:
 //  v -   <Client(s)>
 //  v ^ reverse proxy
+//  v ^ ghcache: graphQLCache (GraphQL query caching and coalescing, instrumentation)
 //  v ^ ghcache: downstreamTransport (coalescing, instrumentation)
+//  v ^ ghcache: freshnessTransport (serving entries known fresh from webhooks, optional)
 //  v ^ ghcache: httpcache layer
 //  v ^ ghcache: upstreamTransport (cache-control, instrumentation)
 //  v ^ apptokenequalizer: Make sure all clients get the same app installation token so they can share a cache
@@ -117,6 +121,12 @@ type options struct {
 	instrumentationOptions flagutil.InstrumentationOptions
 
 	timeout uint
+
+	webhookPort       int
+	webhookSecretFile string
+	freshnessTTL      time.Duration
+
+	graphQLCacheTTL time.Duration
 }
 
 func (o *options) validate() error {
@@ -129,6 +139,9 @@ func (o *options) validate() error {
 	if (o.dir == "") != (o.sizeGB == 0) {
 		return errors.New("--cache-dir and --cache-sizeGB must be specified together to enable the disk cache (otherwise a memory cache is used)")
 	}
+	if o.webhookSecretFile != "" && o.redisAddress != "" {
+		return errors.New("--hmac-secret-file can't be used with --redis-address: the webhooks only invalidate the cache entries of the replica that receives them")
+	}
 	upstreamURL, err := url.Parse(o.upstream)
 	if err != nil {
 		return fmt.Errorf("failed to parse upstream URL: %w", err)
@@ -156,6 +169,10 @@ func flagOptions() *options {
 	flag.StringVar(&o.logLevel, "log-level", "debug", fmt.Sprintf("Log level is one of %v.", logrus.AllLevels))
 	flag.BoolVar(&o.serveMetrics, "serve-metrics", false, "If true, it serves prometheus metrics")
 	flag.UintVar(&o.timeout, "request-timeout", 30, "Request timeout which applies also to paged requests. Default is 30 seconds.")
+	flag.IntVar(&o.webhookPort, "webhook-port", 8889, "Port to listen on for GitHub webhooks if --hmac-secret-file is specified.")
+	flag.StringVar(&o.webhookSecretFile, "hmac-secret-file", "", "Path to the file containing the GitHub HMAC secret. If specified, GitHub webhooks are accepted on --webhook-port to invalidate the cache entries of the resources they change, and the other cache entries are served without revalidation. Not supported with --redis-address.")
+	flag.DurationVar(&o.graphQLCacheTTL, "graphql-cache-ttl", 0, "How long successful responses to GraphQL queries are cached. GraphQL responses can't be revalidated, so cached responses may be stale for up to this long. Concurrent identical queries are coalesced regardless.")
+	flag.DurationVar(&o.freshnessTTL, "webhook-freshness-ttl", 5*time.Minute, "How long cache entries are served without revalidation when GitHub webhooks are accepted. Bounds the staleness caused by missed webhooks.")
 	o.instrumentationOptions.AddFlags(flag.CommandLine)
 	return o
 }
@@ -189,7 +206,17 @@ func main() {
 		ServeMetrics: o.serveMetrics,
 	}, o.instrumentationOptions.MetricsPort)
 
-	proxy := proxy(o, http.DefaultTransport, time.Hour)
+	var freshness *ghcache.FreshnessTracker
+	if o.webhookSecretFile != "" {
+		if err := secret.Add(o.webhookSecretFile); err != nil {
+			logrus.WithError(err).Fatal("Error starting secrets agent.")
+		}
+		freshness = ghcache.NewFreshnessTracker(o.freshnessTTL)
+		webhookServer := &http.Server{Addr: ":" + strconv.Itoa(o.webhookPort), Handler: newWebhookHandler(freshness, secret.GetTokenGenerator(o.webhookSecretFile))}
+		interrupts.ListenAndServe(webhookServer, 5*time.Second)
+	}
+
+	proxy := proxy(o, http.DefaultTransport, time.Hour, freshness)
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: proxy}
 
 	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
@@ -198,15 +225,15 @@ func main() {
 	interrupts.ListenAndServe(server, time.Duration(o.timeout)*time.Second)
 }
 
-func proxy(o *options, upstreamTransport http.RoundTripper, diskCachePruneInterval time.Duration) http.Handler {
+func proxy(o *options, upstreamTransport http.RoundTripper, diskCachePruneInterval time.Duration, freshness *ghcache.FreshnessTracker) http.Handler {
 	var cache http.RoundTripper
 	throttlingTimes := ghcache.NewRequestThrottlingTimes(o.requestThrottlingTime, o.requestThrottlingTimeV4, o.requestThrottlingTimeForGET, o.requestThrottlingMaxDelayTime, o.requestThrottlingMaxDelayTimeV4)
 	if o.redisAddress != "" {
-		cache = ghcache.NewRedisCache(apptokenequalizer.New(upstreamTransport), o.redisAddress, o.maxConcurrency, throttlingTimes)
+		cache = ghcache.NewRedisCache(apptokenequalizer.New(upstreamTransport), o.redisAddress, o.maxConcurrency, throttlingTimes, freshness, o.graphQLCacheTTL)
 	} else if o.dir == "" {
-		cache = ghcache.NewMemCache(apptokenequalizer.New(upstreamTransport), o.maxConcurrency, throttlingTimes)
+		cache = ghcache.NewMemCache(apptokenequalizer.New(upstreamTransport), o.maxConcurrency, throttlingTimes, freshness, o.graphQLCacheTTL)
 	} else {
-		cache = ghcache.NewDiskCache(apptokenequalizer.New(upstreamTransport), o.dir, o.sizeGB, o.maxConcurrency, o.diskCacheDisableAuthHeaderPartitioning, diskCachePruneInterval, throttlingTimes)
+		cache = ghcache.NewDiskCache(apptokenequalizer.New(upstreamTransport), o.dir, o.sizeGB, o.maxConcurrency, o.diskCacheDisableAuthHeaderPartitioning, diskCachePruneInterval, throttlingTimes, freshness, o.graphQLCacheTTL)
 		go diskMonitor(o.pushGatewayInterval, o.dir)
 	}
 
@@ -227,6 +254,22 @@ func newReverseProxy(upstreamURL *url.URL, transport http.RoundTripper, timeout
 	return http.TimeoutHandler(proxy, timeout, fmt.Sprintf("ghproxy timed out after %v", timeout))
 }
 
+// newWebhookHandler accepts GitHub webhooks, either sent by GitHub or
+// forwarded by hook as an external plugin, to invalidate the cache entries of
+// the resources they change.
+func newWebhookHandler(freshness *ghcache.FreshnessTracker, tokenGenerator func() []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventType, eventGUID, payload, ok, _ := github.ValidateWebhook(w, r, tokenGenerator)
+		if !ok {
+			return
+		}
+		fmt.Fprint(w, "Event received. Have a nice day.")
+		if err := freshness.Invalidate(eventType, payload); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"event-type": eventType, github.EventGUID: eventGUID}).Warn("Failed to invalidate the cache entries changed by the event.")
+		}
+	})
+}
+
 // helper to update disk metrics (copied from greenhouse)
 func diskMonitor(interval time.Duration, diskRoot string) {
 	logger := logrus.WithField("sync-loop", "disk-monitor")
@@ -350,7 +350,9 @@ func (rac *RerunAuthConfig) IsAllowAnyone() bool {
 }
 
 type ReporterConfig struct {
-	Slack *SlackReporterConfig `json:"slack,omitempty"`
+	Slack   *SlackReporterConfig   `json:"slack,omitempty"`
+	Webhook *WebhookReporterConfig `json:"webhook,omitempty"`
+	Lark    *LarkReporterConfig    `json:"lark,omitempty"`
 }
 
 type SlackReporterConfig struct {
@@ -358,6 +360,12 @@ type SlackReporterConfig struct {
 	Channel           string         `json:"channel,omitempty"`
 	JobStatesToReport []ProwJobState `json:"job_states_to_report,omitempty"`
 	ReportTemplate    string         `json:"report_template,omitempty"`
+	// MessageMode defines how the successive states of a job are reported,
+	// one of "new" (default), "update" or "thread".
+	MessageMode SlackMessageMode `json:"message_mode,omitempty"`
+	// BlockKit formats the messages with Block Kit, adding the state of the
+	// job and a button linking to its logs to the report template.
+	BlockKit *bool `json:"block_kit,omitempty"`
 	// Report is derived from JobStatesToReport, it's used for differentiating
 	// nil from empty slice, as yaml roundtrip by design can't tell the
 	// difference when omitempty is supplied.
@@ -370,6 +378,21 @@ type SlackReporterConfig struct {
 	Report *bool `json:"report,omitempty"`
 }
 
+// SlackMessageMode defines how the Slack reporter reports the successive
+// states of a job.
+type SlackMessageMode string
+
+const (
+	// SlackMessageModeNew posts a new message for every reported state.
+	SlackMessageModeNew SlackMessageMode = "new"
+	// SlackMessageModeUpdate updates the first message posted for the job
+	// with its later states.
+	SlackMessageModeUpdate SlackMessageMode = "update"
+	// SlackMessageModeThread replies to the first message posted for the
+	// job in a thread.
+	SlackMessageModeThread SlackMessageMode = "thread"
+)
+
 // ApplyDefault is called by jobConfig.ApplyDefault(globalConfig)
 func (src *SlackReporterConfig) ApplyDefault(def *SlackReporterConfig) *SlackReporterConfig {
 	if src == nil && def == nil {
@@ -398,6 +421,96 @@ func (src *SlackReporterConfig) ApplyDefault(def *SlackReporterConfig) *SlackRep
 	if merged.ReportTemplate == "" {
 		merged.ReportTemplate = def.ReportTemplate
 	}
+	if merged.MessageMode == "" {
+		merged.MessageMode = def.MessageMode
+	}
+	if merged.BlockKit == nil {
+		merged.BlockKit = def.BlockKit
+	}
+	if merged.Report == nil {
+		merged.Report = def.Report
+	}
+	return &merged
+}
+
+// LarkReporterConfig holds the config of the Lark/Feishu reporter, which
+// posts message cards through custom bots.
+type LarkReporterConfig struct {
+	// Channel is the name of the custom bot posting to the group chat.
+	Channel           string         `json:"channel,omitempty"`
+	JobStatesToReport []ProwJobState `json:"job_states_to_report,omitempty"`
+	// ReportTemplate is the Go template of the content of the message card,
+	// executed against the ProwJob and rendered as Lark markdown.
+	ReportTemplate string `json:"report_template,omitempty"`
+	// Report is derived from JobStatesToReport, it's used for differentiating
+	// nil from empty slice, see SlackReporterConfig.Report for details.
+	Report *bool `json:"report,omitempty"`
+}
+
+// ApplyDefault is called by jobConfig.ApplyDefault(globalConfig)
+func (src *LarkReporterConfig) ApplyDefault(def *LarkReporterConfig) *LarkReporterConfig {
+	if src == nil && def == nil {
+		return nil
+	}
+	var merged LarkReporterConfig
+	if src != nil {
+		merged = *src.DeepCopy()
+	} else {
+		merged = *def.DeepCopy()
+	}
+	if src == nil || def == nil {
+		return &merged
+	}
+
+	if merged.Channel == "" {
+		merged.Channel = def.Channel
+	}
+	// Note: `job_states_to_report: []` also results in JobStatesToReport == nil
+	if merged.JobStatesToReport == nil {
+		merged.JobStatesToReport = def.JobStatesToReport
+	}
+	if merged.ReportTemplate == "" {
+		merged.ReportTemplate = def.ReportTemplate
+	}
+	if merged.Report == nil {
+		merged.Report = def.Report
+	}
+	return &merged
+}
+
+// WebhookReporterConfig holds the config of the generic webhook reporter,
+// which POSTs the state of the ProwJobs to an HTTP endpoint.
+type WebhookReporterConfig struct {
+	// URL is the endpoint the payloads are POSTed to.
+	URL               string         `json:"url,omitempty"`
+	JobStatesToReport []ProwJobState `json:"job_states_to_report,omitempty"`
+	// Report is derived from JobStatesToReport, it's used for differentiating
+	// nil from empty slice, see SlackReporterConfig.Report for details.
+	Report *bool `json:"report,omitempty"`
+}
+
+// ApplyDefault is called by jobConfig.ApplyDefault(globalConfig)
+func (src *WebhookReporterConfig) ApplyDefault(def *WebhookReporterConfig) *WebhookReporterConfig {
+	if src == nil && def == nil {
+		return nil
+	}
+	var merged WebhookReporterConfig
+	if src != nil {
+		merged = *src.DeepCopy()
+	} else {
+		merged = *def.DeepCopy()
+	}
+	if src == nil || def == nil {
+		return &merged
+	}
+
+	if merged.URL == "" {
+		merged.URL = def.URL
+	}
+	// Note: `job_states_to_report: []` also results in JobStatesToReport == nil
+	if merged.JobStatesToReport == nil {
+		merged.JobStatesToReport = def.JobStatesToReport
+	}
 	if merged.Report == nil {
 		merged.Report = def.Report
 	}
@@ -309,6 +309,32 @@ func (in *JenkinsSpec) DeepCopy() *JenkinsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LarkReporterConfig) DeepCopyInto(out *LarkReporterConfig) {
+	*out = *in
+	if in.JobStatesToReport != nil {
+		in, out := &in.JobStatesToReport, &out.JobStatesToReport
+		*out = make([]ProwJobState, len(*in))
+		copy(*out, *in)
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LarkReporterConfig.
+func (in *LarkReporterConfig) DeepCopy() *LarkReporterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LarkReporterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OauthTokenSecret) DeepCopyInto(out *OauthTokenSecret) {
 	*out = *in
@@ -585,6 +611,16 @@ func (in *ReporterConfig) DeepCopyInto(out *ReporterConfig) {
 		*out = new(SlackReporterConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookReporterConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lark != nil {
+		in, out := &in.Lark, &out.Lark
+		*out = new(LarkReporterConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -694,6 +730,11 @@ func (in *SlackReporterConfig) DeepCopyInto(out *SlackReporterConfig) {
 		*out = make([]ProwJobState, len(*in))
 		copy(*out, *in)
 	}
+	if in.BlockKit != nil {
+		in, out := &in.BlockKit, &out.BlockKit
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Report != nil {
 		in, out := &in.Report, &out.Report
 		*out = new(bool)
@@ -748,3 +789,29 @@ func (in *UtilityImages) DeepCopy() *UtilityImages {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookReporterConfig) DeepCopyInto(out *WebhookReporterConfig) {
+	*out = *in
+	if in.JobStatesToReport != nil {
+		in, out := &in.JobStatesToReport, &out.JobStatesToReport
+		*out = make([]ProwJobState, len(*in))
+		copy(*out, *in)
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReporterConfig.
+func (in *WebhookReporterConfig) DeepCopy() *WebhookReporterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookReporterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
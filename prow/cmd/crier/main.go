@@ -37,15 +37,21 @@ import (
 	k8sgcsreporter "k8s.io/test-infra/prow/crier/reporters/gcs/kubernetes"
 	gerritreporter "k8s.io/test-infra/prow/crier/reporters/gerrit"
 	githubreporter "k8s.io/test-infra/prow/crier/reporters/github"
+	githubchecksreporter "k8s.io/test-infra/prow/crier/reporters/githubchecks"
+	larkreporter "k8s.io/test-infra/prow/crier/reporters/lark"
 	pubsubreporter "k8s.io/test-infra/prow/crier/reporters/pubsub"
 	resultstorereporter "k8s.io/test-infra/prow/crier/reporters/resultstore"
 	slackreporter "k8s.io/test-infra/prow/crier/reporters/slack"
+	webhookreporter "k8s.io/test-infra/prow/crier/reporters/webhook"
 	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	configflagutil "k8s.io/test-infra/prow/flagutil/config"
+	"k8s.io/test-infra/prow/github"
 	"k8s.io/test-infra/prow/interrupts"
+	larkclient "k8s.io/test-infra/prow/lark"
 	"k8s.io/test-infra/prow/logrusutil"
 	"k8s.io/test-infra/prow/metrics"
 	slackclient "k8s.io/test-infra/prow/slack"
+	"k8s.io/test-infra/prow/spyglass"
 )
 
 type options struct {
@@ -60,14 +66,21 @@ type options struct {
 	gerritWorkers         int
 	pubsubWorkers         int
 	githubWorkers         int
+	githubChecksWorkers   int
 	slackWorkers          int
 	blobStorageWorkers    int
 	k8sBlobStorageWorkers int
 	resultStoreWorkers    int
+	webhookWorkers        int
+	larkWorkers           int
 
 	slackTokenFile            string
 	additionalSlackTokenFiles slackclient.HostsFlag
 
+	webhookHMACSecretFile string
+
+	larkBotFiles larkclient.BotsFlag
+
 	storage prowflagutil.StorageClientOptions
 
 	instrumentationOptions prowflagutil.InstrumentationOptions
@@ -81,7 +94,7 @@ type options struct {
 }
 
 func (o *options) validate() error {
-	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.slackWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers <= 0 {
+	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.githubChecksWorkers+o.slackWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers+o.webhookWorkers+o.larkWorkers <= 0 {
 		return errors.New("crier need to have at least one report worker to start")
 	}
 
@@ -98,7 +111,7 @@ func (o *options) validate() error {
 		}
 	}
 
-	if o.githubWorkers > 0 {
+	if o.githubWorkers+o.githubChecksWorkers > 0 {
 		if err := o.github.Validate(o.dryrun); err != nil {
 			return err
 		}
@@ -110,6 +123,10 @@ func (o *options) validate() error {
 		}
 	}
 
+	if o.larkWorkers > 0 && len(o.larkBotFiles) == 0 {
+		return errors.New("--lark-bot-files must be set")
+	}
+
 	for _, opt := range []interface{ Validate(bool) error }{&o.client, &o.githubEnablement, &o.config} {
 		if err := opt.Validate(o.dryrun); err != nil {
 			return err
@@ -124,6 +141,7 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	fs.IntVar(&o.gerritWorkers, "gerrit-workers", 0, "Number of gerrit report workers (0 means disabled)")
 	fs.IntVar(&o.pubsubWorkers, "pubsub-workers", 0, "Number of pubsub report workers (0 means disabled)")
 	fs.IntVar(&o.githubWorkers, "github-workers", 0, "Number of github report workers (0 means disabled)")
+	fs.IntVar(&o.githubChecksWorkers, "github-checks-workers", 0, "Number of GitHub Checks report workers, which require authenticating as a GitHub App (0 means disabled)")
 	fs.IntVar(&o.slackWorkers, "slack-workers", 0, "Number of Slack report workers (0 means disabled)")
 	fs.Var(&o.additionalSlackTokenFiles, "additional-slack-token-files", "Map of additional slack token files. example: --additional-slack-token-files=foo=/etc/foo-slack-tokens/token, repeat flag for each host")
 	fs.IntVar(&o.blobStorageWorkers, "blob-storage-workers", 0, "Number of blob storage report workers (0 means disabled)")
@@ -132,10 +150,14 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	fs.StringVar(&o.slackTokenFile, "slack-token-file", "", "Path to a Slack token file")
 	fs.StringVar(&o.reportAgent, "report-agent", "", "Only report specified agent - empty means report to all agents (effective for github and Slack only)")
 	fs.IntVar(&o.resultStoreWorkers, "resultstore-workers", 0, "Number of ResultStore report workers (0 means disabled)")
+	fs.IntVar(&o.webhookWorkers, "webhook-workers", 0, "Number of webhook report workers (0 means disabled)")
+	fs.StringVar(&o.webhookHMACSecretFile, "webhook-hmac-secret-file", "", "Path to the secret used to sign the webhook payloads, leave empty to not sign them")
+	fs.IntVar(&o.larkWorkers, "lark-workers", 0, "Number of Lark/Feishu report workers (0 means disabled)")
+	fs.Var(&o.larkBotFiles, "lark-bot-files", "Map of the Lark/Feishu custom bots to the files holding their webhook url and signature secret. example: --lark-bot-files=ci-bot=/etc/lark/ci-bot, repeat flag for each bot")
 	fs.BoolVar(&o.resultstoreArtifactsDirOnly, "resultstore-artifacts-dir-only", false, "Report the artifacts/ dir instead of subtree files (testing)")
 
 	// TODO(krzyzacy): implement dryrun for gerrit/pubsub
-	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github and Slack only)")
+	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github, Slack, Lark and webhook only)")
 
 	o.config.AddFlags(fs)
 	o.github.AddFlags(fs)
@@ -217,12 +239,53 @@ func main() {
 				logrus.WithError(err).Fatal("could not read slack token")
 			}
 		}
-		slackReporter := slackreporter.New(slackConfig, o.dryrun, tokensMap)
+		slackReporter := slackreporter.New(slackConfig, o.dryrun, tokensMap, mgr.GetClient())
 		if err := crier.New(mgr, slackReporter, o.slackWorkers, o.githubEnablement.EnablementChecker()); err != nil {
 			logrus.WithError(err).Fatal("failed to construct slack reporter controller")
 		}
 	}
 
+	if o.webhookWorkers > 0 {
+		if cfg().WebhookReporterConfigs == nil {
+			logrus.Fatal("webhookreporter is enabled but has no config")
+		}
+		webhookConfig := func(refs *prowapi.Refs) config.WebhookReporter {
+			return cfg().WebhookReporterConfigs.GetWebhookReporter(refs)
+		}
+		var hmacSecret func() []byte
+		if o.webhookHMACSecretFile != "" {
+			if err := secret.Add(o.webhookHMACSecretFile); err != nil {
+				logrus.WithError(err).Fatal("could not read webhook hmac secret")
+			}
+			hmacSecret = secret.GetTokenGenerator(o.webhookHMACSecretFile)
+		}
+		hasReporter = true
+		if err := crier.New(mgr, webhookreporter.New(webhookConfig, o.dryrun, hmacSecret), o.webhookWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			logrus.WithError(err).Fatal("failed to construct webhook reporter controller")
+		}
+	}
+
+	if o.larkWorkers > 0 {
+		if cfg().LarkReporterConfigs == nil {
+			logrus.Fatal("larkreporter is enabled but has no config")
+		}
+		larkConfig := func(refs *prowapi.Refs) config.LarkReporter {
+			return cfg().LarkReporterConfigs.GetLarkReporter(refs)
+		}
+		bots := make(map[string]func() larkclient.Bot)
+		for channel, botFile := range o.larkBotFiles {
+			bot, err := secret.AddWithParser(botFile, larkclient.ParseBot)
+			if err != nil {
+				logrus.WithError(err).Fatal("could not read lark bot secret")
+			}
+			bots[channel] = bot
+		}
+		hasReporter = true
+		if err := crier.New(mgr, larkreporter.New(larkConfig, o.dryrun, bots), o.larkWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			logrus.WithError(err).Fatal("failed to construct lark reporter controller")
+		}
+	}
+
 	if o.gerritWorkers > 0 {
 		orgRepoConfigGetter := func() *config.GerritOrgRepoConfigs {
 			return cfg().Gerrit.OrgReposConfig
@@ -245,18 +308,21 @@ func main() {
 		}
 	}
 
-	if o.githubWorkers > 0 {
+	var githubClient github.Client
+	if o.githubWorkers+o.githubChecksWorkers > 0 {
 		if o.github.TokenPath != "" {
 			if err := secret.Add(o.github.TokenPath); err != nil {
 				logrus.WithError(err).Fatal("Error reading GitHub credentials")
 			}
 		}
 
-		githubClient, err := o.github.GitHubClient(o.dryrun)
+		githubClient, err = o.github.GitHubClient(o.dryrun)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting GitHub client.")
 		}
+	}
 
+	if o.githubWorkers > 0 {
 		hasReporter = true
 		githubReporter := githubreporter.NewReporter(githubClient, cfg, prowapi.ProwJobAgent(o.reportAgent), mgr.GetCache())
 		if err := crier.New(mgr, githubReporter, o.githubWorkers, o.githubEnablement.EnablementChecker()); err != nil {
@@ -265,7 +331,7 @@ func main() {
 	}
 
 	var opener io.Opener
-	if o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers > 0 {
+	if o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers+o.githubChecksWorkers > 0 {
 		opener, err = o.storage.StorageClient(context.Background())
 		if err != nil {
 			logrus.WithError(err).Fatal("Error creating opener")
@@ -305,6 +371,14 @@ func main() {
 		}
 	}
 
+	if o.githubChecksWorkers > 0 {
+		hasReporter = true
+		fetcher := spyglass.NewStorageArtifactFetcher(opener, cfg, false)
+		if err := crier.New(mgr, githubchecksreporter.NewReporter(githubClient, fetcher, cfg), o.githubChecksWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			logrus.WithError(err).Fatal("failed to construct github checks reporter controller")
+		}
+	}
+
 	if !hasReporter {
 		logrus.Fatalf("should have at least one controller to start crier.")
 	}
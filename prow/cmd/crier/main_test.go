@@ -25,6 +25,7 @@ import (
 
 	"k8s.io/test-infra/prow/flagutil"
 	configflagutil "k8s.io/test-infra/prow/flagutil/config"
+	larkclient "k8s.io/test-infra/prow/lark"
 )
 
 func TestOptions(t *testing.T) {
@@ -146,6 +147,48 @@ func TestOptions(t *testing.T) {
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
+		//Lark Reporter
+		{
+			name: "lark workers, sets workers and bots",
+			args: []string{"--lark-workers=2", "--lark-bot-files=ci-bot=/etc/lark/ci-bot", "--config-path=foo"},
+			expected: &options{
+				larkWorkers:  2,
+				larkBotFiles: larkclient.BotsFlag{"ci-bot": "/etc/lark/ci-bot"},
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                 defaultGitHubOptions,
+				k8sReportFraction:      1.0,
+				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+			},
+		},
+		{
+			name: "lark missing --lark-bot-files, rejects",
+			args: []string{"--lark-workers=1", "--config-path=foo"},
+		},
+		//Webhook Reporter
+		{
+			name: "webhook workers, sets workers and secret",
+			args: []string{"--webhook-workers=3", "--webhook-hmac-secret-file=/etc/webhook/hmac", "--config-path=foo"},
+			expected: &options{
+				webhookWorkers:        3,
+				webhookHMACSecretFile: "/etc/webhook/hmac",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                 defaultGitHubOptions,
+				k8sReportFraction:      1.0,
+				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+			},
+		},
 		{
 			name: "k8s-gcs enables k8s-gcs",
 			args: []string{"--kubernetes-blob-storage-workers=3", "--config-path=foo"},
@@ -236,10 +279,11 @@ This unit testing covers only the public portions
 */
 func TestGitHubOptions(t *testing.T) {
 	cases := []struct {
-		name              string
-		args              []string
-		expectedWorkers   int
-		expectedTokenPath string
+		name                  string
+		args                  []string
+		expectedWorkers       int
+		expectedChecksWorkers int
+		expectedTokenPath     string
 	}{
 		{
 			name:              "github workers, only support single worker",
@@ -247,6 +291,11 @@ func TestGitHubOptions(t *testing.T) {
 			expectedWorkers:   5,
 			expectedTokenPath: "tkpath",
 		},
+		{
+			name:                  "github checks workers",
+			args:                  []string{"--github-checks-workers=3", "--github-app-id=123", "--github-app-private-key-path=key", "--config-path=foo"},
+			expectedChecksWorkers: 3,
+		},
 	}
 
 	for _, tc := range cases {
@@ -261,6 +310,10 @@ func TestGitHubOptions(t *testing.T) {
 			t.Errorf("%s: worker mismatch: actual %d != expected %d",
 				tc.name, actual.githubWorkers, tc.expectedWorkers)
 		}
+		if actual.githubChecksWorkers != tc.expectedChecksWorkers {
+			t.Errorf("%s: checks worker mismatch: actual %d != expected %d",
+				tc.name, actual.githubChecksWorkers, tc.expectedChecksWorkers)
+		}
 		if actual.github.TokenPath != tc.expectedTokenPath {
 			t.Errorf("%s: path mismatch: actual %s != expected %s",
 				tc.name, actual.github.TokenPath, tc.expectedTokenPath)
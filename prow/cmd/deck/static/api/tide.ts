@@ -17,7 +17,7 @@ export interface PullRequest extends BasePullRequest {
   Title: string;
 }
 
-export type Action = "WAIT" | "TRIGGER" | "TRIGGER_BATCH" | "MERGE" | "MERGE_BATCH" | "BLOCKED";
+export type Action = "WAIT" | "TRIGGER" | "TRIGGER_BATCH" | "BISECT_BATCH" | "MERGE" | "MERGE_BATCH" | "BLOCKED";
 
 export interface Blocker {
   Number: number;
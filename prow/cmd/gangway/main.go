@@ -35,6 +35,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"k8s.io/test-infra/pkg/flagutil"
+	prowjobinformer "k8s.io/test-infra/prow/client/informers/externalversions"
 	"k8s.io/test-infra/prow/config"
 	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	configflagutil "k8s.io/test-infra/prow/flagutil/config"
@@ -143,10 +144,16 @@ func main() {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
 
-	prowjobClient, err := o.client.ProwJobClient(configAgent.Config().ProwJobNamespace, o.dryRun)
+	prowjobClientset, err := o.client.ProwJobClientset(o.dryRun)
 	if err != nil {
-		logrus.WithError(err).Fatal("unable to create prow job client")
+		logrus.WithError(err).Fatal("unable to create prow job clientset")
 	}
+	prowjobClient := prowjobClientset.ProwV1().ProwJobs(configAgent.Config().ProwJobNamespace)
+
+	// The informer backs the WatchJobExecutions streams and ListJobExecutions.
+	informerFactory := prowjobinformer.NewSharedInformerFactoryWithOptions(prowjobClientset, 0, prowjobinformer.WithNamespace(configAgent.Config().ProwJobNamespace))
+	jobWatcher := gangway.NewJobWatcher(informerFactory.Prow().V1().ProwJobs().Informer())
+	go informerFactory.Start(interrupts.Context().Done())
 
 	// If we are provided credentials for Git hosts, use them. These credentials
 	// hold per-host information in them so it's safe to set them globally.
@@ -160,6 +167,7 @@ func main() {
 	gw := gangway.Gangway{
 		ConfigAgent:   configAgent,
 		ProwJobClient: prowjobClient,
+		JobWatcher:    jobWatcher,
 	}
 
 	// InRepoConfig getter.
@@ -193,10 +201,11 @@ func main() {
 
 	// Create a new gRPC (empty) server, and wire it up to act as a "ProwServer"
 	// as defined in the auto-generated gangway_grpc.pb.go file. Also inject an
-	// interceptor for collecting Prometheus metrics for all unary gRPC
-	// requests.
+	// interceptor for collecting Prometheus metrics for all unary and streaming
+	// gRPC requests.
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
+		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
 	)
 	gangway.RegisterProwServer(grpcServer, &gw)
 	grpc_prometheus.Register(grpcServer)
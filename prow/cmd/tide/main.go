@@ -20,7 +20,9 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"time"
@@ -28,6 +30,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/config/secret"
+	gitv2 "k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/gitlab"
 	"k8s.io/test-infra/prow/pjutil/pprof"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -38,11 +43,13 @@ import (
 	"k8s.io/test-infra/prow/logrusutil"
 	"k8s.io/test-infra/prow/metrics"
 	"k8s.io/test-infra/prow/tide"
+	"k8s.io/test-infra/prow/tide/history"
 )
 
 const (
 	githubProviderName = "github"
 	gerritProviderName = "gerrit"
+	gitlabProviderName = "gitlab"
 )
 
 type options struct {
@@ -69,6 +76,10 @@ type options struct {
 	// a) the gcs credentials can write to this bucket
 	// b) the default acls do not expose any private info
 	historyURI string
+	// historyStoreURI where Tide should keep all its action history, in
+	// append only segments that can be queried.
+	// Can be gs://bucket/path or s3://bucket/path.
+	historyStoreURI string
 
 	// statusURI where Tide store status update state.
 	// Can be a /local/path, gs://path/to/object or s3://path/to/object.
@@ -82,6 +93,9 @@ type options struct {
 
 	// Gerrit-related options
 	cookiefilePath string
+
+	// GitLab-related options
+	gitlabTokenPath string
 }
 
 func (o *options) Validate() error {
@@ -90,8 +104,14 @@ func (o *options) Validate() error {
 			return err
 		}
 	}
-	if o.providerName != "" && !sets.NewString(githubProviderName, gerritProviderName).Has(o.providerName) {
-		return errors.New("--provider should be github or gerrit")
+	if o.providerName != "" && !sets.NewString(githubProviderName, gerritProviderName, gitlabProviderName).Has(o.providerName) {
+		return errors.New("--provider should be github, gerrit or gitlab")
+	}
+	if o.providerName == gitlabProviderName {
+		if o.gitlabTokenPath == "" {
+			return errors.New("--gitlab-token-path is required for the gitlab provider")
+		}
+		return nil
 	}
 	var providerFlagGroup flagutil.OptionGroup = &o.github
 	if o.providerName == gerritProviderName {
@@ -116,11 +136,14 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.IntVar(&o.statusThrottle, "status-hourly-tokens", 400, "The maximum number of tokens per hour to be used by the status controller.")
 	fs.IntVar(&o.maxRecordsPerPool, "max-records-per-pool", 1000, "The maximum number of history records stored for an individual Tide pool.")
 	fs.StringVar(&o.historyURI, "history-uri", "", "The /local/path,gs://path/to/object or s3://path/to/object to store tide action history. GCS writes will use the default object ACL for the bucket")
+	fs.StringVar(&o.historyStoreURI, "history-store-uri", "", "The gs://bucket/path or s3://bucket/path directory to keep all the tide action history in, so that records evicted from --history-uri can still be queried. GCS writes will use the default object ACL for the bucket.")
 	fs.StringVar(&o.statusURI, "status-path", "", "The /local/path, gs://path/to/object or s3://path/to/object to store status controller state. GCS writes will use the default object ACL for the bucket.")
 	// Gerrit-related flags
 	fs.StringVar(&o.cookiefilePath, "cookiefile", "", "Path to git http.cookiefile; leave empty for anonymous access or if you are using GitHub")
+	// GitLab-related flags
+	fs.StringVar(&o.gitlabTokenPath, "gitlab-token-path", "", "Path to the file containing the GitLab access token, required by the gitlab provider.")
 
-	fs.StringVar(&o.providerName, "provider", "", "The source code provider, only supported providers are github and gerrit, this should be set only when both GitHub and Gerrit configs are set for tide. By default provider is auto-detected as github if `tide.queries` is set, and gerrit if `tide.gerrit` is set.")
+	fs.StringVar(&o.providerName, "provider", "", "The source code provider, only supported providers are github, gerrit and gitlab, this should be set only when more than one of the GitHub, Gerrit and GitLab configs are set for tide. By default provider is auto-detected as github if `tide.queries` is set, gerrit if `tide.gerrit` is set and gitlab if `tide.gitlab` is set.")
 	o.controllerManager.TimeoutListingProwJobsDefault = 30 * time.Second
 	o.controllerManager.AddFlags(fs)
 	fs.Parse(args)
@@ -130,6 +153,11 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 func main() {
 	logrusutil.ComponentInit()
 
+	if len(os.Args) > 1 && os.Args[1] == simulateCommand {
+		simulate(os.Args[2:])
+		return
+	}
+
 	defer interrupts.WaitForGracefulShutdown()
 
 	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
@@ -161,16 +189,21 @@ func main() {
 		logrus.WithError(err).Fatal("Error constructing mgr.")
 	}
 
-	if cfg().Tide.Gerrit != nil && cfg().Tide.Queries.QueryMap() != nil && o.providerName == "" {
-		logrus.Fatal("Both github and gerrit are configured in tide config but provider is not set.")
+	if len(configuredProviders(cfg().Tide)) > 1 && o.providerName == "" {
+		logrus.Fatalf("Providers %v are configured in tide config but provider is not set.", configuredProviders(cfg().Tide))
 	}
 
 	var c *tide.Controller
-	gitClient, err := o.github.GitClientFactory(o.cookiefilePath, &o.config.InRepoConfigCacheDirBase, o.dryRun, false)
+	provider := provider(o.providerName, cfg().Tide)
+	var gitClient gitv2.ClientFactory
+	if provider == gitlabProviderName {
+		gitClient, err = o.gitlabGitClientFactory(cfg().Tide.GitLab)
+	} else {
+		gitClient, err = o.github.GitClientFactory(o.cookiefilePath, &o.config.InRepoConfigCacheDirBase, o.dryRun, false)
+	}
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting Git client.")
 	}
-	provider := provider(o.providerName, cfg().Tide)
 	switch provider {
 	case githubProviderName:
 		githubSync, err := o.github.GitHubClientWithLogFields(o.dryRun, logrus.Fields{"controller": "sync"})
@@ -226,10 +259,33 @@ func main() {
 		if err != nil {
 			logrus.WithError(err).Fatal("Error creating Tide controller.")
 		}
+	case gitlabProviderName:
+		gitlabClient := gitlab.NewClient(cfg().Tide.GitLab.Endpoint, secret.GetTokenGenerator(o.gitlabTokenPath))
+		c, err = tide.NewGitLabController(
+			mgr,
+			cfg,
+			gitClient,
+			gitlabClient,
+			o.maxRecordsPerPool,
+			opener,
+			o.historyURI,
+			nil,
+		)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating Tide controller.")
+		}
 	default:
 		logrus.Fatalf("Unsupported provider type '%s', this should not happen", provider)
 	}
 
+	if o.historyStoreURI != "" {
+		store, err := history.NewSegmentStore(opener, o.historyStoreURI)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating the action history store.")
+		}
+		c.History().SetStore(store)
+	}
+
 	interrupts.Run(func(ctx context.Context) {
 		if err := mgr.Start(ctx); err != nil {
 			logrus.WithError(err).Fatal("Mgr failed.")
@@ -254,6 +310,8 @@ func main() {
 	controllerMux := http.NewServeMux()
 	controllerMux.Handle("/", c)
 	controllerMux.Handle("/history", c.History())
+	controllerMux.HandleFunc("/history/query", c.History().ServeQuery)
+	controllerMux.HandleFunc("/queue", c.ServeQueue)
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: controllerMux}
 
 	// Push metrics to the configured prometheus pushgateway endpoint or serve them
@@ -283,22 +341,66 @@ func sync(c *tide.Controller) {
 	}
 }
 
+// gitlabGitClientFactory creates a git client factory cloning from the GitLab
+// instance, authenticated with the GitLab access token.
+func (o *options) gitlabGitClientFactory(gitlabConfig *config.TideGitLabConfig) (gitv2.ClientFactory, error) {
+	if gitlabConfig == nil {
+		return nil, errors.New("tide.gitlab is not configured")
+	}
+	endpoint, err := url.Parse(gitlabConfig.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab endpoint: %w", err)
+	}
+	if err := secret.Add(o.gitlabTokenPath); err != nil {
+		return nil, fmt.Errorf("error reading GitLab token: %w", err)
+	}
+
+	useInsecureHTTP := endpoint.Scheme == "http"
+	opts := gitv2.ClientFactoryOpts{
+		Host:            endpoint.Host,
+		UseInsecureHTTP: &useInsecureHTTP,
+		Censor:          secret.Censor,
+		// GitLab accepts any username with an access token.
+		Username: func() (string, error) { return "oauth2", nil },
+		Token: func(string) (string, error) {
+			return string(secret.GetSecret(o.gitlabTokenPath)), nil
+		},
+	}
+	if o.config.InRepoConfigCacheDirBase != "" {
+		opts.CacheDirBase = &o.config.InRepoConfigCacheDirBase
+	}
+	return gitv2.NewClientFactory(opts.Apply)
+}
+
 func provider(wantProvider string, tideConfig config.Tide) string {
 	if wantProvider != "" {
-		if !sets.NewString(githubProviderName, gerritProviderName).Has(wantProvider) {
+		if !sets.NewString(githubProviderName, gerritProviderName, gitlabProviderName).Has(wantProvider) {
 			return ""
 		}
 		return wantProvider
 	}
 	// Default to GitHub if GitHub queries are configured
+	if configured := configuredProviders(tideConfig); len(configured) > 0 {
+		return configured[0]
+	}
+	// When nothing is configured, don't fail tide. Assuming
+	return githubProviderName
+}
+
+// configuredProviders returns the providers that have queries in the tide
+// config, in the order of precedence.
+func configuredProviders(tideConfig config.Tide) []string {
+	var res []string
 	if len([]config.TideQuery(tideConfig.Queries)) > 0 {
-		return githubProviderName
+		res = append(res, githubProviderName)
 	}
 	if tideConfig.Gerrit != nil && len([]config.GerritOrgRepoConfig(tideConfig.Gerrit.Queries)) > 0 {
-		return gerritProviderName
+		res = append(res, gerritProviderName)
 	}
-	// When nothing is configured, don't fail tide. Assuming
-	return githubProviderName
+	if tideConfig.GitLab != nil && len(tideConfig.GitLab.Queries) > 0 {
+		res = append(res, gitlabProviderName)
+	}
+	return res
 }
 
 func tokensPerIteration(hourlyTokens int, iterPeriod time.Duration) int {
@@ -221,6 +221,26 @@ func TestProvider(t *testing.T) {
 			},
 			expect: "gerrit",
 		},
+		{
+			name:     "only-gitlab-config",
+			provider: "",
+			tideConfig: config.Tide{GitLab: &config.TideGitLabConfig{
+				Queries: []config.GitLabTideQuery{{}},
+			}},
+			expect: "gitlab",
+		},
+		{
+			name:     "explicit-gitlab",
+			provider: "gitlab",
+			tideConfig: config.Tide{
+				TideGitHubConfig: config.TideGitHubConfig{
+					Queries: config.TideQueries{
+						{},
+					},
+				},
+			},
+			expect: "gitlab",
+		},
 		{
 			name:     "explicit-unsupported-provider",
 			provider: "foobar",
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	configflagutil "k8s.io/test-infra/prow/flagutil/config"
+	gitv2 "k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/tide"
+)
+
+// simulateCommand is the subcommand simulating the actions of tide against a
+// recorded snapshot, e.g. to validate config changes before rolling them out.
+const simulateCommand = "simulate"
+
+type simulateOptions struct {
+	config configflagutil.ConfigOptions
+
+	snapshotPath string
+	output       string
+}
+
+func (o *simulateOptions) Validate() error {
+	if err := o.config.Validate(false); err != nil {
+		return err
+	}
+	if o.snapshotPath == "" {
+		return errors.New("--snapshot is required")
+	}
+	if o.output != "text" && o.output != "json" {
+		return errors.New("--output should be text or json")
+	}
+	return nil
+}
+
+func gatherSimulateOptions(fs *flag.FlagSet, args ...string) simulateOptions {
+	var o simulateOptions
+	o.config.AddFlags(fs)
+	fs.StringVar(&o.snapshotPath, "snapshot", "", "Path to the JSON or YAML snapshot of the PRs, base SHAs and ProwJobs to simulate tide against.")
+	fs.StringVar(&o.output, "output", "text", "The output format, either text or json.")
+	fs.Parse(args)
+	return o
+}
+
+// simulate prints the actions tide would take for each subpool of a snapshot
+// with the given config.
+func simulate(args []string) {
+	o := gatherSimulateOptions(flag.NewFlagSet(os.Args[0]+" "+simulateCommand, flag.ExitOnError), args...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	configAgent, err := o.config.ConfigAgent()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error loading config.")
+	}
+	raw, err := os.ReadFile(o.snapshotPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error reading snapshot.")
+	}
+	var snapshot tide.SimulationSnapshot
+	if err := yaml.Unmarshal(raw, &snapshot); err != nil {
+		logrus.WithError(err).Fatal("Error parsing snapshot.")
+	}
+	// The git client is only used to read in-repo configs.
+	gitClient, err := gitv2.NewClientFactory()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Git client.")
+	}
+	defer gitClient.Clean()
+
+	pools, err := tide.Simulate(configAgent.Config, gitClient, &snapshot, nil)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error simulating tide.")
+	}
+	if o.output == "json" {
+		err = json.NewEncoder(os.Stdout).Encode(pools)
+	} else {
+		err = printSimulatedPools(os.Stdout, pools)
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("Error printing the simulation.")
+	}
+}
+
+func printSimulatedPools(w io.Writer, pools []tide.SimulatedPool) error {
+	numbers := func(prs []int) string {
+		var res []string
+		for _, pr := range prs {
+			res = append(res, fmt.Sprintf("#%d", pr))
+		}
+		return strings.Join(res, ", ")
+	}
+	var b strings.Builder
+	for _, pool := range pools {
+		fmt.Fprintf(&b, "%s/%s:%s", pool.Org, pool.Repo, pool.Branch)
+		if pool.BaseSHA != "" {
+			fmt.Fprintf(&b, " (%s)", pool.BaseSHA)
+		}
+		b.WriteString("\n")
+		if pool.Action == "" {
+			b.WriteString("  action: none, no PR in the pool\n")
+		} else {
+			fmt.Fprintf(&b, "  action: %s %s\n", pool.Action, numbers(pool.Targets))
+		}
+		for _, job := range pool.Jobs {
+			fmt.Fprintf(&b, "  triggers: %s (%s) for %s\n", job.Name, job.Type, numbers(job.PRs))
+		}
+		if pool.Error != "" {
+			fmt.Fprintf(&b, "  error: %s\n", pool.Error)
+		}
+		for _, prs := range []struct {
+			state string
+			prs   []int
+		}{
+			{"success", pool.SuccessPRs},
+			{"pending", pool.PendingPRs},
+			{"missing", pool.MissingPRs},
+			{"batch pending", pool.BatchPending},
+		} {
+			if len(prs.prs) > 0 {
+				fmt.Fprintf(&b, "  %s: %s\n", prs.state, numbers(prs.prs))
+			}
+		}
+		for _, pr := range pool.Excluded {
+			fmt.Fprintf(&b, "  excluded #%d: %s\n", pr.Number, pr.Reason)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
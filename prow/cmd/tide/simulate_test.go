@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/tide"
+)
+
+func TestPrintSimulatedPools(t *testing.T) {
+	pools := []tide.SimulatedPool{
+		{
+			Org:        "org",
+			Repo:       "repo",
+			Branch:     "main",
+			BaseSHA:    "abc",
+			MissingPRs: []int{1, 2},
+			Action:     tide.TriggerBatch,
+			Targets:    []int{1, 2},
+			Jobs:       []tide.SimulatedJob{{Name: "unit", Type: prowapi.BatchJob, PRs: []int{1, 2}}},
+			Excluded:   []tide.ExcludedPR{{Number: 3, Reason: "Not matching the Tide queries: Needs lgtm label."}},
+		},
+		{
+			Org:      "org",
+			Repo:     "repo",
+			Branch:   "release",
+			Excluded: []tide.ExcludedPR{{Number: 4, Reason: "PR has a merge conflict."}},
+		},
+	}
+	expected := `org/repo:main (abc)
+  action: TRIGGER_BATCH #1, #2
+  triggers: unit (batch) for #1, #2
+  missing: #1, #2
+  excluded #3: Not matching the Tide queries: Needs lgtm label.
+org/repo:release
+  action: none, no PR in the pool
+  excluded #4: PR has a merge conflict.
+`
+	var out strings.Builder
+	if err := printSimulatedPools(&out, pools); err != nil {
+		t.Fatalf("Failed to print the simulated pools: %v", err)
+	}
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("Output mismatch (-want +got):\n%s", diff)
+	}
+}
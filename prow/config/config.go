@@ -150,7 +150,11 @@ type ProwConfig struct {
 	GitHubReporter       GitHubReporter       `json:"github_reporter"`
 	Horologium           Horologium           `json:"horologium"`
 	SlackReporterConfigs SlackReporterConfigs `json:"slack_reporter_configs,omitempty"`
-	InRepoConfig         InRepoConfig         `json:"in_repo_config"`
+	// WebhookReporterConfigs configures the generic webhook reporter of crier.
+	WebhookReporterConfigs WebhookReporterConfigs `json:"webhook_reporter_configs,omitempty"`
+	// LarkReporterConfigs configures the Lark/Feishu reporter of crier.
+	LarkReporterConfigs LarkReporterConfigs `json:"lark_reporter_configs,omitempty"`
+	InRepoConfig        InRepoConfig        `json:"in_repo_config"`
 
 	// Gangway contains configurations needed by the the Prow API server of the
 	// same name. It encodes an allowlist of API clients and what kinds of Prow
@@ -1618,6 +1622,139 @@ func (cfg *SlackReporter) DefaultAndValidate() error {
 		return errors.New("channel must be set")
 	}
 
+	switch cfg.MessageMode {
+	case "", prowapi.SlackMessageModeNew, prowapi.SlackMessageModeUpdate, prowapi.SlackMessageModeThread:
+	default:
+		return fmt.Errorf("message_mode %q is invalid, must be one of %q, %q or %q", cfg.MessageMode, prowapi.SlackMessageModeNew, prowapi.SlackMessageModeUpdate, prowapi.SlackMessageModeThread)
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookReporter represents the config for the webhook reporter. The URL can be overridden
+// on the job via the .reporter_config.webhook.url property.
+type WebhookReporter struct {
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// AllowedJobURLs are the URLs under which the jobs can override the url.
+	// A job url is allowed if it has the scheme and host of one of them, and
+	// its path is under the path of it. Jobs can't override the url if unset.
+	AllowedJobURLs                []string `json:"allowed_job_urls,omitempty"`
+	prowapi.WebhookReporterConfig `json:",inline"`
+}
+
+// WebhookReporterConfigs represents the config for the webhook reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `WebhookReporter` struct as value.
+type WebhookReporterConfigs map[string]WebhookReporter
+
+func (cfg WebhookReporterConfigs) GetWebhookReporter(refs *prowapi.Refs) WebhookReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if webhook, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return webhook
+	}
+
+	if webhook, ok := cfg[refs.Org]; ok {
+		return webhook
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *WebhookReporter) Validate() error {
+	if cfg.URL == "" {
+		return errors.New("url must be set")
+	}
+	if _, err := parseWebhookURL(cfg.URL); err != nil {
+		return err
+	}
+	for _, allowed := range cfg.AllowedJobURLs {
+		if _, err := parseWebhookURL(allowed); err != nil {
+			return fmt.Errorf("invalid allowed_job_urls: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateJobURL checks that a job can override the url with the given one.
+func (cfg *WebhookReporter) ValidateJobURL(jobURL string) error {
+	u, err := parseWebhookURL(jobURL)
+	if err != nil {
+		return err
+	}
+	for _, allowed := range cfg.AllowedJobURLs {
+		a, err := parseWebhookURL(allowed)
+		if err != nil {
+			continue
+		}
+		if u.Scheme == a.Scheme && strings.EqualFold(u.Host, a.Host) && strings.HasPrefix(u.Path, a.Path) {
+			return nil
+		}
+	}
+	return fmt.Errorf("url %q is not allowed by the allowed_job_urls of the webhook reporter config", jobURL)
+}
+
+func parseWebhookURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("url %q must be an http or https url", rawURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("url %q must have a host", rawURL)
+	}
+	return u, nil
+}
+
+// LarkReporter represents the config for the Lark/Feishu reporter. The channel can be overridden
+// on the job via the .reporter_config.lark.channel property.
+type LarkReporter struct {
+	JobTypesToReport           []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	prowapi.LarkReporterConfig `json:",inline"`
+}
+
+// LarkReporterConfigs represents the config for the Lark/Feishu reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `LarkReporter` struct as value.
+type LarkReporterConfigs map[string]LarkReporter
+
+func (cfg LarkReporterConfigs) GetLarkReporter(refs *prowapi.Refs) LarkReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if lark, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return lark
+	}
+
+	if lark, ok := cfg[refs.Org]; ok {
+		return lark
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *LarkReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job **{{.Spec.Job}}** of type {{.Spec.Type}} ended with state **{{.Status.State}}**. [View logs]({{.Status.URL}})`
+	}
+
+	if cfg.Channel == "" {
+		return errors.New("channel must be set")
+	}
+
 	// Validate ReportTemplate.
 	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
 	if err != nil {
@@ -2189,6 +2326,19 @@ func (c *Config) validateComponentConfig() error {
 		}
 	}
 
+	for k, config := range c.LarkReporterConfigs {
+		if err := config.DefaultAndValidate(); err != nil {
+			return fmt.Errorf("failed to validate lark reporter config for %q: %w", k, err)
+		}
+		c.LarkReporterConfigs[k] = config
+	}
+
+	for k, config := range c.WebhookReporterConfigs {
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("failed to validate webhook reporter config for %q: %w", k, err)
+		}
+	}
+
 	if err := c.Deck.FinalizeDefaultRerunAuthConfigs(); err != nil {
 		return err
 	}
@@ -2744,6 +2894,12 @@ func parseProwConfig(c *Config) error {
 		}
 	}
 
+	if c.Tide.GitLab != nil {
+		if err := c.Tide.GitLab.Validate(); err != nil {
+			return fmt.Errorf("tide gitlab config is invalid: %w", err)
+		}
+	}
+
 	if c.ProwJobNamespace == "" {
 		c.ProwJobNamespace = "default"
 	}
@@ -3343,13 +3499,13 @@ func StringsToOrgRepos(vs []string) []OrgRepo {
 func (pc *ProwConfig) mergeFrom(additional *ProwConfig) error {
 	emptyReference := &ProwConfig{
 		BranchProtection:     additional.BranchProtection,
-		Tide:                 Tide{TideGitHubConfig: TideGitHubConfig{MergeType: additional.Tide.MergeType, Queries: additional.Tide.Queries}},
+		Tide:                 Tide{GitLab: additional.Tide.GitLab, TideGitHubConfig: TideGitHubConfig{MergeType: additional.Tide.MergeType, Queries: additional.Tide.Queries}},
 		SlackReporterConfigs: additional.SlackReporterConfigs,
 	}
 
 	var errs []error
 	if diff := cmp.Diff(additional, emptyReference, DefaultDiffOpts...); diff != "" {
-		errs = append(errs, fmt.Errorf("only 'branch-protection', 'slack_reporter_configs', 'tide.merge_method' and 'tide.queries' may be set via additional config, all other fields have no merging logic yet. Diff: %s", diff))
+		errs = append(errs, fmt.Errorf("only 'branch-protection', 'slack_reporter_configs', 'tide.merge_method', 'tide.queries' and 'tide.gitlab' may be set via additional config, all other fields have no merging logic yet. Diff: %s", diff))
 	}
 	if err := pc.BranchProtection.merge(&additional.BranchProtection); err != nil {
 		errs = append(errs, fmt.Errorf("failed to merge branch protection config: %w", err))
@@ -4884,6 +4884,153 @@ func TestSlackReporterValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestLarkReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		larkCfg         LarkReporterConfigs
+		successExpected bool
+	}{
+		{
+			name: "valid config",
+			larkCfg: LarkReporterConfigs{
+				"*":        {LarkReporterConfig: prowapi.LarkReporterConfig{Channel: "ci-bot"}},
+				"org/repo": {LarkReporterConfig: prowapi.LarkReporterConfig{Channel: "repo-bot", ReportTemplate: "{{.Spec.Job}} is {{.Status.State}}"}},
+			},
+			successExpected: true,
+		},
+		{
+			name: "no channel",
+			larkCfg: LarkReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{prowapi.PeriodicJob}},
+			},
+		},
+		{
+			name: "invalid template",
+			larkCfg: LarkReporterConfigs{
+				"*": {LarkReporterConfig: prowapi.LarkReporterConfig{Channel: "ci-bot", ReportTemplate: "{{ .Undef}}"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{LarkReporterConfigs: tc.larkCfg}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				for _, config := range cfg.LarkReporterConfigs {
+					if config.ReportTemplate == "" {
+						t.Errorf("expected default ReportTemplate to be set")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		webhookCfg      WebhookReporterConfigs
+		successExpected bool
+	}{
+		{
+			name: "valid config",
+			webhookCfg: WebhookReporterConfigs{
+				"*":   {WebhookReporterConfig: prowapi.WebhookReporterConfig{URL: "https://dashboard.example.com/prow"}},
+				"org": {WebhookReporterConfig: prowapi.WebhookReporterConfig{URL: "http://chatops.svc/hooks"}},
+			},
+			successExpected: true,
+		},
+		{
+			name:            "empty config",
+			webhookCfg:      WebhookReporterConfigs{},
+			successExpected: true,
+		},
+		{
+			name: "no url",
+			webhookCfg: WebhookReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{prowapi.PostsubmitJob}},
+			},
+		},
+		{
+			name: "not an http url",
+			webhookCfg: WebhookReporterConfigs{
+				"org/repo": {WebhookReporterConfig: prowapi.WebhookReporterConfig{URL: "ftp://dashboard.example.com"}},
+			},
+		},
+		{
+			name: "no host",
+			webhookCfg: WebhookReporterConfigs{
+				"org/repo": {WebhookReporterConfig: prowapi.WebhookReporterConfig{URL: "https:///prow"}},
+			},
+		},
+		{
+			name: "invalid allowed job url",
+			webhookCfg: WebhookReporterConfigs{
+				"*": {
+					AllowedJobURLs:        []string{"hooks.example.com"},
+					WebhookReporterConfig: prowapi.WebhookReporterConfig{URL: "https://dashboard.example.com/prow"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{WebhookReporterConfigs: tc.webhookCfg}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+func TestWebhookReporterValidateJobURL(t *testing.T) {
+	cfg := WebhookReporter{AllowedJobURLs: []string{"https://hooks.example.com/prow/", "http://chatops.svc"}}
+	testCases := []struct {
+		name            string
+		url             string
+		successExpected bool
+	}{
+		{
+			name:            "under an allowed url",
+			url:             "https://hooks.example.com/prow/team-a",
+			successExpected: true,
+		},
+		{
+			name:            "allowed host",
+			url:             "http://chatops.svc/hooks",
+			successExpected: true,
+		},
+		{
+			name: "outside of the allowed path",
+			url:  "https://hooks.example.com/other",
+		},
+		{
+			name: "other scheme",
+			url:  "http://hooks.example.com/prow/team-a",
+		},
+		{
+			name: "host with an allowed prefix",
+			url:  "http://chatops.svc.evil.example.com/hooks",
+		},
+		{
+			name: "not an http url",
+			url:  "file:///etc/passwd",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := cfg.ValidateJobURL(tc.url); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
 func TestManagedHmacEntityValidation(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -9066,6 +9213,12 @@ func TestProwConfigMergingProperties(t *testing.T) {
 				*pc = ProwConfig{Tide: Tide{TideGitHubConfig: TideGitHubConfig{Queries: pc.Tide.Queries}}}
 			},
 		},
+		{
+			name: "Tide GitLab config",
+			makeMergeable: func(pc *ProwConfig) {
+				*pc = ProwConfig{Tide: Tide{GitLab: pc.Tide.GitLab}}
+			},
+		},
 		{
 			name: "SlackReporter configurations",
 			makeMergeable: func(pc *ProwConfig) {
@@ -9105,9 +9258,9 @@ func TestProwConfigMergingProperties(t *testing.T) {
 					return
 				}
 
-				// One exception: Tide queries can be merged into themselves, as we just de-duplicate them
-				// later on.
-				if len(fuzzedMergeableConfig.Tide.Queries) > 0 {
+				// One exception: Tide queries, including the GitLab ones, can be merged into themselves,
+				// as we just de-duplicate them later on.
+				if len(fuzzedMergeableConfig.Tide.Queries) > 0 || fuzzedMergeableConfig.Tide.GitLab != nil {
 					return
 				}
 
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"sort"
 	"strings"
@@ -201,6 +202,7 @@ type TidePriority struct {
 // Tide is config for the tide pool.
 type Tide struct {
 	Gerrit *TideGerritConfig `json:"gerrit,omitempty"`
+	GitLab *TideGitLabConfig `json:"gitlab,omitempty"`
 	// SyncPeriod specifies how often Tide will sync jobs with GitHub. Defaults to 1m.
 	SyncPeriod *metav1.Duration `json:"sync_period,omitempty"`
 	// MaxGoroutines is the maximum number of goroutines spawned inside the
@@ -219,6 +221,13 @@ type Tide struct {
 	// starting a new one requires to start new instances of all tests.
 	// Use '*' as key to set this globally. Defaults to true.
 	PrioritizeExistingBatchesMap map[string]bool `json:"prioritize_existing_batches,omitempty"`
+	// BisectFailedBatchesMap configures on org or org/repo level if Tide should bisect
+	// failed batches to find the PR that breaks them. The PRs of a failed batch are split
+	// in two halves that are tested in parallel, recursively, until a single PR fails. That
+	// PR is not merged until either its head or the base branch changes. The passing halves
+	// are not merged before that PR is found, as merging them would restart the bisection.
+	// Use '*' as key to set this globally. Defaults to false.
+	BisectFailedBatchesMap map[string]bool `json:"bisect_failed_batches,omitempty"`
 
 	TideGitHubConfig `json:",inline"`
 }
@@ -313,6 +322,73 @@ type TideGerritConfig struct {
 	RateLimit int `json:"ratelimit,omitempty"`
 }
 
+// TideGitLabConfig contains all GitLab related configurations for tide.
+//
+// The settings keyed by org or org/repo, such as merge_method and
+// context_options, apply to a GitLab project by its top-level group or by its
+// full path, such as "group" or "group/subgroup/project". Subgroups can't be
+// configured on their own.
+type TideGitLabConfig struct {
+	// Endpoint is the URL of the GitLab instance, such as https://gitlab.com.
+	Endpoint string `json:"endpoint"`
+	// Queries select the merge requests that are in the merge pool.
+	Queries []GitLabTideQuery `json:"queries"`
+}
+
+// GitLabTideQuery selects the open merge requests of some GitLab projects.
+// Draft merge requests are never selected.
+type GitLabTideQuery struct {
+	// Projects are the full paths of the GitLab projects, such as
+	// "group/subgroup/project".
+	Projects []string `json:"projects"`
+
+	// Labels are required on the merge requests. A label can list comma
+	// separated alternatives, any of which is enough.
+	Labels        []string `json:"labels,omitempty"`
+	MissingLabels []string `json:"missingLabels,omitempty"`
+
+	ExcludedBranches []string `json:"excludedBranches,omitempty"`
+	IncludedBranches []string `json:"includedBranches,omitempty"`
+
+	// Milestone is the title of the milestone required on the merge requests.
+	Milestone string `json:"milestone,omitempty"`
+}
+
+// Validate returns an error if the GitLab configuration is invalid.
+func (c *TideGitLabConfig) Validate() error {
+	if c.Endpoint == "" {
+		return errors.New("endpoint must be set")
+	}
+	if u, err := url.Parse(c.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("endpoint %q is not a valid URL", c.Endpoint)
+	}
+	for i, q := range c.Queries {
+		if err := q.Validate(); err != nil {
+			return fmt.Errorf("query (index %d) is invalid: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the query is invalid.
+func (q *GitLabTideQuery) Validate() error {
+	if len(q.Projects) == 0 {
+		return errors.New("'projects' cannot be empty")
+	}
+	for i, p := range q.Projects {
+		if !strings.Contains(p, "/") || strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/") {
+			return fmt.Errorf("projects[%d]: %q is not of the form \"group/project\"", i, p)
+		}
+	}
+	if invalids := sets.New[string](q.Labels...).Intersection(sets.New[string](q.MissingLabels...)); len(invalids) > 0 {
+		return fmt.Errorf("the labels: %q are both required and forbidden", sets.List(invalids))
+	}
+	if len(q.ExcludedBranches) > 0 && len(q.IncludedBranches) > 0 {
+		return errors.New("both 'includedBranches' and 'excludedBranches' are specified ('excludedBranches' have no effect)")
+	}
+	return nil
+}
+
 func (t *Tide) mergeFrom(additional *Tide) error {
 
 	// Duplicate queries are pointless but not harmful, we
@@ -320,6 +396,16 @@ func (t *Tide) mergeFrom(additional *Tide) error {
 	// increase token usage needlessly.
 	t.Queries = append(t.Queries, additional.Queries...)
 
+	if additional.GitLab != nil {
+		if t.GitLab == nil {
+			t.GitLab = additional.GitLab
+		} else if t.GitLab.Endpoint != additional.GitLab.Endpoint {
+			return fmt.Errorf("gitlab endpoint %q conflicts with %q", additional.GitLab.Endpoint, t.GitLab.Endpoint)
+		} else {
+			t.GitLab.Queries = append(t.GitLab.Queries, additional.GitLab.Queries...)
+		}
+	}
+
 	if t.MergeType == nil {
 		t.MergeType = additional.MergeType
 		return nil
@@ -352,6 +438,16 @@ func (t *Tide) PrioritizeExistingBatches(repo OrgRepo) bool {
 	return true
 }
 
+func (t *Tide) BisectFailedBatches(repo OrgRepo) bool {
+	if val, set := t.BisectFailedBatchesMap[repo.String()]; set {
+		return val
+	}
+	if val, set := t.BisectFailedBatchesMap[repo.Org]; set {
+		return val
+	}
+	return t.BisectFailedBatchesMap["*"]
+}
+
 func (t *Tide) BatchSizeLimit(repo OrgRepo) int {
 	if limit, ok := t.BatchSizeLimitMap[repo.String()]; ok {
 		return limit
@@ -1855,6 +1855,139 @@ func TestTideQuery_Validate(t *testing.T) {
 	}
 }
 
+func TestTideGitLabConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      TideGitLabConfig
+		expectError bool
+	}{
+		{
+			name: "good config",
+			config: TideGitLabConfig{
+				Endpoint: "https://gitlab.com",
+				Queries: []GitLabTideQuery{{
+					Projects:         []string{"group/project", "group/subgroup/project"},
+					Labels:           []string{labels.LGTM, "approved,approved-by-owner"},
+					MissingLabels:    []string{"do-not-merge"},
+					IncludedBranches: []string{"main"},
+					Milestone:        "v1",
+				}},
+			},
+		},
+		{
+			name:        "missing endpoint",
+			config:      TideGitLabConfig{Queries: []GitLabTideQuery{{Projects: []string{"group/project"}}}},
+			expectError: true,
+		},
+		{
+			name:        "invalid endpoint",
+			config:      TideGitLabConfig{Endpoint: "gitlab.com"},
+			expectError: true,
+		},
+		{
+			name: "query without projects",
+			config: TideGitLabConfig{
+				Endpoint: "https://gitlab.com",
+				Queries:  []GitLabTideQuery{{Labels: []string{labels.LGTM}}},
+			},
+			expectError: true,
+		},
+		{
+			name: "project without group",
+			config: TideGitLabConfig{
+				Endpoint: "https://gitlab.com",
+				Queries:  []GitLabTideQuery{{Projects: []string{"project"}}},
+			},
+			expectError: true,
+		},
+		{
+			name: "label both required and forbidden",
+			config: TideGitLabConfig{
+				Endpoint: "https://gitlab.com",
+				Queries: []GitLabTideQuery{{
+					Projects:      []string{"group/project"},
+					Labels:        []string{labels.LGTM},
+					MissingLabels: []string{labels.LGTM},
+				}},
+			},
+			expectError: true,
+		},
+		{
+			name: "both included and excluded branches",
+			config: TideGitLabConfig{
+				Endpoint: "https://gitlab.com",
+				Queries: []GitLabTideQuery{{
+					Projects:         []string{"group/project"},
+					IncludedBranches: []string{"main"},
+					ExcludedBranches: []string{"dev"},
+				}},
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if err != nil && !tc.expectError {
+				t.Errorf("Unexpected error: %v.", err)
+			} else if err == nil && tc.expectError {
+				t.Error("Expected a validation error, but didn't get one.")
+			}
+		})
+	}
+}
+
+func TestTideMergeFromGitLab(t *testing.T) {
+	query := func(project string) GitLabTideQuery {
+		return GitLabTideQuery{Projects: []string{project}}
+	}
+	testCases := []struct {
+		name        string
+		config      *TideGitLabConfig
+		additional  *TideGitLabConfig
+		expected    *TideGitLabConfig
+		expectError bool
+	}{
+		{
+			name:     "no supplemental config",
+			config:   &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/a")}},
+			expected: &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/a")}},
+		},
+		{
+			name:       "only in the supplemental config",
+			additional: &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/b")}},
+			expected:   &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/b")}},
+		},
+		{
+			name:       "queries are appended",
+			config:     &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/a")}},
+			additional: &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/b")}},
+			expected:   &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/a"), query("group/b")}},
+		},
+		{
+			name:        "conflicting endpoints",
+			config:      &TideGitLabConfig{Endpoint: "https://gitlab.com", Queries: []GitLabTideQuery{query("group/a")}},
+			additional:  &TideGitLabConfig{Endpoint: "https://gitlab.example.com", Queries: []GitLabTideQuery{query("group/b")}},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tide := &Tide{GitLab: tc.config}
+			err := tide.mergeFrom(&Tide{GitLab: tc.additional})
+			if (err != nil) != tc.expectError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectError, err)
+			}
+			if tc.expectError {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, tide.GitLab); diff != "" {
+				t.Errorf("GitLab config mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTideContextPolicy_Validate(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -0,0 +1,282 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package githubchecks reports ProwJobs as GitHub check runs, annotated with
+// the failed tests found in their JUnit artifacts.
+package githubchecks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/crier/reporters/gcs/util"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/io/providers"
+	"k8s.io/test-infra/prow/kube"
+	"k8s.io/test-infra/prow/pjutil"
+	"k8s.io/test-infra/prow/spyglass/api"
+	"k8s.io/test-infra/prow/spyglass/lenses/junit"
+)
+
+const (
+	// GitHubChecksReporterName is the name of the GitHub Checks reporter.
+	GitHubChecksReporterName = "github-checks-reporter"
+
+	// maxAnnotations is the number of annotations GitHub accepts per request.
+	maxAnnotations = 50
+	// maxListedTests caps the number of failed tests listed in the summary.
+	maxListedTests = 100
+	// maxMessageLength caps the failure messages of the annotations.
+	maxMessageLength = 4096
+	// junitSizeLimit caps the size of the JUnit files read.
+	junitSizeLimit = 100 * 1024 * 1024
+)
+
+var junitRe = regexp.MustCompile(`(^|/)junit.*\.xml$`)
+
+type githubClient interface {
+	ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error)
+	CreateCheckRun(org, repo string, checkRun github.CheckRun) error
+	UpdateCheckRun(org, repo string, id int64, checkRun github.CheckRun) error
+}
+
+type artifactFetcher interface {
+	Artifacts(ctx context.Context, key string) ([]string, error)
+	Artifact(ctx context.Context, key string, artifactName string, sizeLimit int64) (api.Artifact, error)
+}
+
+// Client reports ProwJobs as GitHub check runs. The check runs use the name
+// of the ProwJob as their external ID, which the trigger plugin relies on to
+// re-run the job when the "Re-run" action of a check run is requested.
+type Client struct {
+	gc      githubClient
+	fetcher artifactFetcher
+	config  config.Getter
+}
+
+// NewReporter returns a reporter client. Check runs can only be created by
+// GitHub Apps, so the GitHub client must authenticate as one.
+func NewReporter(gc githubClient, fetcher artifactFetcher, cfg config.Getter) *Client {
+	return &Client{
+		gc:      gc,
+		fetcher: fetcher,
+		config:  cfg,
+	}
+}
+
+// GetName returns the name of the reporter.
+func (c *Client) GetName() string {
+	return GitHubChecksReporterName
+}
+
+// ShouldReport returns whether the ProwJob is reported to GitHub.
+func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *v1.ProwJob) bool {
+	if !pj.Spec.Report || pj.Spec.Refs == nil {
+		return false
+	}
+	switch {
+	case pj.Labels[kube.GerritReportLabel] != "":
+		return false
+	case pj.Spec.Type != v1.PresubmitJob && pj.Spec.Type != v1.PostsubmitJob:
+		return false
+	}
+	return true
+}
+
+// Report creates or updates the check run of the ProwJob.
+func (c *Client) Report(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) ([]*v1.ProwJob, *reconcile.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	checkRun := c.checkRun(ctx, log, pj)
+	refs := pj.Spec.Refs
+	runs, err := c.gc.ListCheckRuns(refs.Org, refs.Repo, checkRun.HeadSHA)
+	if err != nil {
+		return []*v1.ProwJob{pj}, nil, fmt.Errorf("failed to list check runs: %w", err)
+	}
+	for _, run := range runs.CheckRuns {
+		if run.Name == checkRun.Name && run.ExternalID == checkRun.ExternalID {
+			log.WithField("check-run", run.ID).Debug("Updating check run.")
+			return []*v1.ProwJob{pj}, nil, c.gc.UpdateCheckRun(refs.Org, refs.Repo, run.ID, checkRun)
+		}
+	}
+	log.Debug("Creating check run.")
+	return []*v1.ProwJob{pj}, nil, c.gc.CreateCheckRun(refs.Org, refs.Repo, checkRun)
+}
+
+func (c *Client) checkRun(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) github.CheckRun {
+	checkRun := github.CheckRun{
+		Name:       pj.Spec.Context,
+		HeadSHA:    pj.Spec.Refs.BaseSHA,
+		ExternalID: pj.Name,
+		DetailsURL: pj.Status.URL,
+		Output: github.CheckRunOutput{
+			Title:   pj.Status.Description,
+			Summary: summary(pj, nil),
+		},
+	}
+	if checkRun.Name == "" {
+		checkRun.Name = pj.Spec.Job
+	}
+	if len(pj.Spec.Refs.Pulls) > 0 {
+		checkRun.HeadSHA = pj.Spec.Refs.Pulls[0].SHA
+	}
+	if checkRun.Output.Title == "" {
+		checkRun.Output.Title = string(pj.Status.State)
+	}
+	if !pj.Status.StartTime.IsZero() {
+		checkRun.StartedAt = pj.Status.StartTime.Format(time.RFC3339)
+	}
+
+	switch pj.Status.State {
+	case v1.TriggeredState:
+		checkRun.Status = "queued"
+		return checkRun
+	case v1.PendingState:
+		checkRun.Status = "in_progress"
+		return checkRun
+	case v1.SuccessState:
+		checkRun.Conclusion = "success"
+	case v1.AbortedState:
+		checkRun.Conclusion = "cancelled"
+	default:
+		checkRun.Conclusion = "failure"
+	}
+	checkRun.Status = "completed"
+	if pj.Status.CompletionTime != nil {
+		checkRun.CompletedAt = pj.Status.CompletionTime.Format(time.RFC3339)
+	}
+	if pj.Spec.Type == v1.PresubmitJob {
+		checkRun.Actions = []github.CheckRunAction{{
+			Label:       "Re-run",
+			Description: "Re-run this job",
+			Identifier:  pjutil.RerunCheckRunAction,
+		}}
+	}
+
+	jvd, err := c.junitResults(ctx, pj)
+	if err != nil {
+		// The check run is still worth reporting without the test results.
+		log.WithError(err).Warn("Failed to read the JUnit results of the job.")
+		return checkRun
+	}
+	checkRun.Output.Summary = summary(pj, jvd)
+	checkRun.Output.Annotations = annotations(jvd)
+	return checkRun
+}
+
+// junitResults reads the JUnit artifacts of a completed job.
+func (c *Client) junitResults(ctx context.Context, pj *v1.ProwJob) (*junit.JVD, error) {
+	bucket, dir, err := util.GetJobDestination(c.config, pj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the job destination: %w", err)
+	}
+	key, err := providers.StoragePath(bucket, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the storage path: %w", err)
+	}
+	names, err := c.fetcher.Artifacts(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the artifacts: %w", err)
+	}
+	var artifacts []api.Artifact
+	for _, name := range names {
+		if !junitRe.MatchString(name) {
+			continue
+		}
+		artifact, err := c.fetcher.Artifact(ctx, key, name, junitSizeLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get artifact %s: %w", name, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+	jvd := junit.GetJVD(artifacts)
+	return &jvd, nil
+}
+
+func testName(result junit.TestResult) string {
+	test := result.Junit[0]
+	if test.ClassName == "" {
+		return test.Name
+	}
+	return test.ClassName + "." + test.Name
+}
+
+func summary(pj *v1.ProwJob, jvd *junit.JVD) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Job %s is %s.\n", pj.Spec.Job, pj.Status.State)
+	if jvd == nil {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n**%d** tests ran: **%d** failed, **%d** flaky, **%d** skipped and **%d** passed.\n",
+		jvd.NumTests, len(jvd.Failed), len(jvd.Flaky), len(jvd.Skipped), len(jvd.Passed))
+	if len(jvd.Failed) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n### Failed tests\n\n")
+	for i, result := range jvd.Failed {
+		if i == maxListedTests {
+			fmt.Fprintf(&b, "\n... and %d more, see the [job results](%s).\n", len(jvd.Failed)-maxListedTests, pj.Status.URL)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s`\n", testName(result))
+	}
+	return b.String()
+}
+
+// annotations returns an annotation per failed test. JUnit results carry no
+// source location, so the annotations point at the first line of the path
+// named after the class of the test, which GitHub lists in the check run.
+func annotations(jvd *junit.JVD) []github.CheckRunAnnotation {
+	if jvd == nil {
+		return nil
+	}
+	var annotations []github.CheckRunAnnotation
+	for _, result := range jvd.Failed {
+		if len(annotations) == maxAnnotations {
+			break
+		}
+		test := result.Junit[0]
+		path := test.ClassName
+		if path == "" {
+			path = test.Name
+		}
+		message := test.Message(maxMessageLength)
+		if message == "" {
+			message = "Test failed."
+		}
+		annotations = append(annotations, github.CheckRunAnnotation{
+			Path:            path,
+			StartLine:       1,
+			EndLine:         1,
+			AnnotationLevel: "failure",
+			Title:           testName(result),
+			Message:         message,
+		})
+	}
+	return annotations
+}
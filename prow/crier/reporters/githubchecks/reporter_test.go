@@ -0,0 +1,310 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package githubchecks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/spyglass/api"
+)
+
+type fakeGitHubClient struct {
+	existing []github.CheckRun
+	created  []github.CheckRun
+	updated  map[int64]github.CheckRun
+}
+
+func (f *fakeGitHubClient) ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error) {
+	var runs []github.CheckRun
+	for _, run := range f.existing {
+		if run.HeadSHA == ref {
+			runs = append(runs, run)
+		}
+	}
+	return &github.CheckRunList{Total: len(runs), CheckRuns: runs}, nil
+}
+
+func (f *fakeGitHubClient) CreateCheckRun(org, repo string, checkRun github.CheckRun) error {
+	f.created = append(f.created, checkRun)
+	return nil
+}
+
+func (f *fakeGitHubClient) UpdateCheckRun(org, repo string, id int64, checkRun github.CheckRun) error {
+	if f.updated == nil {
+		f.updated = map[int64]github.CheckRun{}
+	}
+	f.updated[id] = checkRun
+	return nil
+}
+
+type fakeArtifact struct {
+	path    string
+	content []byte
+}
+
+func (fa *fakeArtifact) ReadAt(b []byte, off int64) (int, error) {
+	return bytes.NewReader(fa.content).ReadAt(b, off)
+}
+func (fa *fakeArtifact) ReadAtMost(n int64) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(bytes.NewReader(fa.content), n))
+}
+func (fa *fakeArtifact) CanonicalLink() string                  { return "https://storage/" + fa.path }
+func (fa *fakeArtifact) JobPath() string                        { return fa.path }
+func (fa *fakeArtifact) ReadAll() ([]byte, error)               { return fa.content, nil }
+func (fa *fakeArtifact) ReadTail(n int64) ([]byte, error)       { return nil, nil }
+func (fa *fakeArtifact) Size() (int64, error)                   { return int64(len(fa.content)), nil }
+func (fa *fakeArtifact) Metadata() (map[string]string, error)   { return nil, nil }
+func (fa *fakeArtifact) UpdateMetadata(map[string]string) error { return nil }
+
+type fakeFetcher map[string]string
+
+func (f fakeFetcher) Artifacts(_ context.Context, _ string) ([]string, error) {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f fakeFetcher) Artifact(_ context.Context, _ string, artifactName string, _ int64) (api.Artifact, error) {
+	content, ok := f[artifactName]
+	if !ok {
+		return nil, fmt.Errorf("artifact %s not found", artifactName)
+	}
+	return &fakeArtifact{path: artifactName, content: []byte(content)}, nil
+}
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "presubmit",
+			pj: v1.ProwJob{Spec: v1.ProwJobSpec{
+				Type:   v1.PresubmitJob,
+				Report: true,
+				Refs:   &v1.Refs{Org: "org", Repo: "repo"},
+			}},
+			expected: true,
+		},
+		{
+			name: "postsubmit",
+			pj: v1.ProwJob{Spec: v1.ProwJobSpec{
+				Type:   v1.PostsubmitJob,
+				Report: true,
+				Refs:   &v1.Refs{Org: "org", Repo: "repo"},
+			}},
+			expected: true,
+		},
+		{
+			name: "periodic",
+			pj: v1.ProwJob{Spec: v1.ProwJobSpec{
+				Type:   v1.PeriodicJob,
+				Report: true,
+				Refs:   &v1.Refs{Org: "org", Repo: "repo"},
+			}},
+		},
+		{
+			name: "skip report",
+			pj: v1.ProwJob{Spec: v1.ProwJobSpec{
+				Type: v1.PresubmitJob,
+				Refs: &v1.Refs{Org: "org", Repo: "repo"},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewReporter(&fakeGitHubClient{}, fakeFetcher{}, nil)
+			if actual := c.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), &tc.pj); actual != tc.expected {
+				t.Errorf("Expected %t, got %t.", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	startTime := metav1.NewTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	completionTime := metav1.NewTime(startTime.Add(time.Hour))
+	junitXML := `<testsuites>
+  <testsuite name="suite">
+    <testcase classname="pkg/foo" name="TestPass" time="1"></testcase>
+    <testcase classname="pkg/foo" name="TestFail" time="1"><failure message="boom">expected 1, got 2</failure></testcase>
+    <testcase classname="pkg/bar" name="TestSkip" time="0"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+
+	prowJob := func(jobType v1.ProwJobType, state v1.ProwJobState) *v1.ProwJob {
+		pj := &v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-id"},
+			Spec: v1.ProwJobSpec{
+				Type:    jobType,
+				Job:     "some-job",
+				Context: "some-context",
+				Report:  true,
+				Refs:    &v1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "base-sha"},
+				DecorationConfig: &v1.DecorationConfig{
+					GCSConfiguration: &v1.GCSConfiguration{Bucket: "bucket", PathStrategy: v1.PathStrategyExplicit},
+				},
+			},
+			Status: v1.ProwJobStatus{
+				State:       state,
+				Description: "Job " + string(state) + ".",
+				URL:         "https://prow/view/some-id",
+				BuildID:     "123",
+				StartTime:   startTime,
+			},
+		}
+		if jobType == v1.PresubmitJob {
+			pj.Spec.Refs.Pulls = []v1.Pull{{Number: 1, SHA: "head-sha"}}
+		}
+		if pj.Complete() {
+			pj.Status.CompletionTime = &completionTime
+		}
+		return pj
+	}
+	rerun := []github.CheckRunAction{{Label: "Re-run", Description: "Re-run this job", Identifier: "rerun"}}
+
+	testCases := []struct {
+		name            string
+		pj              *v1.ProwJob
+		artifacts       fakeFetcher
+		existing        []github.CheckRun
+		expectedCreated []github.CheckRun
+		expectedUpdated map[int64]github.CheckRun
+	}{
+		{
+			name: "pending presubmit creates an in progress check run",
+			pj:   prowJob(v1.PresubmitJob, v1.PendingState),
+			expectedCreated: []github.CheckRun{{
+				Name:       "some-context",
+				HeadSHA:    "head-sha",
+				ExternalID: "some-id",
+				DetailsURL: "https://prow/view/some-id",
+				Status:     "in_progress",
+				StartedAt:  "2023-06-01T12:00:00Z",
+				Output: github.CheckRunOutput{
+					Title:   "Job pending.",
+					Summary: "Job some-job is pending.\n",
+				},
+			}},
+		},
+		{
+			name:      "failed presubmit updates its check run with the failed tests",
+			pj:        prowJob(v1.PresubmitJob, v1.FailureState),
+			artifacts: fakeFetcher{"artifacts/junit_01.xml": junitXML, "build-log.txt": "not junit"},
+			existing: []github.CheckRun{
+				{ID: 1, Name: "some-context", HeadSHA: "head-sha", ExternalID: "previous-id"},
+				{ID: 2, Name: "some-context", HeadSHA: "head-sha", ExternalID: "some-id"},
+			},
+			expectedUpdated: map[int64]github.CheckRun{2: {
+				Name:        "some-context",
+				HeadSHA:     "head-sha",
+				ExternalID:  "some-id",
+				DetailsURL:  "https://prow/view/some-id",
+				Status:      "completed",
+				Conclusion:  "failure",
+				StartedAt:   "2023-06-01T12:00:00Z",
+				CompletedAt: "2023-06-01T13:00:00Z",
+				Output: github.CheckRunOutput{
+					Title: "Job failure.",
+					Summary: "Job some-job is failure.\n" +
+						"\n**3** tests ran: **1** failed, **0** flaky, **1** skipped and **1** passed.\n" +
+						"\n### Failed tests\n\n- `pkg/foo.TestFail`\n",
+					Annotations: []github.CheckRunAnnotation{{
+						Path:            "pkg/foo",
+						StartLine:       1,
+						EndLine:         1,
+						AnnotationLevel: "failure",
+						Title:           "pkg/foo.TestFail",
+						Message:         "boom\nexpected 1, got 2",
+					}},
+				},
+				Actions: rerun,
+			}},
+		},
+		{
+			name: "successful postsubmit without JUnit results reports on the base SHA",
+			pj:   prowJob(v1.PostsubmitJob, v1.SuccessState),
+			expectedCreated: []github.CheckRun{{
+				Name:        "some-context",
+				HeadSHA:     "base-sha",
+				ExternalID:  "some-id",
+				DetailsURL:  "https://prow/view/some-id",
+				Status:      "completed",
+				Conclusion:  "success",
+				StartedAt:   "2023-06-01T12:00:00Z",
+				CompletedAt: "2023-06-01T13:00:00Z",
+				Output: github.CheckRunOutput{
+					Title:   "Job success.",
+					Summary: "Job some-job is success.\n",
+				},
+			}},
+		},
+		{
+			name: "aborted presubmit is cancelled",
+			pj:   prowJob(v1.PresubmitJob, v1.AbortedState),
+			expectedCreated: []github.CheckRun{{
+				Name:        "some-context",
+				HeadSHA:     "head-sha",
+				ExternalID:  "some-id",
+				DetailsURL:  "https://prow/view/some-id",
+				Status:      "completed",
+				Conclusion:  "cancelled",
+				StartedAt:   "2023-06-01T12:00:00Z",
+				CompletedAt: "2023-06-01T13:00:00Z",
+				Output: github.CheckRunOutput{
+					Title:   "Job aborted.",
+					Summary: "Job some-job is aborted.\n",
+				},
+				Actions: rerun,
+			}},
+		},
+	}
+
+	cfg := func() *config.Config {
+		return &config.Config{}
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gc := &fakeGitHubClient{existing: tc.existing}
+			c := NewReporter(gc, tc.artifacts, cfg)
+			if _, _, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("Failed to report: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCreated, gc.created); diff != "" {
+				t.Errorf("Created check runs mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedUpdated, gc.updated); diff != "" {
+				t.Errorf("Updated check runs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
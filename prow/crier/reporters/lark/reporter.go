@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	larkclient "k8s.io/test-infra/prow/lark"
+)
+
+const reporterName = "larkreporter"
+
+type larkClient interface {
+	SendCard(card interface{}) error
+}
+
+type larkReporter struct {
+	clients map[string]larkClient
+	config  func(*prowapi.Refs) config.LarkReporter
+	dryRun  bool
+}
+
+// card is an interactive message card, see
+// https://open.larksuite.com/document/common-capabilities/message-card/message-cards-content.
+type card struct {
+	Config   cardConfig    `json:"config"`
+	Header   cardHeader    `json:"header"`
+	Elements []cardElement `json:"elements"`
+}
+
+type cardConfig struct {
+	WideScreenMode bool `json:"wide_screen_mode"`
+}
+
+type cardHeader struct {
+	Title    cardText `json:"title"`
+	Template string   `json:"template"`
+}
+
+type cardElement struct {
+	Tag  string   `json:"tag"`
+	Text cardText `json:"text"`
+}
+
+type cardText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+
+// headerColors maps the states of the jobs to the colors of the card headers.
+var headerColors = map[prowapi.ProwJobState]string{
+	prowapi.TriggeredState: "blue",
+	prowapi.PendingState:   "blue",
+	prowapi.SuccessState:   "green",
+	prowapi.FailureState:   "red",
+	prowapi.ErrorState:     "red",
+	prowapi.AbortedState:   "grey",
+}
+
+func newCard(pj *prowapi.ProwJob, content string) *card {
+	return &card{
+		Config: cardConfig{WideScreenMode: true},
+		Header: cardHeader{
+			Title:    cardText{Tag: "plain_text", Content: fmt.Sprintf("%s %s", pj.Spec.Job, pj.Status.State)},
+			Template: headerColors[pj.Status.State],
+		},
+		Elements: []cardElement{{Tag: "div", Text: cardText{Tag: "lark_md", Content: content}}},
+	}
+}
+
+func (lr *larkReporter) getConfig(pj *prowapi.ProwJob) (*config.LarkReporter, *prowapi.LarkReporterConfig) {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	globalConfig := lr.config(refs)
+	var jobLarkConfig *prowapi.LarkReporterConfig
+	if pj.Spec.ReporterConfig != nil && pj.Spec.ReporterConfig.Lark != nil {
+		jobLarkConfig = pj.Spec.ReporterConfig.Lark
+	}
+	return &globalConfig, jobLarkConfig
+}
+
+func (lr *larkReporter) Report(_ context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, lr.report(log, pj)
+}
+
+func (lr *larkReporter) report(log *logrus.Entry, pj *prowapi.ProwJob) error {
+	globalLarkConfig, jobLarkConfig := lr.getConfig(pj)
+	if globalLarkConfig != nil {
+		jobLarkConfig = jobLarkConfig.ApplyDefault(&globalLarkConfig.LarkReporterConfig)
+	}
+	if jobLarkConfig == nil {
+		return errors.New("resolved lark config is empty") // Shouldn't happen at all, just in case
+	}
+
+	client, ok := lr.clients[jobLarkConfig.Channel]
+	if !ok {
+		return fmt.Errorf("channel '%s' not supported", jobLarkConfig.Channel)
+	}
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Parse(jobLarkConfig.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(b, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+	if lr.dryRun {
+		log.WithField("messagetext", b.String()).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := client.SendCard(newCard(pj, b.String())); err != nil {
+		log.WithError(err).Error("failed to send Lark message")
+		return fmt.Errorf("failed to send Lark message: %w", err)
+	}
+	return nil
+}
+
+func (lr *larkReporter) GetName() string {
+	return reporterName
+}
+
+func (lr *larkReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	globalLarkConfig, jobLarkConfig := lr.getConfig(pj)
+
+	var typeShouldReport bool
+	for _, tp := range globalLarkConfig.JobTypesToReport {
+		if tp == pj.Spec.Type {
+			typeShouldReport = true
+			break
+		}
+	}
+
+	// If a user specifically put a channel on their job, they want
+	// it to be reported regardless of the job types setting.
+	jobShouldReport := jobLarkConfig != nil && jobLarkConfig.Channel != ""
+
+	// The job should only be reported if its state has a match with the
+	// JobStatesToReport config.
+	// Note the JobStatesToReport configured in the Prow job can overwrite the
+	// Prow config.
+	var stateShouldReport bool
+	if merged := jobLarkConfig.ApplyDefault(&globalLarkConfig.LarkReporterConfig); merged != nil && merged.JobStatesToReport != nil {
+		if merged.Report != nil && !*merged.Report {
+			logger.WithField("job_states_to_report", merged.JobStatesToReport).Debug("Skip lark reporting as 'report: false', could result from 'job_states_to_report: []'.")
+			return false
+		}
+		for _, stateToReport := range merged.JobStatesToReport {
+			if pj.Status.State == stateToReport {
+				stateShouldReport = true
+				break
+			}
+		}
+	}
+
+	shouldReport := stateShouldReport && (typeShouldReport || jobShouldReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+// New returns a reporter posting to the custom bots of the given getters,
+// keyed by the channel names used in the config.
+func New(cfg func(refs *prowapi.Refs) config.LarkReporter, dryRun bool, bots map[string]func() larkclient.Bot) *larkReporter {
+	clients := map[string]larkClient{}
+	for channel, bot := range bots {
+		clients[channel] = larkclient.NewClient(bot)
+	}
+	return &larkReporter{
+		clients: clients,
+		config:  cfg,
+		dryRun:  dryRun,
+	}
+}
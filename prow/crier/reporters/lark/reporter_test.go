@@ -0,0 +1,226 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	larkclient "k8s.io/test-infra/prow/lark"
+)
+
+func TestShouldReport(t *testing.T) {
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+	testCases := []struct {
+		name      string
+		config    config.LarkReporter
+		jobConfig *v1.LarkReporterConfig
+		state     v1.ProwJobState
+		expected  bool
+	}{
+		{
+			name: "matching job type and state should report",
+			config: config.LarkReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				LarkReporterConfig: v1.LarkReporterConfig{
+					Channel:           "ci-bot",
+					JobStatesToReport: []v1.ProwJobState{v1.SuccessState},
+				},
+			},
+			state:    v1.SuccessState,
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.LarkReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob},
+				LarkReporterConfig: v1.LarkReporterConfig{
+					Channel:           "ci-bot",
+					JobStatesToReport: []v1.ProwJobState{v1.SuccessState},
+				},
+			},
+			state: v1.SuccessState,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.LarkReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				LarkReporterConfig: v1.LarkReporterConfig{
+					Channel:           "ci-bot",
+					JobStatesToReport: []v1.ProwJobState{v1.SuccessState},
+				},
+			},
+			state: v1.PendingState,
+		},
+		{
+			name: "job channel should report regardless of the job type",
+			config: config.LarkReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob},
+				LarkReporterConfig: v1.LarkReporterConfig{
+					Channel:           "ci-bot",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			jobConfig: &v1.LarkReporterConfig{Channel: "team-bot"},
+			state:     v1.FailureState,
+			expected:  true,
+		},
+		{
+			name: "job states override the global ones",
+			config: config.LarkReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				LarkReporterConfig: v1.LarkReporterConfig{
+					Channel:           "ci-bot",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			jobConfig: &v1.LarkReporterConfig{JobStatesToReport: []v1.ProwJobState{v1.SuccessState}},
+			state:     v1.SuccessState,
+			expected:  true,
+		},
+		{
+			name: "report false in the job should not report",
+			config: config.LarkReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				LarkReporterConfig: v1.LarkReporterConfig{
+					Channel:           "ci-bot",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			jobConfig: &v1.LarkReporterConfig{Report: boolPtr(false)},
+			state:     v1.FailureState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgGetter := func(*v1.Refs) config.LarkReporter {
+				return tc.config
+			}
+			pj := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type:           v1.PresubmitJob,
+					ReporterConfig: &v1.ReporterConfig{Lark: tc.jobConfig},
+				},
+				Status: v1.ProwJobStatus{State: tc.state},
+			}
+			reporter := New(cfgGetter, false, nil)
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	const secret = "s3cr3t"
+	var received []map[string]interface{}
+	var invalidSignatures int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("Failed to decode message: %v", err)
+		}
+		timestamp, _ := strconv.ParseInt(msg["timestamp"].(string), 10, 64)
+		if msg["sign"] != larkclient.Sign(timestamp, secret) {
+			invalidSignatures++
+			w.Write([]byte(`{"code":19021,"msg":"sign match fail"}`))
+			return
+		}
+		received = append(received, msg)
+		w.Write([]byte(`{"code":0,"msg":"success"}`))
+	}))
+	defer server.Close()
+
+	bots := map[string]func() larkclient.Bot{
+		"ci-bot":    func() larkclient.Bot { return larkclient.Bot{WebhookURL: server.URL, Secret: secret} },
+		"wrong-bot": func() larkclient.Bot { return larkclient.Bot{WebhookURL: server.URL, Secret: "wrong"} },
+	}
+	pj := func(channel string) *v1.ProwJob {
+		return &v1.ProwJob{
+			Spec: v1.ProwJobSpec{
+				Type:           v1.PeriodicJob,
+				Job:            "ci-periodic",
+				ReporterConfig: &v1.ReporterConfig{Lark: &v1.LarkReporterConfig{Channel: channel}},
+			},
+			Status: v1.ProwJobStatus{State: v1.FailureState, URL: "https://prow/view/1"},
+		}
+	}
+	cfgGetter := func(*v1.Refs) config.LarkReporter {
+		return config.LarkReporter{LarkReporterConfig: v1.LarkReporterConfig{
+			Channel:        "ci-bot",
+			ReportTemplate: "Job **{{.Spec.Job}}** ended with state **{{.Status.State}}**. [View logs]({{.Status.URL}})",
+		}}
+	}
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	reporter := New(cfgGetter, false, bots)
+	if _, _, err := reporter.Report(context.Background(), log, pj("")); err != nil {
+		t.Fatalf("Failed to report: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("Expected a single message, got %d.", len(received))
+	}
+	expected := []map[string]interface{}{{
+		"timestamp": received[0]["timestamp"],
+		"sign":      received[0]["sign"],
+		"msg_type":  "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]interface{}{"wide_screen_mode": true},
+			"header": map[string]interface{}{
+				"title":    map[string]interface{}{"tag": "plain_text", "content": "ci-periodic failure"},
+				"template": "red",
+			},
+			"elements": []interface{}{map[string]interface{}{
+				"tag":  "div",
+				"text": map[string]interface{}{"tag": "lark_md", "content": "Job **ci-periodic** ended with state **failure**. [View logs](https://prow/view/1)"},
+			}},
+		},
+	}}
+	if diff := cmp.Diff(expected, received); diff != "" {
+		t.Errorf("Message mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := reporter.Report(context.Background(), log, pj("wrong-bot")); err == nil {
+		t.Error("Expected an error when the signature is rejected.")
+	}
+	if invalidSignatures != 1 {
+		t.Errorf("Expected a single invalid signature, got %d.", invalidSignatures)
+	}
+	if _, _, err := reporter.Report(context.Background(), log, pj("unknown-bot")); err == nil {
+		t.Error("Expected an error for an unknown channel.")
+	}
+
+	dryRunReporter := New(cfgGetter, true, bots)
+	if _, _, err := dryRunReporter.Report(context.Background(), log, pj("")); err != nil {
+		t.Fatalf("Failed to report in dry-run mode: %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected no message to be sent in dry-run mode, got %d messages.", len(received)-1)
+	}
+}
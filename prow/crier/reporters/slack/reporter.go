@@ -19,11 +19,13 @@ package slack
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"text/template"
 
 	"github.com/sirupsen/logrus"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -34,16 +36,32 @@ import (
 const (
 	reporterName    = "slackreporter"
 	DefaultHostName = "*"
+
+	// MessageAnnotation records the first message posted for a ProwJob, so
+	// that it can be updated or replied to on later states depending on the
+	// message mode.
+	MessageAnnotation = "prow.k8s.io/slack-message"
 )
 
 type slackClient interface {
-	WriteMessage(text, channel string) error
+	PostMessage(msg slackclient.Message) (string, string, error)
+	UpdateMessage(channelID, timestamp string, msg slackclient.Message) error
 }
 
 type slackReporter struct {
 	clients map[string]slackClient
 	config  func(*prowapi.Refs) config.SlackReporter
-	dryRun  bool
+	// pjclient is used to record the posted messages on the ProwJobs.
+	pjclient ctrlruntimeclient.Client
+	dryRun   bool
+}
+
+// postedMessage is the value of the MessageAnnotation.
+type postedMessage struct {
+	Host      string `json:"host"`
+	Channel   string `json:"channel"`
+	ChannelID string `json:"channel_id"`
+	TS        string `json:"ts"`
 }
 
 func hostAndChannel(cfg *prowapi.SlackReporterConfig) (string, string) {
@@ -67,11 +85,11 @@ func (sr *slackReporter) getConfig(pj *prowapi.ProwJob) (*config.SlackReporter,
 	return &globalConfig, jobSlackConfig
 }
 
-func (sr *slackReporter) Report(_ context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
-	return []*prowapi.ProwJob{pj}, nil, sr.report(log, pj)
+func (sr *slackReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, sr.report(ctx, log, pj)
 }
 
-func (sr *slackReporter) report(log *logrus.Entry, pj *prowapi.ProwJob) error {
+func (sr *slackReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
 	globalSlackConfig, jobSlackConfig := sr.getConfig(pj)
 	if globalSlackConfig != nil {
 		jobSlackConfig = jobSlackConfig.ApplyDefault(&globalSlackConfig.SlackReporterConfig)
@@ -95,17 +113,113 @@ func (sr *slackReporter) report(log *logrus.Entry, pj *prowapi.ProwJob) error {
 		log.WithError(err).Error("failed to execute report template")
 		return fmt.Errorf("failed to execute report template: %w", err)
 	}
+	msg := slackclient.Message{Channel: channel, Text: b.String()}
+	if jobSlackConfig.BlockKit != nil && *jobSlackConfig.BlockKit {
+		if msg.Blocks, err = blocks(pj, msg.Text); err != nil {
+			return fmt.Errorf("failed to format Block Kit message: %w", err)
+		}
+	}
 	if sr.dryRun {
-		log.WithField("messagetext", b.String()).Debug("Skipping reporting because dry-run is enabled")
+		log.WithField("messagetext", msg.Text).Debug("Skipping reporting because dry-run is enabled")
 		return nil
 	}
-	if err := client.WriteMessage(b.String(), channel); err != nil {
+
+	mode := jobSlackConfig.MessageMode
+	if prev := previousMessage(pj, host, channel); prev != nil {
+		switch mode {
+		case prowapi.SlackMessageModeUpdate:
+			if err := client.UpdateMessage(prev.ChannelID, prev.TS, msg); err != nil {
+				log.WithError(err).Error("failed to update Slack message")
+				return fmt.Errorf("failed to update Slack message: %w", err)
+			}
+			return nil
+		case prowapi.SlackMessageModeThread:
+			msg.Channel, msg.ThreadTS = prev.ChannelID, prev.TS
+		}
+	}
+	channelID, ts, err := client.PostMessage(msg)
+	if err != nil {
 		log.WithError(err).Error("failed to write Slack message")
 		return fmt.Errorf("failed to write Slack message: %w", err)
 	}
+	if (mode == prowapi.SlackMessageModeUpdate || mode == prowapi.SlackMessageModeThread) && msg.ThreadTS == "" {
+		// The message is sent already, failing here would only post it again.
+		if err := sr.recordMessage(ctx, pj, &postedMessage{Host: host, Channel: channel, ChannelID: channelID, TS: ts}); err != nil {
+			log.WithError(err).Warn("Failed to record the Slack message on the ProwJob, later states will be posted as new messages.")
+		}
+	}
 	return nil
 }
 
+// previousMessage returns the message recorded on the ProwJob, if it was
+// posted to the same channel.
+func previousMessage(pj *prowapi.ProwJob, host, channel string) *postedMessage {
+	raw, ok := pj.Annotations[MessageAnnotation]
+	if !ok {
+		return nil
+	}
+	var prev postedMessage
+	if err := json.Unmarshal([]byte(raw), &prev); err != nil || prev.Host != host || prev.Channel != channel || prev.TS == "" {
+		return nil
+	}
+	return &prev
+}
+
+func (sr *slackReporter) recordMessage(ctx context.Context, pj *prowapi.ProwJob, msg *postedMessage) error {
+	if sr.pjclient == nil {
+		return errors.New("no ProwJob client")
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	newpj := pj.DeepCopy()
+	if newpj.Annotations == nil {
+		newpj.Annotations = map[string]string{}
+	}
+	newpj.Annotations[MessageAnnotation] = string(raw)
+	if err := sr.pjclient.Patch(ctx, newpj, ctrlruntimeclient.MergeFrom(pj)); err != nil {
+		return fmt.Errorf("failed to patch: %w", err)
+	}
+	*pj = *newpj
+	return nil
+}
+
+type block struct {
+	Type     string      `json:"type"`
+	Text     *textObject `json:"text,omitempty"`
+	Elements []element   `json:"elements,omitempty"`
+}
+
+type textObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type element struct {
+	Type string      `json:"type"`
+	Text interface{} `json:"text"`
+	URL  string      `json:"url,omitempty"`
+}
+
+// blocks formats the message with Block Kit: the text of the report
+// template, followed by the state of the job and a button to its logs.
+func blocks(pj *prowapi.ProwJob, text string) (string, error) {
+	res := []block{
+		{Type: "section", Text: &textObject{Type: "mrkdwn", Text: text}},
+		{Type: "context", Elements: []element{{Type: "mrkdwn", Text: fmt.Sprintf("*%s* | %s | %s", pj.Status.State, pj.Spec.Type, pj.Spec.Job)}}},
+	}
+	if pj.Status.URL != "" {
+		res = append(res, block{Type: "actions", Elements: []element{{
+			Type: "button",
+			Text: textObject{Type: "plain_text", Text: "View logs"},
+			URL:  pj.Status.URL,
+		}}})
+	}
+	raw, err := json.Marshal(res)
+	return string(raw), err
+}
+
 func (sr *slackReporter) GetName() string {
 	return reporterName
 }
@@ -153,14 +267,15 @@ func (sr *slackReporter) ShouldReport(_ context.Context, logger *logrus.Entry, p
 	return shouldReport
 }
 
-func New(cfg func(refs *prowapi.Refs) config.SlackReporter, dryRun bool, tokensMap map[string]func() []byte) *slackReporter {
+func New(cfg func(refs *prowapi.Refs) config.SlackReporter, dryRun bool, tokensMap map[string]func() []byte, pjclient ctrlruntimeclient.Client) *slackReporter {
 	clients := map[string]slackClient{}
 	for key, val := range tokensMap {
 		clients[key] = slackclient.NewClient(val)
 	}
 	return &slackReporter{
-		clients: clients,
-		config:  cfg,
-		dryRun:  dryRun,
+		clients:  clients,
+		config:   cfg,
+		pjclient: pjclient,
+		dryRun:   dryRun,
 	}
 }
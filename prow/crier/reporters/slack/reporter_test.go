@@ -18,12 +18,18 @@ package slack
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/config"
+	slackclient "k8s.io/test-infra/prow/slack"
 )
 
 func TestShouldReport(t *testing.T) {
@@ -622,13 +628,22 @@ func TestShouldReportDefaultsToExtraRefs(t *testing.T) {
 
 type fakeSlackClient struct {
 	messages map[string]string
+	// calls records the posts and updates, in order.
+	calls []string
 }
 
-func (fsc *fakeSlackClient) WriteMessage(text, channel string) error {
+func (fsc *fakeSlackClient) PostMessage(msg slackclient.Message) (string, string, error) {
 	if fsc.messages == nil {
 		fsc.messages = map[string]string{}
 	}
-	fsc.messages[channel] = text
+	fsc.messages[msg.Channel] = msg.Text
+	ts := fmt.Sprintf("%d.000", len(fsc.calls)+1)
+	fsc.calls = append(fsc.calls, fmt.Sprintf("post %s thread=%q blocks=%t: %s", msg.Channel, msg.ThreadTS, msg.Blocks != "", msg.Text))
+	return "C" + msg.Channel, ts, nil
+}
+
+func (fsc *fakeSlackClient) UpdateMessage(channelID, timestamp string, msg slackclient.Message) error {
+	fsc.calls = append(fsc.calls, fmt.Sprintf("update %s %s blocks=%t: %s", channelID, timestamp, msg.Blocks != "", msg.Text))
 	return nil
 }
 
@@ -669,3 +684,90 @@ func TestReportDefaultsToExtraRefs(t *testing.T) {
 		t.Errorf("expected the channel 'emergency' to contain message 'there you go' but wasn't the case, all messages: %v", fsc.messages)
 	}
 }
+
+func TestReportMessageModes(t *testing.T) {
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+	testCases := []struct {
+		name          string
+		mode          v1.SlackMessageMode
+		blockKit      *bool
+		expectedCalls []string
+	}{
+		{
+			name: "new message for every state",
+			expectedCalls: []string{
+				`post team thread="" blocks=false: ci-job is pending`,
+				`post team thread="" blocks=false: ci-job is failure`,
+			},
+		},
+		{
+			name: "update the first message",
+			mode: v1.SlackMessageModeUpdate,
+			expectedCalls: []string{
+				`post team thread="" blocks=false: ci-job is pending`,
+				`update Cteam 1.000 blocks=false: ci-job is failure`,
+			},
+		},
+		{
+			name: "reply in a thread",
+			mode: v1.SlackMessageModeThread,
+			expectedCalls: []string{
+				`post team thread="" blocks=false: ci-job is pending`,
+				`post Cteam thread="1.000" blocks=false: ci-job is failure`,
+			},
+		},
+		{
+			name:     "block kit",
+			mode:     v1.SlackMessageModeUpdate,
+			blockKit: boolPtr(true),
+			expectedCalls: []string{
+				`post team thread="" blocks=true: ci-job is pending`,
+				`update Cteam 1.000 blocks=true: ci-job is failure`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pj", Namespace: "prowjobs"},
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+					Job:  "ci-job",
+				},
+				Status: v1.ProwJobStatus{State: v1.PendingState},
+			}
+			pjclient := fakectrlruntimeclient.NewFakeClient(pj.DeepCopy())
+			fsc := &fakeSlackClient{}
+			sr := &slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "team",
+							ReportTemplate: "{{.Spec.Job}} is {{.Status.State}}",
+							MessageMode:    tc.mode,
+							BlockKit:       tc.blockKit,
+						},
+					}
+				},
+				clients:  map[string]slackClient{DefaultHostName: fsc},
+				pjclient: pjclient,
+			}
+
+			for _, state := range []v1.ProwJobState{v1.PendingState, v1.FailureState} {
+				if err := pjclient.Get(context.Background(), types.NamespacedName{Namespace: "prowjobs", Name: "pj"}, pj); err != nil {
+					t.Fatalf("Failed to get ProwJob: %v", err)
+				}
+				pj.Status.State = state
+				if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+					t.Fatalf("Failed to report: %v", err)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedCalls, fsc.calls); diff != "" {
+				t.Errorf("Calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains a reporter POSTing the state of ProwJobs to
+// generic HTTP endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/crier/reporters/criercommonlib"
+)
+
+const (
+	reporterName = "webhookreporter"
+
+	// PayloadVersion is the version of the Payload schema. It is bumped on
+	// any breaking change of the payload.
+	PayloadVersion = "v1"
+
+	// EventHeader is the header holding the type of the event, always
+	// "prowjob" for now.
+	EventHeader = "X-Prow-Event"
+	// VersionHeader is the header holding the PayloadVersion.
+	VersionHeader = "X-Prow-Payload-Version"
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of the
+	// body, in the form sha256=<hex digest>.
+	SignatureHeader = "X-Prow-Signature-256"
+
+	maxRetries     = 4
+	initialBackoff = time.Second
+	requestTimeout = 10 * time.Second
+)
+
+// Payload is the JSON body POSTed to the webhooks.
+type Payload struct {
+	Version        string               `json:"version"`
+	ID             string               `json:"id"`
+	Job            string               `json:"job"`
+	Type           prowapi.ProwJobType  `json:"type"`
+	State          prowapi.ProwJobState `json:"state"`
+	Description    string               `json:"description,omitempty"`
+	URL            string               `json:"url,omitempty"`
+	BuildID        string               `json:"build_id,omitempty"`
+	Cluster        string               `json:"cluster,omitempty"`
+	Refs           *prowapi.Refs        `json:"refs,omitempty"`
+	ExtraRefs      []prowapi.Refs       `json:"extra_refs,omitempty"`
+	StartTime      time.Time            `json:"start_time"`
+	CompletionTime *time.Time           `json:"completion_time,omitempty"`
+}
+
+type webhookReporter struct {
+	client     *http.Client
+	config     func(*prowapi.Refs) config.WebhookReporter
+	hmacSecret func() []byte
+	dryRun     bool
+	// sleep is used to wait between retries, stubbed in tests. It returns
+	// early with an error when the context is done.
+	sleep func(context.Context, time.Duration) error
+}
+
+func (wr *webhookReporter) getConfig(pj *prowapi.ProwJob) (*config.WebhookReporter, *prowapi.WebhookReporterConfig) {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	globalConfig := wr.config(refs)
+	var jobWebhookConfig *prowapi.WebhookReporterConfig
+	if pj.Spec.ReporterConfig != nil && pj.Spec.ReporterConfig.Webhook != nil {
+		jobWebhookConfig = pj.Spec.ReporterConfig.Webhook
+	}
+	return &globalConfig, jobWebhookConfig
+}
+
+// Report POSTs the state of the ProwJob to the webhook configured for it.
+func (wr *webhookReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, wr.report(ctx, log, pj)
+}
+
+func (wr *webhookReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	globalConfig, jobConfig := wr.getConfig(pj)
+	if jobConfig != nil && jobConfig.URL != "" && jobConfig.URL != globalConfig.URL {
+		// The job config may come from an untrusted in-repo config, so it can
+		// only send the signed payloads to the allowed URLs.
+		if err := globalConfig.ValidateJobURL(jobConfig.URL); err != nil {
+			return criercommonlib.UserError(err)
+		}
+	}
+	jobConfig = jobConfig.ApplyDefault(&globalConfig.WebhookReporterConfig)
+	if jobConfig == nil || jobConfig.URL == "" {
+		return errors.New("resolved webhook config has no url") // Shouldn't happen at all, just in case
+	}
+
+	body, err := json.Marshal(newPayload(pj))
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	log = log.WithField("url", jobConfig.URL)
+	if wr.dryRun {
+		log.WithField("payload", string(body)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := wr.post(ctx, jobConfig.URL, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable {
+			// The endpoint rejected the payload, retrying won't help.
+			return criercommonlib.UserError(err)
+		}
+		if attempt > maxRetries {
+			return fmt.Errorf("failed to POST to webhook after %d attempts: %w", attempt, err)
+		}
+		log.WithError(err).WithField("attempt", attempt).Debug("Failed to POST to webhook, retrying.")
+		if err := wr.sleep(ctx, backoff); err != nil {
+			return fmt.Errorf("stopped retrying to POST to webhook after %d attempts: %w", attempt, err)
+		}
+		backoff *= 2
+	}
+}
+
+// sleepContext waits for the duration, or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// post sends the body to the url once. The returned bool tells whether the
+// request can be retried in case of error.
+func (wr *webhookReporter) post(ctx context.Context, url string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "prowjob")
+	req.Header.Set(VersionHeader, PayloadVersion)
+	if wr.hmacSecret != nil {
+		req.Header.Set(SignatureHeader, PayloadSignature(body, wr.hmacSecret()))
+	}
+
+	resp, err := wr.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
+
+func newPayload(pj *prowapi.ProwJob) *Payload {
+	payload := &Payload{
+		Version:     PayloadVersion,
+		ID:          pj.Name,
+		Job:         pj.Spec.Job,
+		Type:        pj.Spec.Type,
+		State:       pj.Status.State,
+		Description: pj.Status.Description,
+		URL:         pj.Status.URL,
+		BuildID:     pj.Status.BuildID,
+		Cluster:     pj.ClusterAlias(),
+		Refs:        pj.Spec.Refs,
+		ExtraRefs:   pj.Spec.ExtraRefs,
+		StartTime:   pj.Status.StartTime.Time,
+	}
+	if pj.Status.CompletionTime != nil {
+		payload.CompletionTime = &pj.Status.CompletionTime.Time
+	}
+	return payload
+}
+
+// PayloadSignature returns the signature of the payload, as set in the
+// SignatureHeader. Receivers should compute it with the shared secret and
+// compare it with the header to authenticate the payloads.
+func PayloadSignature(payload []byte, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (wr *webhookReporter) GetName() string {
+	return reporterName
+}
+
+func (wr *webhookReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	globalConfig, jobConfig := wr.getConfig(pj)
+
+	var typeShouldReport bool
+	for _, tp := range globalConfig.JobTypesToReport {
+		if tp == pj.Spec.Type {
+			typeShouldReport = true
+			break
+		}
+	}
+
+	// If a user specifically put a url on their job, they want it to be
+	// reported regardless of the job types setting.
+	jobShouldReport := jobConfig != nil && jobConfig.URL != ""
+
+	// The JobStatesToReport configured in the Prow job can overwrite the
+	// Prow config.
+	var stateShouldReport bool
+	if merged := jobConfig.ApplyDefault(&globalConfig.WebhookReporterConfig); merged != nil && merged.URL != "" {
+		if merged.Report != nil && !*merged.Report {
+			logger.WithField("job_states_to_report", merged.JobStatesToReport).Debug("Skip webhook reporting as 'report: false', could result from 'job_states_to_report: []'.")
+			return false
+		}
+		for _, stateToReport := range merged.JobStatesToReport {
+			if pj.Status.State == stateToReport {
+				stateShouldReport = true
+				break
+			}
+		}
+	}
+
+	shouldReport := stateShouldReport && (typeShouldReport || jobShouldReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+// New returns a reporter POSTing the state of the ProwJobs to the webhooks
+// configured for them, signing the payloads with the hmacSecret if set.
+func New(cfg func(refs *prowapi.Refs) config.WebhookReporter, dryRun bool, hmacSecret func() []byte) *webhookReporter {
+	return &webhookReporter{
+		client:     &http.Client{},
+		config:     cfg,
+		hmacSecret: hmacSecret,
+		dryRun:     dryRun,
+		sleep:      sleepContext,
+	}
+}
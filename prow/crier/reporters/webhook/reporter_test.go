@@ -0,0 +1,313 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/crier/reporters/criercommonlib"
+)
+
+func TestShouldReport(t *testing.T) {
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+	testCases := []struct {
+		name      string
+		config    config.WebhookReporter
+		jobConfig *v1.WebhookReporterConfig
+		state     v1.ProwJobState
+		expected  bool
+	}{
+		{
+			name: "matching job type and state should report",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					URL:               "http://hook",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			state:    v1.FailureState,
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					URL:               "http://hook",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			state: v1.FailureState,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					URL:               "http://hook",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			state: v1.SuccessState,
+		},
+		{
+			name: "no url should not report",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			state: v1.FailureState,
+		},
+		{
+			name: "job url should report regardless of the job type",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			jobConfig: &v1.WebhookReporterConfig{URL: "http://job-hook"},
+			state:     v1.FailureState,
+			expected:  true,
+		},
+		{
+			name: "job states override the global ones",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					URL:               "http://hook",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			jobConfig: &v1.WebhookReporterConfig{JobStatesToReport: []v1.ProwJobState{v1.PendingState}},
+			state:     v1.PendingState,
+			expected:  true,
+		},
+		{
+			name: "report false in the job should not report",
+			config: config.WebhookReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				WebhookReporterConfig: v1.WebhookReporterConfig{
+					URL:               "http://hook",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			jobConfig: &v1.WebhookReporterConfig{Report: boolPtr(false)},
+			state:     v1.FailureState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgGetter := func(*v1.Refs) config.WebhookReporter {
+				return tc.config
+			}
+			pj := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type:           v1.PresubmitJob,
+					ReporterConfig: &v1.ReporterConfig{Webhook: tc.jobConfig},
+				},
+				Status: v1.ProwJobStatus{State: tc.state},
+			}
+			reporter := New(cfgGetter, false, nil)
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	startTime := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	completionTime := metav1.NewTime(startTime.Add(time.Hour))
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-id"},
+		Spec: v1.ProwJobSpec{
+			Type:  v1.PostsubmitJob,
+			Job:   "post-job",
+			Agent: v1.KubernetesAgent,
+			Refs:  &v1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"},
+		},
+		Status: v1.ProwJobStatus{
+			State:          v1.FailureState,
+			Description:    "Job failed.",
+			URL:            "https://prow/view/some-id",
+			BuildID:        "123",
+			StartTime:      metav1.NewTime(startTime),
+			CompletionTime: &completionTime,
+		},
+	}
+	expectedPayload := &Payload{
+		Version:        PayloadVersion,
+		ID:             "some-id",
+		Job:            "post-job",
+		Type:           v1.PostsubmitJob,
+		State:          v1.FailureState,
+		Description:    "Job failed.",
+		URL:            "https://prow/view/some-id",
+		BuildID:        "123",
+		Cluster:        v1.DefaultClusterAlias,
+		Refs:           &v1.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "abc"},
+		StartTime:      startTime,
+		CompletionTime: &completionTime.Time,
+	}
+
+	testCases := []struct {
+		name      string
+		responses []int
+		dryRun    bool
+		// jobPath is appended to the server URL to override the URL in the job.
+		jobPath          string
+		allowJobURLs     bool
+		cancelRetries    bool
+		expectedRequests int
+		expectedErr      bool
+		expectedUserErr  bool
+	}{
+		{
+			name:             "delivered",
+			responses:        []int{http.StatusOK},
+			expectedRequests: 1,
+		},
+		{
+			name:             "delivered after retries",
+			responses:        []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusAccepted},
+			expectedRequests: 3,
+		},
+		{
+			name:             "retries exhausted",
+			responses:        []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			expectedRequests: 5,
+			expectedErr:      true,
+		},
+		{
+			name:             "rejected payload is not retried",
+			responses:        []int{http.StatusBadRequest},
+			expectedRequests: 1,
+			expectedErr:      true,
+			expectedUserErr:  true,
+		},
+		{
+			name:             "retries stop when the context is done",
+			responses:        []int{http.StatusBadGateway},
+			cancelRetries:    true,
+			expectedRequests: 1,
+			expectedErr:      true,
+		},
+		{
+			name:             "allowed job url",
+			responses:        []int{http.StatusOK},
+			jobPath:          "/job",
+			allowJobURLs:     true,
+			expectedRequests: 1,
+		},
+		{
+			name:            "job url not allowed",
+			jobPath:         "/job",
+			expectedErr:     true,
+			expectedUserErr: true,
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+		},
+	}
+
+	secret := []byte("top-secret")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("Failed to read body: %v", err)
+				}
+				if sig := r.Header.Get(SignatureHeader); sig != PayloadSignature(body, secret) {
+					t.Errorf("Invalid signature %q.", sig)
+				}
+				if version := r.Header.Get(VersionHeader); version != PayloadVersion {
+					t.Errorf("Expected payload version %q, got %q.", PayloadVersion, version)
+				}
+				var payload Payload
+				if err := json.Unmarshal(body, &payload); err != nil {
+					t.Fatalf("Failed to unmarshal payload: %v", err)
+				}
+				if diff := cmp.Diff(expectedPayload, &payload); diff != "" {
+					t.Errorf("Payload mismatch (-want +got):\n%s", diff)
+				}
+				w.WriteHeader(tc.responses[requests-1])
+			}))
+			defer server.Close()
+
+			globalConfig := config.WebhookReporter{WebhookReporterConfig: v1.WebhookReporterConfig{URL: server.URL}}
+			pj := pj.DeepCopy()
+			if tc.jobPath != "" {
+				globalConfig.URL = "https://global.example.com"
+				pj.Spec.ReporterConfig = &v1.ReporterConfig{Webhook: &v1.WebhookReporterConfig{URL: server.URL + tc.jobPath}}
+			}
+			if tc.allowJobURLs {
+				globalConfig.AllowedJobURLs = []string{server.URL}
+			}
+			cfgGetter := func(*v1.Refs) config.WebhookReporter {
+				return globalConfig
+			}
+			reporter := New(cfgGetter, tc.dryRun, func() []byte { return secret })
+			var backoffs []time.Duration
+			reporter.sleep = func(_ context.Context, d time.Duration) error {
+				backoffs = append(backoffs, d)
+				if tc.cancelRetries {
+					return context.Canceled
+				}
+				return nil
+			}
+
+			_, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if criercommonlib.IsUserError(err) != tc.expectedUserErr {
+				t.Errorf("Expected user error: %t, got: %v", tc.expectedUserErr, err)
+			}
+			if requests != tc.expectedRequests {
+				t.Errorf("Expected %d requests, got %d.", tc.expectedRequests, requests)
+			}
+			for i, backoff := range backoffs {
+				if expected := initialBackoff << i; backoff != expected {
+					t.Errorf("Expected backoff %d to be %v, got %v.", i, expected, backoff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// chatBackend is the chat completion endpoint, it's implemented by the OpenAI
+// client which also works with the OpenAI-compatible or self-hosted servers.
+type chatBackend interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+}
+
+// chatBackendRef is a configured backend candidate for the requests.
+type chatBackendRef struct {
+	name          string
+	client        chatBackend
+	model         string
+	contextTokens int
+	// approximate counts tokens without the model encoding, it's used by the
+	// fake backend to avoid downloading the encodings.
+	approximate bool
+}
+
+// numTokens returns the tokens of the messages for the backend model.
+func (b *chatBackendRef) numTokens(messages []openai.ChatCompletionMessage) (int, error) {
+	if b.approximate {
+		tokens := 3
+		for _, m := range messages {
+			tokens += 3 + approximateTokens(m.Role) + approximateTokens(m.Content) + approximateTokens(m.Name)
+		}
+		return tokens, nil
+	}
+
+	return numTokensFromMessages(messages, b.model)
+}
+
+// tokenCounter returns a function to count the tokens of text for the backend model.
+func (b *chatBackendRef) tokenCounter() (func(string) int, error) {
+	if b.approximate {
+		return approximateTokens, nil
+	}
+
+	return tokenCounterFor(b.model)
+}
+
+// fits returns whether the messages and the response fit the model context.
+func (b *chatBackendRef) fits(messages []openai.ChatCompletionMessage, maxResponseTokens int) (bool, int, error) {
+	needTokens, err := b.numTokens(messages)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return needTokens+maxResponseTokens < b.contextTokens, needTokens, nil
+}
+
+// approximateTokens estimates the tokens as one token for every 4 bytes.
+func approximateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// isRateLimited returns whether the error is the HTTP 429 from the server.
+func isRateLimited(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// fakeBackend is a deterministic in-process backend, it responds the fixed
+// response when configured, otherwise a summary of the request.
+type fakeBackend struct {
+	response string
+
+	mu       sync.Mutex
+	requests []openai.ChatCompletionRequest
+	// errs are returned in order by the requests before responding.
+	errs []error
+}
+
+func (f *fakeBackend) CreateChatCompletion(_ context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, request)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	content := f.response
+	if content == "" {
+		content = fmt.Sprintf("fake response to %d messages", len(request.Messages))
+	}
+
+	promptTokens := 3
+	for _, m := range request.Messages {
+		promptTokens += 3 + approximateTokens(m.Role) + approximateTokens(m.Content)
+	}
+	completionTokens := approximateTokens(content)
+
+	return openai.ChatCompletionResponse{
+		Model: request.Model,
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: content,
+				},
+				FinishReason: "stop",
+			},
+		},
+		Usage: openai.Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}, nil
+}
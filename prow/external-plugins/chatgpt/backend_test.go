@@ -0,0 +1,429 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+func Test_chatWithAIServer(t *testing.T) {
+	rateLimited := &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests, Message: "rate limited"}
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: strings.Repeat("a", 400)},
+	}
+
+	tests := []struct {
+		name       string
+		backends   []*fakeBackend
+		contexts   []int
+		budgets    *BudgetsConfig
+		want       string
+		wantErr    bool
+		wantCalled []int
+	}{
+		{
+			name:       "first backend responds",
+			backends:   []*fakeBackend{{response: "first"}, {response: "second"}},
+			contexts:   []int{1000, 1000},
+			want:       "first",
+			wantCalled: []int{1, 0},
+		},
+		{
+			name:       "skip the backend too small",
+			backends:   []*fakeBackend{{response: "first"}, {response: "second"}},
+			contexts:   []int{100, 1000},
+			want:       "second",
+			wantCalled: []int{0, 1},
+		},
+		{
+			name:       "fallback when rate limited",
+			backends:   []*fakeBackend{{response: "first", errs: []error{rateLimited}}, {response: "second"}},
+			contexts:   []int{1000, 1000},
+			want:       "second",
+			wantCalled: []int{1, 1},
+		},
+		{
+			name:       "fallback on errors",
+			backends:   []*fakeBackend{{response: "first", errs: []error{errors.New("boom")}}, {response: "second"}},
+			contexts:   []int{1000, 1000},
+			want:       "second",
+			wantCalled: []int{1, 1},
+		},
+		{
+			name:       "all backends failed",
+			backends:   []*fakeBackend{{errs: []error{rateLimited}}, {errs: []error{errors.New("boom")}}},
+			contexts:   []int{1000, 1000},
+			wantErr:    true,
+			wantCalled: []int{1, 1},
+		},
+		{
+			name:       "no fallback when budget exhausted",
+			backends:   []*fakeBackend{{response: "first"}, {response: "second"}},
+			contexts:   []int{1000, 1000},
+			budgets:    &BudgetsConfig{Orgs: map[string]Budget{"org": {TokensPerDay: 10}}},
+			wantErr:    true,
+			wantCalled: []int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var refs []*chatBackendRef
+			for i, b := range tt.backends {
+				refs = append(refs, &chatBackendRef{
+					name:          string(rune('a' + i)),
+					client:        b,
+					model:         "fake",
+					contextTokens: tt.contexts[i],
+					approximate:   true,
+				})
+			}
+
+			s := &Server{}
+			if tt.budgets != nil {
+				agent := &BudgetAgent{}
+				agent.config = *tt.budgets
+				s.budgetTracker = newBudgetTracker(agent)
+			}
+
+			got, err := s.chatWithAIServer(logrus.NewEntry(logrus.New()), &usageScope{Org: "org", Repo: "repo"}, refs, messages, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("chatWithAIServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("chatWithAIServer() = %q, want %q", got, tt.want)
+			}
+
+			var called []int
+			for _, b := range tt.backends {
+				called = append(called, len(b.requests))
+			}
+			if diff := cmp.Diff(tt.wantCalled, called); diff != "" {
+				t.Errorf("called backends mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestServerHandle(t *testing.T) {
+	const tasks = `
+org/repo:
+  review:
+    always_run: true
+    system_message: You are a reviewer.
+    user_prompt: Please review the pull request.
+    max_response_tokens: 100
+org/files:
+  review:
+    always_run: true
+    per_file: true
+    exclude_paths: ["docs/**"]
+    max_response_tokens: 100
+`
+	smallDiff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n"
+	largeDiff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1,100 @@\n-a\n" +
+		strings.Repeat("+bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb\n", 100)
+
+	multiFileDiff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/zz_generated.go b/zz_generated.go\n--- a/zz_generated.go\n+++ b/zz_generated.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/vendor/x/x.go b/vendor/x/x.go\n--- a/vendor/x/x.go\n+++ b/vendor/x/x.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/docs/a.md b/docs/a.md\n--- a/docs/a.md\n+++ b/docs/a.md\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	tests := []struct {
+		name         string
+		repo         string
+		diff         string
+		comment      *github.IssueComment
+		foreword     string
+		wantComments []string
+	}{
+		{
+			name:         "pull request event with the default backend",
+			diff:         smallDiff,
+			wantComments: []string{"org/repo#1:<!-- chatgpt-task: review sha: abcdef1 -->\nsmall answer"},
+		},
+		{
+			name:         "pull request event selects the large backend by tokens",
+			diff:         largeDiff,
+			wantComments: []string{"org/repo#1:<!-- chatgpt-task: review sha: abcdef1 -->\nlarge answer"},
+		},
+		{
+			name:     "comment trigger",
+			diff:     smallDiff,
+			comment:  &github.IssueComment{Body: "/review any problems?", User: github.User{Login: "user"}, HTMLURL: "https://github.com/org/repo/pull/1#issuecomment-1"},
+			foreword: "any problems?",
+			wantComments: []string{
+				"org/repo#1:" + plugins.FormatICResponse(github.IssueComment{
+					Body:    "/review any problems?",
+					User:    github.User{Login: "user"},
+					HTMLURL: "https://github.com/org/repo/pull/1#issuecomment-1",
+				}, "\nsmall answer"),
+			},
+		},
+		{
+			name: "per file review without excluded files",
+			repo: "files",
+			diff: multiFileDiff,
+			wantComments: []string{
+				"org/files#1:<!-- chatgpt-task: review sha: abcdef1 -->\n#### `a.go`\n\nsmall answer\n\n#### `b.go`\n\nsmall answer",
+			},
+		},
+		{
+			name: "all files excluded",
+			repo: "files",
+			diff: "diff --git a/go.sum b/go.sum\n--- a/go.sum\n+++ b/go.sum\n@@ -1 +1 @@\n-a\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openaiAgent, err := NewWrapOpenaiAgent(
+				writeTestFile(t, "default.yaml", "type: fake\nmodel: fake-small\nmax_tokens: 1000\nfake_response: small answer\n"),
+				writeTestFile(t, "large.yaml", "type: fake\nmodel: fake-large\nmax_tokens: 4096\nfake_response: large answer\n"),
+				"", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			taskAgent, err := NewTaskAgent(writeTestFile(t, "tasks.yaml", tasks), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repo := tt.repo
+			if repo == "" {
+				repo = "repo"
+			}
+			ghc := newFakeGitHubClient()
+			ghc.RemoteFiles = map[string]map[string]string{
+				".gitattributes": {"base": "zz_generated.go linguist-generated=true\n"},
+			}
+			pr := &github.PullRequest{
+				Number: 1,
+				User:   github.User{Login: "author"},
+				Base: github.PullRequestBranch{
+					Ref:  "main",
+					SHA:  "base",
+					Repo: github.Repo{Owner: github.User{Login: "org"}, Name: repo},
+				},
+				Head: github.PullRequestBranch{SHA: "abcdef1"},
+			}
+			ghc.PullRequests[1] = pr
+			ghc.diffs[1] = []byte(tt.diff)
+
+			s := &Server{
+				ghc:                    ghc,
+				issueCommentMatchRegex: regexp.MustCompile(`(?m)^/review\s+(.+)$`),
+				log:                    logrus.NewEntry(logrus.New()),
+				openaiClientAgent:      openaiAgent,
+				openaiTaskAgent:        taskAgent,
+			}
+			if err := s.handle(logrus.NewEntry(logrus.New()), pr, tt.comment, tt.foreword); err != nil {
+				t.Fatalf("handle() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantComments, ghc.IssueCommentsAdded); diff != "" {
+				t.Errorf("comments mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServerHandleReviewMode(t *testing.T) {
+	const tasks = `
+org/repo:
+  review:
+    always_run: true
+    output_mode: review
+    max_response_tokens: 100
+`
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n"
+	structured := `{"summary": "looks good", "findings": []}`
+
+	tests := []struct {
+		name             string
+		response         string
+		comments         []github.IssueComment
+		heads            []string
+		wantReviews      []string
+		wantComments     []string
+		wantEditComments []string
+	}{
+		{
+			name:     "the previous review is outdated",
+			response: structured,
+			heads:    []string{"sha1", "sha2"},
+			wantReviews: []string{
+				outdatedReviewSummaryPrefix + "sha1</summary>\n\nlooks good\n</details>",
+				taskCommentMarker("review", "sha2") + "looks good",
+			},
+		},
+		{
+			name:         "the fallback comment is updated in place",
+			response:     "not structured",
+			heads:        []string{"sha1", "sha2"},
+			wantComments: []string{"org/repo#1:" + taskCommentMarker("review", "sha1") + "not structured"},
+			wantEditComments: []string{
+				"org/repo#1:" + taskCommentMarker("review", "sha2") + "not structured\n\n" +
+					previousReviewSummaryPrefix + "sha1</summary>\n\nnot structured\n</details>",
+			},
+		},
+		{
+			name:     "the previous fallback comment is outdated",
+			response: structured,
+			comments: []github.IssueComment{
+				{ID: 7, Body: taskCommentMarker("review", "sha1") + "not structured", User: github.User{Login: "k8s-ci-robot"}},
+			},
+			heads:       []string{"sha2"},
+			wantReviews: []string{taskCommentMarker("review", "sha2") + "looks good"},
+			wantEditComments: []string{
+				"org/repo#7:" + outdatedReviewSummaryPrefix + "sha1</summary>\n\nnot structured\n</details>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openaiAgent, err := NewWrapOpenaiAgent(
+				writeTestFile(t, "default.yaml", "type: fake\nmodel: fake\nmax_tokens: 1000\nfake_response: '"+tt.response+"'\n"),
+				"", "", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			taskAgent, err := NewTaskAgent(writeTestFile(t, "tasks.yaml", tasks), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ghc := newFakeGitHubClient()
+			ghc.IssueComments[1] = tt.comments
+			ghc.diffs[1] = []byte(diff)
+			s := &Server{
+				ghc:               ghc,
+				log:               logrus.NewEntry(logrus.New()),
+				openaiClientAgent: openaiAgent,
+				openaiTaskAgent:   taskAgent,
+			}
+			for _, head := range tt.heads {
+				pr := &github.PullRequest{
+					Number: 1,
+					User:   github.User{Login: "author"},
+					Base: github.PullRequestBranch{
+						Ref:  "main",
+						SHA:  "base",
+						Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+					},
+					Head: github.PullRequestBranch{SHA: head},
+				}
+				if err := s.handle(logrus.NewEntry(logrus.New()), pr, nil, ""); err != nil {
+					t.Fatalf("handle() error = %v", err)
+				}
+			}
+
+			var reviews []string
+			for _, r := range ghc.Reviews[1] {
+				reviews = append(reviews, r.Body)
+			}
+			if diff := cmp.Diff(tt.wantReviews, reviews); diff != "" {
+				t.Errorf("reviews mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantComments, ghc.IssueCommentsAdded); diff != "" {
+				t.Errorf("comments mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantEditComments, ghc.IssueCommentsEdited); diff != "" {
+				t.Errorf("edited comments mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServerHandleOverBudget(t *testing.T) {
+	const tasks = `
+org/repo:
+  review:
+    always_run: true
+    max_response_tokens: 100
+`
+	openaiAgent, err := NewWrapOpenaiAgent(
+		writeTestFile(t, "default.yaml", "type: fake\nmodel: fake\nmax_tokens: 1000\nfake_response: answer\n"),
+		"", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskAgent, err := NewTaskAgent(writeTestFile(t, "tasks.yaml", tasks), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budgetAgent := &BudgetAgent{}
+	budgetAgent.config = BudgetsConfig{Orgs: map[string]Budget{"org": {TokensPerDay: 10}}}
+
+	ghc := newFakeGitHubClient()
+	ghc.diffs[1] = []byte("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n")
+	s := &Server{
+		ghc:               ghc,
+		log:               logrus.NewEntry(logrus.New()),
+		openaiClientAgent: openaiAgent,
+		openaiTaskAgent:   taskAgent,
+		budgetTracker:     newBudgetTracker(budgetAgent),
+	}
+	// two synchronize events.
+	for _, head := range []string{"sha1", "sha2"} {
+		pr := &github.PullRequest{
+			Number: 1,
+			User:   github.User{Login: "author"},
+			Base: github.PullRequestBranch{
+				Ref:  "main",
+				SHA:  "base",
+				Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+			},
+			Head: github.PullRequestBranch{SHA: head},
+		}
+		if err := s.handle(logrus.NewEntry(logrus.New()), pr, nil, ""); err != nil {
+			t.Fatalf("handle() error = %v", err)
+		}
+	}
+
+	noticePrefix := "org/repo#1:" + taskCommentMarker("review", "base") + taskNoticePrefix + "Sorry, I skip it since the budget"
+	if len(ghc.IssueCommentsAdded) != 1 || !strings.HasPrefix(ghc.IssueCommentsAdded[0], noticePrefix) {
+		t.Errorf("expected a single notice comment, got: %v", ghc.IssueCommentsAdded)
+	}
+	if len(ghc.IssueCommentsEdited) != 1 || !strings.HasPrefix(ghc.IssueCommentsEdited[0], noticePrefix) {
+		t.Errorf("expected the notice comment to be edited, got: %v", ghc.IssueCommentsEdited)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	budgetScopeOrg  = "org"
+	budgetScopeRepo = "repo"
+	budgetScopeUser = "user"
+
+	// budgetWildcard matches any org or user without their own budgets.
+	budgetWildcard = "*"
+)
+
+// BudgetsConfig represent the budgets of the AI requests.
+//
+// The budgets are enforced for every scope separately, a request is rejected
+// when any of the budgets of its org, repo and user is exhausted.
+type BudgetsConfig struct {
+	Orgs  map[string]Budget `yaml:"orgs,omitempty" json:"orgs,omitempty"`   // key: org name or `*`.
+	Repos map[string]Budget `yaml:"repos,omitempty" json:"repos,omitempty"` // key: org/repo.
+	Users map[string]Budget `yaml:"users,omitempty" json:"users,omitempty"` // key: GitHub login or `*`.
+}
+
+// Budget represent the limits for a scope, zero value means no limit.
+type Budget struct {
+	TokensPerDay    int `yaml:"tokens_per_day,omitempty" json:"tokens_per_day,omitempty"`
+	RequestsPerHour int `yaml:"requests_per_hour,omitempty" json:"requests_per_hour,omitempty"`
+}
+
+// BudgetAgent agent for budgets config with watching and hot reload.
+type BudgetAgent struct {
+	ConfigAgent[BudgetsConfig]
+}
+
+// NewBudgetAgent returns a new budgets config loader.
+func NewBudgetAgent(path string, watchInterval time.Duration) (*BudgetAgent, error) {
+	c := &BudgetAgent{ConfigAgent: ConfigAgent[BudgetsConfig]{path: path}}
+	if err := c.Reload(path); err != nil {
+		return nil, err
+	}
+
+	go c.WatchConfig(context.Background(), watchInterval, c.Reload)
+
+	return c, nil
+}
+
+func (a *BudgetAgent) Reload(file string) error {
+	return a.ConfigAgent.Reload(file)
+}
+
+// usageScope is the scope that an AI request belongs to.
+type usageScope struct {
+	Org  string
+	Repo string
+	User string
+	Task string
+}
+
+// budgetsFor returns the budgets for the scope, key is the scope key.
+func (c *BudgetsConfig) budgetsFor(scope *usageScope) map[string]Budget {
+	ret := make(map[string]Budget)
+	if b, ok := c.Orgs[scope.Org]; ok {
+		ret[fmt.Sprintf("%s %s", budgetScopeOrg, scope.Org)] = b
+	} else if b, ok := c.Orgs[budgetWildcard]; ok {
+		ret[fmt.Sprintf("%s %s", budgetScopeOrg, scope.Org)] = b
+	}
+
+	fullName := fmt.Sprintf("%s/%s", scope.Org, scope.Repo)
+	if b, ok := c.Repos[fullName]; ok {
+		ret[fmt.Sprintf("%s %s", budgetScopeRepo, fullName)] = b
+	}
+
+	if scope.User != "" {
+		if b, ok := c.Users[scope.User]; ok {
+			ret[fmt.Sprintf("%s %s", budgetScopeUser, scope.User)] = b
+		} else if b, ok := c.Users[budgetWildcard]; ok {
+			ret[fmt.Sprintf("%s %s", budgetScopeUser, scope.User)] = b
+		}
+	}
+
+	return ret
+}
+
+// budgetExceededError is returned when the request is rejected by budgets.
+type budgetExceededError struct {
+	Scope  string
+	Reason string
+}
+
+func (e *budgetExceededError) Error() string {
+	return fmt.Sprintf("the budget of %s is exhausted: %s", e.Scope, e.Reason)
+}
+
+type usageRecord struct {
+	at     time.Time
+	tokens int
+}
+
+// budgetTracker tracks the usages in memory and enforces the budgets.
+type budgetTracker struct {
+	agent *BudgetAgent
+	now   func() time.Time
+
+	mu     sync.Mutex
+	usages map[string][]*usageRecord
+}
+
+func newBudgetTracker(agent *BudgetAgent) *budgetTracker {
+	return &budgetTracker{
+		agent:  agent,
+		now:    time.Now,
+		usages: make(map[string][]*usageRecord),
+	}
+}
+
+// reserve checks all the budgets of the scope, then records a request with the
+// estimated tokens when none is exhausted. The tokens of the returned record
+// should be settled after the request is done.
+func (t *budgetTracker) reserve(scope *usageScope, tokens int) (*usageRecord, error) {
+	cfg := t.agent.Data()
+	budgets := cfg.budgetsFor(scope)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for key, budget := range budgets {
+		records := t.prune(key, now)
+
+		if budget.RequestsPerHour > 0 {
+			requests := 0
+			for _, r := range records {
+				if now.Sub(r.at) < time.Hour {
+					requests++
+				}
+			}
+			if requests >= budget.RequestsPerHour {
+				budgetRejections.WithLabelValues(scope.Org, scope.Repo).Inc()
+				return nil, &budgetExceededError{
+					Scope:  key,
+					Reason: fmt.Sprintf("%d requests per hour", budget.RequestsPerHour),
+				}
+			}
+		}
+
+		if budget.TokensPerDay > 0 {
+			used := 0
+			for _, r := range records {
+				used += r.tokens
+			}
+			if used+tokens > budget.TokensPerDay {
+				budgetRejections.WithLabelValues(scope.Org, scope.Repo).Inc()
+				return nil, &budgetExceededError{
+					Scope:  key,
+					Reason: fmt.Sprintf("%d tokens per day, %d used and %d needed", budget.TokensPerDay, used, tokens),
+				}
+			}
+		}
+	}
+
+	record := &usageRecord{at: now, tokens: tokens}
+	for key := range budgets {
+		t.usages[key] = append(t.usages[key], record)
+	}
+
+	return record, nil
+}
+
+// settle updates the tokens of the record with the actual usage.
+func (t *budgetTracker) settle(record *usageRecord, tokens int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record.tokens = tokens
+}
+
+// prune drops the records older than one day, it should be called with lock held.
+func (t *budgetTracker) prune(key string, now time.Time) []*usageRecord {
+	records := t.usages[key]
+	i := 0
+	for i < len(records) && now.Sub(records[i].at) >= 24*time.Hour {
+		i++
+	}
+	records = records[i:]
+	t.usages[key] = records
+
+	return records
+}
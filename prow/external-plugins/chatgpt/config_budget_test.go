@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudgetTracker_reserve(t *testing.T) {
+	agent := &BudgetAgent{}
+	agent.config = BudgetsConfig{
+		Orgs: map[string]Budget{
+			"*":    {TokensPerDay: 100},
+			"org1": {TokensPerDay: 1000},
+		},
+		Repos: map[string]Budget{
+			"org1/repo1": {RequestsPerHour: 2},
+		},
+	}
+	now := time.Now()
+	tracker := newBudgetTracker(agent)
+	tracker.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		scope     *usageScope
+		tokens    int
+		advance   time.Duration
+		wantScope string
+	}{
+		{
+			name:   "first request",
+			scope:  &usageScope{Org: "org1", Repo: "repo1"},
+			tokens: 100,
+		},
+		{
+			name:   "second request",
+			scope:  &usageScope{Org: "org1", Repo: "repo1"},
+			tokens: 100,
+		},
+		{
+			name:      "exceed requests per hour of repo",
+			scope:     &usageScope{Org: "org1", Repo: "repo1"},
+			tokens:    100,
+			wantScope: "repo org1/repo1",
+		},
+		{
+			name:   "requests per hour recovered",
+			scope:  &usageScope{Org: "org1", Repo: "repo1"},
+			tokens: 100,
+			// the first two requests fall out of the hour window.
+			advance: time.Hour,
+		},
+		{
+			name:      "exceed tokens per day of org by wildcard",
+			scope:     &usageScope{Org: "org2", Repo: "repo2"},
+			tokens:    101,
+			wantScope: "org org2",
+		},
+		{
+			name:   "other repo in the org",
+			scope:  &usageScope{Org: "org1", Repo: "repo2"},
+			tokens: 700,
+		},
+		{
+			name:      "exceed tokens per day of org",
+			scope:     &usageScope{Org: "org1", Repo: "repo2"},
+			tokens:    1,
+			wantScope: "org org1",
+		},
+		{
+			name:    "tokens per day recovered",
+			scope:   &usageScope{Org: "org1", Repo: "repo2"},
+			tokens:  1000,
+			advance: 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+
+			record, err := tracker.reserve(tt.scope, tt.tokens)
+			if tt.wantScope == "" {
+				if err != nil {
+					t.Fatalf("reserve() error = %v", err)
+				}
+				tracker.settle(record, tt.tokens)
+				return
+			}
+
+			var budgetErr *budgetExceededError
+			if !errors.As(err, &budgetErr) {
+				t.Fatalf("reserve() error = %v, want budget exceeded error", err)
+			}
+			if budgetErr.Scope != tt.wantScope {
+				t.Errorf("reserve() exceeded scope = %s, want %s", budgetErr.Scope, tt.wantScope)
+			}
+		})
+	}
+}
@@ -2,12 +2,25 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// BackendTypeOpenAI is the OpenAI or OpenAI-compatible chat endpoints.
+	BackendTypeOpenAI = "openai"
+	// BackendTypeFake is the deterministic in-process backend for testing.
+	BackendTypeFake = "fake"
+
+	defaultBackendName = "default"
+	largeBackendName   = "large"
 )
 
 type OpenaiConfig struct {
+	Type       string `yaml:"type,omitempty" json:"type,omitempty"` // openai(default) | fake
 	Token      string `yaml:"token,omitempty" json:"token,omitempty"`
 	BaseURL    string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
 	OrgID      string `yaml:"org_id,omitempty" json:"org_id,omitempty"`
@@ -15,12 +28,16 @@ type OpenaiConfig struct {
 	APIVersion string `yaml:"api_version,omitempty" json:"api_version,omitempty"` // 2023-03-15-preview, required when APIType is APITypeAzure or APITypeAzureAD
 	Engine     string `yaml:"engine,omitempty" json:"engine,omitempty"`           // required when APIType is APITypeAzure or APITypeAzureAD, it's the deploy instance name.
 	Model      string `yaml:"model,omitempty" json:"model,omitempty"`             // OpenAI models, list ref: https://github.com/sashabaranov/go-openai/blob/master/completion.go#L15-L38
+	MaxTokens  int    `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`   // context tokens of the model, required for the models not in the builtin list.
 
-	client *openai.Client
+	FakeResponse string `yaml:"fake_response,omitempty" json:"fake_response,omitempty"` // fixed response of the fake backend.
+
+	client chatBackend
 }
 
 func (cfg *OpenaiConfig) initClient() error {
-	if cfg.client == nil {
+	switch cfg.Type {
+	case "", BackendTypeOpenAI:
 		openaiCfg := openai.DefaultConfig(cfg.Token)
 		openaiCfg.BaseURL = cfg.BaseURL
 		openaiCfg.OrgID = cfg.OrgID
@@ -29,11 +46,38 @@ func (cfg *OpenaiConfig) initClient() error {
 		openaiCfg.Engine = cfg.Engine
 
 		cfg.client = openai.NewClientWithConfig(openaiCfg)
+	case BackendTypeFake:
+		cfg.client = &fakeBackend{response: cfg.FakeResponse}
+	default:
+		return fmt.Errorf("unsupported backend type: %s", cfg.Type)
+	}
+
+	if cfg.contextTokens() <= 0 {
+		return fmt.Errorf("max_tokens is required for model %q", cfg.Model)
 	}
 
 	return nil
 }
 
+// contextTokens returns the max tokens of the model context.
+func (cfg *OpenaiConfig) contextTokens() int {
+	if cfg.MaxTokens > 0 {
+		return cfg.MaxTokens
+	}
+
+	return maxTokens[cfg.Model]
+}
+
+func (cfg *OpenaiConfig) backendRef(name string) *chatBackendRef {
+	return &chatBackendRef{
+		name:          name,
+		client:        cfg.client,
+		model:         cfg.Model,
+		contextTokens: cfg.contextTokens(),
+		approximate:   cfg.Type == BackendTypeFake,
+	}
+}
+
 // OpenaiAgent agent for openai clients with watching and hot reload.
 type OpenaiAgent struct {
 	ConfigAgent[OpenaiConfig]
@@ -55,20 +99,54 @@ func (a *OpenaiAgent) Reload(file string) error {
 	return a.ConfigAgent.Reload(file, a.config.initClient)
 }
 
+// BackendsConfig represent the named backends, key is the backend name.
+type BackendsConfig map[string]*OpenaiConfig
+
+// BackendsAgent agent for named backends with watching and hot reload.
+type BackendsAgent struct {
+	ConfigAgent[BackendsConfig]
+}
+
+// NewBackendsAgent returns a new backends loader.
+func NewBackendsAgent(path string, watchInterval time.Duration) (*BackendsAgent, error) {
+	c := &BackendsAgent{ConfigAgent: ConfigAgent[BackendsConfig]{path: path}}
+	if err := c.Reload(path); err != nil {
+		return nil, err
+	}
+
+	go c.WatchConfig(context.Background(), watchInterval, c.Reload)
+
+	return c, nil
+}
+
+func (a *BackendsAgent) Reload(file string) error {
+	return a.ConfigAgent.Reload(file, func() error {
+		for name, cfg := range a.config {
+			if name == defaultBackendName || name == largeBackendName {
+				return fmt.Errorf("backend name %q is reserved", name)
+			}
+			if err := cfg.initClient(); err != nil {
+				return fmt.Errorf("backend %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
 type OpenaiWrapAgent struct {
-	small              *OpenaiAgent
-	large              *OpenaiAgent
-	largeDownThreshold int
+	small    *OpenaiAgent
+	large    *OpenaiAgent
+	backends *BackendsAgent
 }
 
-// NewOpenaiAgent returns a new openai loader.
-func NewWrapOpenaiAgent(defaultPath, largePath string, largeDownThreshold int, watchInterval time.Duration) (*OpenaiWrapAgent, error) {
+// NewWrapOpenaiAgent returns a new openai loader.
+func NewWrapOpenaiAgent(defaultPath, largePath, backendsPath string, watchInterval time.Duration) (*OpenaiWrapAgent, error) {
 	d, err := NewOpenaiAgent(defaultPath, watchInterval)
 	if err != nil {
 		return nil, err
 	}
 
-	ret := &OpenaiWrapAgent{small: d, largeDownThreshold: largeDownThreshold}
+	ret := &OpenaiWrapAgent{small: d}
 	if largePath != "" {
 		l, err := NewOpenaiAgent(largePath, watchInterval)
 		if err != nil {
@@ -78,15 +156,52 @@ func NewWrapOpenaiAgent(defaultPath, largePath string, largeDownThreshold int, w
 		ret.large = l
 	}
 
+	if backendsPath != "" {
+		b, err := NewBackendsAgent(backendsPath, watchInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.backends = b
+	}
+
 	return ret, nil
 }
 
-func (a *OpenaiWrapAgent) ClientFor(msgLen int) (*openai.Client, string) {
-	if a.large != nil &&
-		a.largeDownThreshold > 0 &&
-		msgLen > a.largeDownThreshold {
-		return a.large.Data().client, a.large.Data().Model
+// BackendsFor returns the candidate backends in order of preference.
+//
+// The default backend and the large backend are returned when no names given,
+// the backends given by `openai-config-file` and `openai-config-file-large`
+// options are named `default` and `large`.
+func (a *OpenaiWrapAgent) BackendsFor(names []string) []*chatBackendRef {
+	if len(names) == 0 {
+		names = []string{defaultBackendName, largeBackendName}
+	}
+
+	var ret []*chatBackendRef
+	for _, name := range names {
+		switch name {
+		case defaultBackendName:
+			cfg := a.small.Data()
+			ret = append(ret, cfg.backendRef(name))
+		case largeBackendName:
+			if a.large != nil {
+				cfg := a.large.Data()
+				ret = append(ret, cfg.backendRef(name))
+			}
+		default:
+			if a.backends == nil {
+				logrus.Warnf("backend %s not found, no backends file given.", name)
+				continue
+			}
+			cfg, ok := a.backends.Data()[name]
+			if !ok {
+				logrus.Warnf("backend %s not found.", name)
+				continue
+			}
+			ret = append(ret, cfg.backendRef(name))
+		}
 	}
 
-	return a.small.Data().client, a.small.Data().Model
+	return ret
 }
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -16,11 +18,21 @@ const (
 	defaultPrPatchIntroducePromte = "This is the diff for the pull request:"
 	defaultMaxResponseTokens      = 500
 	defaultTemperature            = 0.7
+	defaultExternalContextPrompt  = "Here are the serval context contents:"
+	defaultExternalContextMaxSize = 32 * 1024
+	defaultExternalContextTTL     = 10 * time.Minute
 	defaultStaticOutHeadnote      = `> **I have already done a preliminary review for you, and I hope to help you do a better job.**
 ------
 `
 )
 
+const (
+	// TaskOutputModeComment posts the response as an issue comment.
+	TaskOutputModeComment = "comment"
+	// TaskOutputModeReview asks for structured findings and posts them as a pull request review with line comments.
+	TaskOutputModeReview = "review"
+)
+
 // TasksConfig represent the all tasks store for the plugin.
 //
 // layer: org|repo / task-name / task-config
@@ -47,23 +59,36 @@ type RepoTasks map[string]*Task
 // > <OutputStaticHeadNote>
 // > responses from AI server.
 //
-// TODO(wuhuizuo): using go template to comose the question.
+// SystemMessage and UserPrompt are go templates rendered with PromptContext,
+// the whole user message layout above can be replaced by MessageTpl.
 type Task struct {
 	Description          string             `yaml:"description,omitempty" json:"description,omitempty"`
 	SystemMessage        string             `yaml:"system_message,omitempty" json:"system_message,omitempty"`
 	UserPrompt           string             `yaml:"user_prompt,omitempty" json:"user_prompt,omitempty"`
+	MessageTpl           string             `yaml:"message_tpl,omitempty" json:"message_tpl,omitempty"` // go template to compose the whole user message.
 	PatchIntroducePrompt string             `yaml:"patch_introduce_prompt,omitempty" json:"patch_introduce_prompt,omitempty"`
 	OutputStaticHeadNote string             `yaml:"output_static_head_note,omitempty" json:"output_static_head_note,omitempty"`
+	OutputMode           string             `yaml:"output_mode,omitempty" json:"output_mode,omitempty"` // comment(default) | review
 	MaxResponseTokens    int                `yaml:"max_response_tokens,omitempty" json:"max_response_tokens,omitempty"`
 	ExternalContexts     []*ExternalContext `yaml:"external_contexts,omitempty" json:"external_contexts,omitempty"`
+	Backends             []string           `yaml:"backends,omitempty" json:"backends,omitempty"` // candidate backend names in order, default: [default, large].
 
 	AlwaysRun       bool     `yaml:"always_run,omitempty" json:"always_run,omitempty"`               // automatic run or should triggered by comments.
 	SkipAuthors     []string `yaml:"skip_authors,omitempty" json:"skip_authors,omitempty"`           // skip the pull request created by the authors.
 	SkipBrancheRegs []string `yaml:"skip_branche_regs,omitempty" json:"skip_branche_regs,omitempty"` // skip the pull requests whiches target branch matched the regex.
 	SkipLabelRegs   []string `yaml:"skip_label_regs,omitempty" json:"skip_label_regs,omitempty"`     // skip the pull reqeusts when any labels matched on the pull request.
 
+	// the vendored, generated and lock files are always excluded.
+	IncludePaths []string `yaml:"include_paths,omitempty" json:"include_paths,omitempty"` // only review the files matched any globs, all files when empty.
+	ExcludePaths []string `yaml:"exclude_paths,omitempty" json:"exclude_paths,omitempty"` // skip the files matched any globs.
+	PerFile      bool     `yaml:"per_file,omitempty" json:"per_file,omitempty"`           // review every file separately and aggregate the results.
+
 	skipBrancheRegs []*regexp.Regexp `yaml:"-" json:"-"`
 	skipLabelRegs   []*regexp.Regexp `yaml:"-" json:"-"`
+
+	systemMessageTpl *template.Template `yaml:"-" json:"-"`
+	userPromptTpl    *template.Template `yaml:"-" json:"-"`
+	messageTpl       *template.Template `yaml:"-" json:"-"`
 }
 
 func (t *Task) initRegexps() error {
@@ -90,14 +115,53 @@ func (t *Task) initRegexps() error {
 	return nil
 }
 
+// ExternalContext represents an external resource that will be composed into the prompt.
+//
+// ResURL can be a http(s) URL, or a file path relative to the root of the pull
+// request's base repository, which will be read at the base SHA of the pull request.
 type ExternalContext struct {
-	PromptTpl  string `yaml:"prompt_tpl,omitempty" json:"prompt_tpl,omitempty"`
-	ResURL     string `yaml:"res_url,omitempty" json:"res_url,omitempty"`
-	resContent []byte //nolint: unused // to be implemented.
+	PromptTpl string `yaml:"prompt_tpl,omitempty" json:"prompt_tpl,omitempty"` // format string with a `%s` verb for the content.
+	ResURL    string `yaml:"res_url,omitempty" json:"res_url,omitempty"`
+	CacheTTL  string `yaml:"cache_ttl,omitempty" json:"cache_ttl,omitempty"` // such as `30m`, default is 10 minutes.
+	MaxSize   int    `yaml:"max_size,omitempty" json:"max_size,omitempty"`   // max bytes of the content, larger content will be truncated.
+
+	cacheTTL time.Duration `yaml:"-" json:"-"`
+}
+
+func (ec *ExternalContext) init() error {
+	if ec.ResURL == "" {
+		return errors.New("res_url is required for external context")
+	}
+
+	ec.cacheTTL = defaultExternalContextTTL
+	if ec.CacheTTL != "" {
+		ttl, err := time.ParseDuration(ec.CacheTTL)
+		if err != nil {
+			return fmt.Errorf("invalid cache_ttl for external context %s: %w", ec.ResURL, err)
+		}
+		ec.cacheTTL = ttl
+	}
+
+	return nil
 }
 
-func (ec *ExternalContext) Content() ([]byte, error) {
-	return nil, errors.New("not implemented")
+// IsRemote returns true when the resource should be fetched by HTTP.
+func (ec *ExternalContext) IsRemote() bool {
+	return strings.HasPrefix(ec.ResURL, "http://") || strings.HasPrefix(ec.ResURL, "https://")
+}
+
+// Content returns the resource content, it will be fetched from cache firstly.
+func (ec *ExternalContext) Content(fetcher *externalContextFetcher, org, repo, baseSHA string) ([]byte, error) {
+	return fetcher.fetch(ec, org, repo, baseSHA)
+}
+
+// Prompt renders the prompt with the given resource content.
+func (ec *ExternalContext) Prompt(content []byte) string {
+	if !strings.Contains(ec.PromptTpl, "%s") {
+		return strings.Join([]string{ec.PromptTpl, string(content)}, "\n")
+	}
+
+	return fmt.Sprintf(ec.PromptTpl, content)
 }
 
 // TaskAgent agent for fetch tasks with watching and hot reload.
@@ -124,6 +188,25 @@ func (a *TaskAgent) Reload(file string) error {
 				if err := task.initRegexps(); err != nil {
 					return err
 				}
+				if err := task.initTemplates(); err != nil {
+					return err
+				}
+				if err := validateGlobs(task.IncludePaths); err != nil {
+					return err
+				}
+				if err := validateGlobs(task.ExcludePaths); err != nil {
+					return err
+				}
+				switch task.OutputMode {
+				case "", TaskOutputModeComment, TaskOutputModeReview:
+				default:
+					return fmt.Errorf("unsupported output mode: %s", task.OutputMode)
+				}
+				for _, ec := range task.ExternalContexts {
+					if err := ec.init(); err != nil {
+						return err
+					}
+				}
 			}
 		}
 		return nil
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+// inResponseToRe matches the link to the comment replied in the bot responses
+// formatted by plugins.FormatICResponse.
+var inResponseToRe = regexp.MustCompile(`\n\n<details>\n\nIn response to \[this\]\(([^)]*)\):`)
+
+type historyItem struct {
+	at      time.Time
+	message openai.ChatCompletionMessage
+}
+
+// conversationHistory rebuilds the earlier conversation before the comment in
+// the pull request, it's composed by:
+//   - the comments triggered the plugin and the bot responses to them.
+//   - the comments posted by the tasks on pull request events.
+//   - the review comments posted by the bot and the replies to them.
+func (s *Server) conversationHistory(pr *github.PullRequest, comment *github.IssueComment) ([]openai.ChatCompletionMessage, error) {
+	org := pr.Base.Repo.Owner.Login
+	repo := pr.Base.Repo.Name
+
+	botUserChecker, err := s.ghc.BotUserChecker()
+	if err != nil {
+		return nil, err
+	}
+	before := func(id int, at time.Time) bool {
+		if id == comment.ID {
+			return false
+		}
+		return comment.CreatedAt.IsZero() || !at.After(comment.CreatedAt)
+	}
+
+	issueComments, err := s.ghc.ListIssueComments(org, repo, pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	commands := make(map[string]bool)
+	for _, c := range issueComments {
+		if !botUserChecker(c.User.Login) && s.issueCommentMatchRegex.MatchString(c.Body) {
+			commands[c.HTMLURL] = true
+		}
+	}
+
+	var items []historyItem
+	for _, c := range issueComments {
+		if !before(c.ID, c.CreatedAt) {
+			continue
+		}
+
+		if !botUserChecker(c.User.Login) {
+			if commands[c.HTMLURL] {
+				items = append(items, historyItem{at: c.CreatedAt, message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleUser,
+					Content: c.Body,
+				}})
+			}
+			continue
+		}
+
+		if content, ok := botResponseContent(c.Body, commands); ok {
+			items = append(items, historyItem{at: c.CreatedAt, message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: content,
+			}})
+		}
+	}
+
+	reviewComments, err := s.ghc.ListPullRequestComments(org, repo, pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	botReviewComments := make(map[int]bool)
+	for _, c := range reviewComments {
+		if botUserChecker(c.User.Login) {
+			botReviewComments[c.ID] = true
+		}
+	}
+	for _, c := range reviewComments {
+		if !before(c.ID, c.CreatedAt) {
+			continue
+		}
+
+		switch {
+		case botReviewComments[c.ID]:
+			items = append(items, historyItem{at: c.CreatedAt, message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: fmt.Sprintf("Review comment on `%s`:\n%s", c.Path, c.Body),
+			}})
+		case botReviewComments[c.InReplyTo]:
+			items = append(items, historyItem{at: c.CreatedAt, message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("Reply to the review comment on `%s`:\n%s", c.Path, c.Body),
+			}})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].at.Before(items[j].at) })
+	var ret []openai.ChatCompletionMessage
+	for _, item := range items {
+		ret = append(ret, item.message)
+	}
+
+	return ret, nil
+}
+
+// botResponseContent returns the content of the bot comment when it's posted
+// by tasks or responded to the commands.
+func botResponseContent(body string, commands map[string]bool) (string, bool) {
+	if taskCommentMarkerRe.MatchString(body) {
+		content := taskCommentMarkerRe.ReplaceAllString(body, "")
+		content, _, _ = strings.Cut(content, "\n\n"+previousReviewSummaryPrefix)
+		return content, true
+	}
+
+	loc := inResponseToRe.FindStringSubmatchIndex(body)
+	if loc == nil || !commands[body[loc[2]:loc[3]]] {
+		return "", false
+	}
+
+	// strip the `@user: ` prefix and the details.
+	content := body[:loc[0]]
+	if strings.HasPrefix(content, "@") {
+		_, content, _ = strings.Cut(content, ": ")
+	}
+
+	return strings.TrimSpace(content), true
+}
+
+// trimHistory drops the oldest messages of the history until it takes no more
+// than maxTokens tokens for the backend.
+func trimHistory(backend *chatBackendRef, history []openai.ChatCompletionMessage, maxTokens int) ([]openai.ChatCompletionMessage, error) {
+	total := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		tokens, err := backend.numTokens(history[i : i+1])
+		if err != nil {
+			return nil, err
+		}
+		// the tokens to prime the reply are counted once for all the messages.
+		tokens -= 3
+		if total+tokens > maxTokens {
+			return history[i+1:], nil
+		}
+		total += tokens
+	}
+
+	return history, nil
+}
+
+// withHistory inserts the history after the system message.
+func withHistory(messages, history []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	if len(history) == 0 || len(messages) == 0 {
+		return messages
+	}
+
+	ret := make([]openai.ChatCompletionMessage, 0, len(messages)+len(history))
+	ret = append(ret, messages[0])
+	ret = append(ret, history...)
+	return append(ret, messages[1:]...)
+}
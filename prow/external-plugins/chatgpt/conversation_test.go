@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+func TestServer_conversationHistory(t *testing.T) {
+	const bot = "k8s-ci-robot"
+	at := func(minute int) time.Time {
+		return time.Date(2023, 5, 1, 0, minute, 0, 0, time.UTC)
+	}
+	question := github.IssueComment{
+		ID:        2,
+		Body:      "/review is it thread safe?",
+		User:      github.User{Login: "user"},
+		HTMLURL:   "https://github.com/org/repo/pull/1#issuecomment-2",
+		CreatedAt: at(2),
+	}
+	current := github.IssueComment{
+		ID:        6,
+		Body:      "/review why?",
+		User:      github.User{Login: "user"},
+		HTMLURL:   "https://github.com/org/repo/pull/1#issuecomment-6",
+		CreatedAt: at(6),
+	}
+
+	ghc := newFakeGitHubClient()
+	ghc.IssueComments[1] = []github.IssueComment{
+		{ID: 1, Body: taskCommentMarker("review", "abc") + "the review", User: github.User{Login: bot}, CreatedAt: at(1)},
+		question,
+		{ID: 3, Body: plugins.FormatICResponse(question, "\nNo, it is not."), User: github.User{Login: bot}, CreatedAt: at(3)},
+		{ID: 4, Body: "/lgtm", User: github.User{Login: "other"}, CreatedAt: at(4)},
+		{ID: 5, Body: "some other bot message", User: github.User{Login: bot}, CreatedAt: at(5)},
+		current,
+		{ID: 7, Body: "/review later question", User: github.User{Login: "user"}, CreatedAt: at(7)},
+	}
+	ghc.PullRequestComments[1] = []github.ReviewComment{
+		{ID: 10, Body: "**[major]** data race", Path: "a.go", User: github.User{Login: bot}, CreatedAt: at(4)},
+		{ID: 11, Body: "where?", Path: "a.go", User: github.User{Login: "user"}, InReplyTo: 10, CreatedAt: at(5)},
+		{ID: 12, Body: "unrelated", Path: "b.go", User: github.User{Login: "user"}, CreatedAt: at(5)},
+	}
+
+	s := &Server{
+		ghc:                    ghc,
+		issueCommentMatchRegex: regexp.MustCompile(`(?m)^/review\s+(.+)$`),
+	}
+	pr := &github.PullRequest{
+		Number: 1,
+		Base:   github.PullRequestBranch{Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"}},
+	}
+
+	got, err := s.conversationHistory(pr, &current)
+	if err != nil {
+		t.Fatalf("conversationHistory() error = %v", err)
+	}
+
+	want := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleAssistant, Content: "the review"},
+		{Role: openai.ChatMessageRoleUser, Content: "/review is it thread safe?"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "No, it is not."},
+		{Role: openai.ChatMessageRoleAssistant, Content: "Review comment on `a.go`:\n**[major]** data race"},
+		{Role: openai.ChatMessageRoleUser, Content: "Reply to the review comment on `a.go`:\nwhere?"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("conversationHistory() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_botResponseContent(t *testing.T) {
+	question := github.IssueComment{
+		Body:    "/review why?",
+		User:    github.User{Login: "user"},
+		HTMLURL: "https://github.com/org/repo/pull/1#issuecomment-1",
+	}
+	commands := map[string]bool{question.HTMLURL: true}
+
+	tests := []struct {
+		name   string
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "task comment",
+			body:   taskCommentMarker("review", "abc") + "the review",
+			want:   "the review",
+			wantOK: true,
+		},
+		{
+			name: "task comment with previous review",
+			body: taskCommentMarker("review", "def") + "the new review\n\n" +
+				previousReviewSummaryPrefix + "abc</summary>\n\nthe review\n</details>",
+			want:   "the new review",
+			wantOK: true,
+		},
+		{
+			name:   "response to a command",
+			body:   plugins.FormatICResponse(question, "\nbecause of it."),
+			want:   "because of it.",
+			wantOK: true,
+		},
+		{
+			name: "response to other comments",
+			body: plugins.FormatICResponse(github.IssueComment{
+				Body:    "/lgtm",
+				User:    github.User{Login: "user"},
+				HTMLURL: "https://github.com/org/repo/pull/1#issuecomment-2",
+			}, "LGTM label has been added."),
+		},
+		{
+			name: "other comments",
+			body: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := botResponseContent(tt.body, commands)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("botResponseContent() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_trimHistory(t *testing.T) {
+	backend := &chatBackendRef{approximate: true}
+	history := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: strings.Repeat("a", 40)},      // 3 + 1 + 10 tokens.
+		{Role: openai.ChatMessageRoleAssistant, Content: strings.Repeat("b", 40)}, // 3 + 3 + 10 tokens.
+		{Role: openai.ChatMessageRoleUser, Content: strings.Repeat("c", 40)},      // 3 + 1 + 10 tokens.
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      []openai.ChatCompletionMessage
+	}{
+		{name: "all fit", maxTokens: 100, want: history},
+		{name: "drop the oldest", maxTokens: 40, want: history[1:]},
+		{name: "only the latest", maxTokens: 20, want: history[2:]},
+		{name: "none fit", maxTokens: 5, want: []openai.ChatCompletionMessage{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := trimHistory(backend, history, tt.maxTokens)
+			if err != nil {
+				t.Fatalf("trimHistory() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("trimHistory() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+)
+
+const (
+	diffFileHeaderPrefix = "diff --git "
+	diffHunkHeaderPrefix = "@@ "
+)
+
+// splitDiff splits the diff into parts, each part takes no more than maxTokens tokens.
+//
+// The diff is split on file boundaries first, then on hunk boundaries for the
+// files too large, the file header will be kept for every hunk of it. Hunks
+// still too large will be split on line boundaries.
+func splitDiff(diff string, maxTokens int, countTokens func(string) int) []string {
+	var units []string
+	for _, file := range splitByLinePrefix(diff, diffFileHeaderPrefix) {
+		if countTokens(file) <= maxTokens {
+			units = append(units, file)
+			continue
+		}
+
+		hunks := splitByLinePrefix(file, diffHunkHeaderPrefix)
+		header := ""
+		if len(hunks) > 0 && !strings.HasPrefix(hunks[0], diffHunkHeaderPrefix) {
+			header, hunks = hunks[0], hunks[1:]
+		}
+		if len(hunks) == 0 {
+			units = append(units, splitLines("", header, maxTokens, countTokens)...)
+			continue
+		}
+
+		for _, hunk := range hunks {
+			if unit := header + hunk; countTokens(unit) <= maxTokens {
+				units = append(units, unit)
+				continue
+			}
+			units = append(units, splitLines(header, hunk, maxTokens, countTokens)...)
+		}
+	}
+
+	return packUnits(units, maxTokens, countTokens)
+}
+
+// splitByLinePrefix splits the text into segments, every segment starts with a
+// line having the given prefix except the first one.
+func splitByLinePrefix(text, prefix string) []string {
+	var segments []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, prefix) && current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	}
+
+	return segments
+}
+
+// splitLines splits the text on line boundaries, every piece is prefixed with the header.
+//
+// A single line larger than maxTokens will be kept as one piece.
+func splitLines(header, text string, maxTokens int, countTokens func(string) int) []string {
+	var pieces []string
+	current := header
+	currentTokens := countTokens(header)
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+
+		lineTokens := countTokens(line)
+		if current != header && currentTokens+lineTokens > maxTokens {
+			pieces = append(pieces, current)
+			current, currentTokens = header, countTokens(header)
+		}
+		current += line
+		currentTokens += lineTokens
+	}
+	if current != header {
+		pieces = append(pieces, current)
+	}
+
+	return pieces
+}
+
+// packUnits joins the adjacent units together as long as they fit in maxTokens.
+func packUnits(units []string, maxTokens int, countTokens func(string) int) []string {
+	var parts []string
+	var current strings.Builder
+	currentTokens := 0
+	for _, unit := range units {
+		unitTokens := countTokens(unit)
+		if current.Len() > 0 && currentTokens+unitTokens > maxTokens {
+			parts = append(parts, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		current.WriteString(unit)
+		currentTokens += unitTokens
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+
+	return parts
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	testFileHeaderA = "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n"
+	testHunkA1      = "@@ -1,1 +1,1 @@\n-a1\n+A1\n"
+	testHunkA2      = "@@ -9,1 +9,1 @@\n-a2\n+A2\n"
+	testFileHeaderB = "diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n"
+	testHunkB1      = "@@ -1,1 +1,1 @@\n-b1\n+B1\n"
+)
+
+func Test_splitDiff(t *testing.T) {
+	fileA := testFileHeaderA + testHunkA1 + testHunkA2
+	fileB := testFileHeaderB + testHunkB1
+	countBytes := func(s string) int { return len(s) }
+
+	tests := []struct {
+		name      string
+		diff      string
+		maxTokens int
+		want      []string
+	}{
+		{
+			name:      "fit in one part",
+			diff:      fileA + fileB,
+			maxTokens: len(fileA + fileB),
+			want:      []string{fileA + fileB},
+		},
+		{
+			name:      "split on file boundaries",
+			diff:      fileA + fileB,
+			maxTokens: len(fileA),
+			want:      []string{fileA, fileB},
+		},
+		{
+			name:      "split on hunk boundaries",
+			diff:      fileA + fileB,
+			maxTokens: len(testFileHeaderA + testHunkA1),
+			want: []string{
+				testFileHeaderA + testHunkA1,
+				testFileHeaderA + testHunkA2,
+				fileB,
+			},
+		},
+		{
+			name:      "split on line boundaries",
+			diff:      fileB,
+			maxTokens: len(testFileHeaderB) + len("@@ -1,1 +1,1 @@\n-b1\n"),
+			want: []string{
+				testFileHeaderB + "@@ -1,1 +1,1 @@\n-b1\n",
+				testFileHeaderB + "+B1\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitDiff(tt.diff, tt.maxTokens, countBytes)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("splitDiff() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_groupParts(t *testing.T) {
+	countBytes := func(s string) int { return len(s) }
+	parts := []string{"aaaa", "bbbb", "cccc"}
+
+	got := groupParts(parts, 2*(4+splitorHoldingTokenCount), countBytes)
+	want := [][]string{{"aaaa", "bbbb"}, {"cccc"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("groupParts() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fileGetter interface {
+	GetFile(org, repo, filepath, commit string) ([]byte, error)
+}
+
+type externalContextCacheItem struct {
+	content  []byte
+	expireAt time.Time
+}
+
+// externalContextFetcher fetches the external context contents with TTL cache.
+type externalContextFetcher struct {
+	ghc        fileGetter
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]externalContextCacheItem
+}
+
+func newExternalContextFetcher(ghc fileGetter) *externalContextFetcher {
+	return &externalContextFetcher{
+		ghc:        ghc,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+		cache:      make(map[string]externalContextCacheItem),
+	}
+}
+
+func (f *externalContextFetcher) fetch(ec *ExternalContext, org, repo, baseSHA string) ([]byte, error) {
+	maxSize := ec.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultExternalContextMaxSize
+	}
+
+	var key string
+	var getter func() ([]byte, error)
+	if ec.IsRemote() {
+		key = ec.ResURL
+		getter = func() ([]byte, error) { return f.fetchHTTP(ec.ResURL, maxSize) }
+	} else {
+		filePath := strings.TrimPrefix(ec.ResURL, "/")
+		key = fmt.Sprintf("%s/%s@%s:%s", org, repo, baseSHA, filePath)
+		getter = func() ([]byte, error) { return f.ghc.GetFile(org, repo, filePath, baseSHA) }
+	}
+
+	// the content is cut to the max size before being cached, the tasks
+	// sharing the resource with different max sizes can't share the content.
+	cacheKey := fmt.Sprintf("%s#%d", key, maxSize)
+	if content, ok := f.get(cacheKey); ok {
+		return content, nil
+	}
+
+	content, err := getter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch external context %s: %w", key, err)
+	}
+
+	if len(content) > maxSize {
+		content = content[:maxSize]
+	}
+
+	ttl := ec.cacheTTL
+	if ttl == 0 {
+		ttl = defaultExternalContextTTL
+	}
+	f.set(cacheKey, content, ttl)
+
+	return content, nil
+}
+
+// fetchHTTP fetches the content of the URL. At most maxSize bytes are read, so
+// that a huge response can't exhaust the memory.
+func (f *externalContextFetcher) fetchHTTP(url string, maxSize int) ([]byte, error) {
+	resp, err := f.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+}
+
+func (f *externalContextFetcher) get(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.cache[key]
+	if !ok || f.now().After(item.expireAt) {
+		return nil, false
+	}
+
+	return item.content, true
+}
+
+func (f *externalContextFetcher) set(key string, content []byte, ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// purge the expired items to avoid the cache growing forever.
+	now := f.now()
+	for k, item := range f.cache {
+		if now.After(item.expireAt) {
+			delete(f.cache, k)
+		}
+	}
+
+	f.cache[key] = externalContextCacheItem{content: content, expireAt: now.Add(ttl)}
+}
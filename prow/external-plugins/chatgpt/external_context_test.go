@@ -0,0 +1,195 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeFileGetter struct {
+	files map[string][]byte
+	calls int
+}
+
+func (f *fakeFileGetter) GetFile(org, repo, filepath, commit string) ([]byte, error) {
+	f.calls++
+	content, ok := f.files[fmt.Sprintf("%s/%s@%s:%s", org, repo, commit, filepath)]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found", filepath)
+	}
+	return content, nil
+}
+
+func TestExternalContextFetcher(t *testing.T) {
+	httpCalls := 0
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpCalls++
+		switch r.URL.Path {
+		case "/guide.md":
+			fmt.Fprint(w, "remote guide content")
+		case "/large.md":
+			fmt.Fprint(w, strings.Repeat("remote large content ", 1000))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer httpServer.Close()
+
+	ghc := &fakeFileGetter{files: map[string][]byte{
+		"org/repo@sha1:docs/guide.md": []byte("repo guide content"),
+	}}
+	now := time.Now()
+	fetcher := newExternalContextFetcher(ghc)
+	fetcher.now = func() time.Time { return now }
+
+	tests := []struct {
+		name          string
+		ec            *ExternalContext
+		advance       time.Duration
+		want          string
+		wantErr       bool
+		wantHTTPCalls int
+		wantGitCalls  int
+	}{
+		{
+			name:          "fetch remote resource",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/guide.md"},
+			want:          "remote guide content",
+			wantHTTPCalls: 1,
+		},
+		{
+			name:          "remote resource hit cache",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/guide.md"},
+			want:          "remote guide content",
+			wantHTTPCalls: 1,
+		},
+		{
+			name:          "remote resource expired",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/guide.md"},
+			advance:       defaultExternalContextTTL + time.Second,
+			want:          "remote guide content",
+			wantHTTPCalls: 2,
+		},
+		{
+			name:          "remote resource not found",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/not-found.md"},
+			wantErr:       true,
+			wantHTTPCalls: 3,
+		},
+		{
+			name:          "fetch repo file with size limit",
+			ec:            &ExternalContext{ResURL: "/docs/guide.md", MaxSize: 4},
+			want:          "repo",
+			wantHTTPCalls: 3,
+			wantGitCalls:  1,
+		},
+		{
+			name:          "repo file hit cache",
+			ec:            &ExternalContext{ResURL: "docs/guide.md", MaxSize: 4},
+			want:          "repo",
+			wantHTTPCalls: 3,
+			wantGitCalls:  1,
+		},
+		{
+			name:          "fetch large remote resource with size limit",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/large.md", MaxSize: 6},
+			want:          "remote",
+			wantHTTPCalls: 4,
+			wantGitCalls:  1,
+		},
+		{
+			name:          "same remote resource with larger size limit",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/large.md", MaxSize: 12},
+			want:          "remote large",
+			wantHTTPCalls: 5,
+			wantGitCalls:  1,
+		},
+		{
+			name:          "same remote resource with smaller size limit hit cache",
+			ec:            &ExternalContext{ResURL: httpServer.URL + "/large.md", MaxSize: 6},
+			want:          "remote",
+			wantHTTPCalls: 5,
+			wantGitCalls:  1,
+		},
+		{
+			name:          "same repo file with larger size limit",
+			ec:            &ExternalContext{ResURL: "docs/guide.md", MaxSize: 10},
+			want:          "repo guide",
+			wantHTTPCalls: 5,
+			wantGitCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ec.init(); err != nil {
+				t.Fatalf("init() error = %v", err)
+			}
+			now = now.Add(tt.advance)
+
+			got, err := tt.ec.Content(fetcher, "org", "repo", "sha1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Content() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Content() = %q, want %q", got, tt.want)
+			}
+			if httpCalls != tt.wantHTTPCalls {
+				t.Errorf("http calls = %d, want %d", httpCalls, tt.wantHTTPCalls)
+			}
+			if ghc.calls != tt.wantGitCalls {
+				t.Errorf("GetFile calls = %d, want %d", ghc.calls, tt.wantGitCalls)
+			}
+		})
+	}
+}
+
+func TestExternalContextPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     string
+		content string
+		want    string
+	}{
+		{
+			name:    "format verb",
+			tpl:     "The guide: %s",
+			content: "abc",
+			want:    "The guide: abc",
+		},
+		{
+			name:    "without format verb",
+			tpl:     "The guide:",
+			content: "abc",
+			want:    "The guide:\nabc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := &ExternalContext{PromptTpl: tt.tpl}
+			if got := ec.Prompt([]byte(tt.content)); got != tt.want {
+				t.Errorf("Prompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
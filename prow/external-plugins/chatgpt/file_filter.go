@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const globZeroToManyDirs = "**"
+
+// defaultExcludedPaths are the vendored and lock files, they are never reviewed.
+var defaultExcludedPaths = []string{
+	"**/vendor/**",
+	"**/third_party/**",
+	"**/node_modules/**",
+	"go.sum",
+	"*.lock",
+	"package-lock.json",
+	"pnpm-lock.yaml",
+}
+
+// fileFilter decides whether a changed file should be reviewed by a task.
+type fileFilter struct {
+	includePaths []string
+	excludePaths []string
+	// isGenerated reports whether the file is generated, such as the ones
+	// marked by `linguist-generated=true` in the .gitattributes file.
+	isGenerated func(name string) bool
+}
+
+// match returns whether the file should be reviewed.
+func (f *fileFilter) match(name string) bool {
+	for _, p := range defaultExcludedPaths {
+		if matchGlob(p, name) {
+			return false
+		}
+	}
+	if f.isGenerated != nil && f.isGenerated(name) {
+		return false
+	}
+	for _, p := range f.excludePaths {
+		if matchGlob(p, name) {
+			return false
+		}
+	}
+	if len(f.includePaths) == 0 {
+		return true
+	}
+	for _, p := range f.includePaths {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterDiff drops the diffs of the files not matched, returns the filtered diff
+// and the names of the dropped files.
+func (f *fileFilter) filterDiff(diff string) (string, []string) {
+	var kept strings.Builder
+	var dropped []string
+	for _, fileDiff := range splitByLinePrefix(diff, diffFileHeaderPrefix) {
+		// keep the content not belong to any files as is.
+		if name := diffFileName(fileDiff); name != "" && !f.match(name) {
+			dropped = append(dropped, name)
+			continue
+		}
+		kept.WriteString(fileDiff)
+	}
+
+	return kept.String(), dropped
+}
+
+// matchGlob matches the file path with the glob pattern.
+//
+// The pattern without a slash matches the base name of the file, otherwise it
+// matches the whole path, and `**` matches zero or more directories.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(name))
+		return matched
+	}
+
+	return matchGlobSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == globZeroToManyDirs {
+			for i := 0; i <= len(names); i++ {
+				if matchGlobSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(patterns[0], names[0]); !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
+
+// validateGlobs checks the syntax of the glob patterns.
+func validateGlobs(patterns []string) error {
+	for _, p := range patterns {
+		for _, segment := range strings.Split(p, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path glob %q: %w", p, err)
+			}
+		}
+	}
+
+	return nil
+}
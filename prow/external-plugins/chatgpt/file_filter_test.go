@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.go", name: "main.go", want: true},
+		{pattern: "*.go", name: "pkg/a/main.go", want: true},
+		{pattern: "*.go", name: "main.py", want: false},
+		{pattern: "pkg/*.go", name: "pkg/main.go", want: true},
+		{pattern: "pkg/*.go", name: "pkg/a/main.go", want: false},
+		{pattern: "/pkg/*.go", name: "pkg/main.go", want: true},
+		{pattern: "pkg/**", name: "pkg/a/b/main.go", want: true},
+		{pattern: "pkg/**/*.go", name: "pkg/main.go", want: true},
+		{pattern: "pkg/**/*.go", name: "pkg/a/b/main.go", want: true},
+		{pattern: "pkg/**/*.go", name: "cmd/main.go", want: false},
+		{pattern: "**/vendor/**", name: "vendor/a/a.go", want: true},
+		{pattern: "**/vendor/**", name: "hack/tools/vendor/a.go", want: true},
+		{pattern: "**/vendor/**", name: "pkg/vendors/a.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("matchGlob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fileFilter_filterDiff(t *testing.T) {
+	fileDiff := func(name string) string {
+		return "diff --git a/" + name + " b/" + name + "\n--- a/" + name + "\n+++ b/" + name + "\n@@ -1 +1 @@\n-a\n+b\n"
+	}
+	diff := fileDiff("main.go") +
+		fileDiff("go.sum") +
+		fileDiff("vendor/a/a.go") +
+		fileDiff("zz_generated.deepcopy.go") +
+		fileDiff("docs/README.md") +
+		fileDiff("pkg/a.go")
+
+	tests := []struct {
+		name        string
+		filter      *fileFilter
+		wantDiff    string
+		wantDropped []string
+	}{
+		{
+			name:        "default exclusions",
+			filter:      &fileFilter{},
+			wantDiff:    fileDiff("main.go") + fileDiff("zz_generated.deepcopy.go") + fileDiff("docs/README.md") + fileDiff("pkg/a.go"),
+			wantDropped: []string{"go.sum", "vendor/a/a.go"},
+		},
+		{
+			name: "generated files",
+			filter: &fileFilter{
+				isGenerated: func(name string) bool { return name == "zz_generated.deepcopy.go" },
+			},
+			wantDiff:    fileDiff("main.go") + fileDiff("docs/README.md") + fileDiff("pkg/a.go"),
+			wantDropped: []string{"go.sum", "vendor/a/a.go", "zz_generated.deepcopy.go"},
+		},
+		{
+			name: "include and exclude paths",
+			filter: &fileFilter{
+				includePaths: []string{"*.go"},
+				excludePaths: []string{"pkg/**"},
+			},
+			wantDiff:    fileDiff("main.go") + fileDiff("zz_generated.deepcopy.go"),
+			wantDropped: []string{"go.sum", "vendor/a/a.go", "docs/README.md", "pkg/a.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDiff, gotDropped := tt.filter.filterDiff(diff)
+			if diff := cmp.Diff(tt.wantDiff, gotDiff); diff != "" {
+				t.Errorf("filterDiff() diff mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantDropped, gotDropped); diff != "" {
+				t.Errorf("filterDiff() dropped mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -28,10 +28,12 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/test-infra/pkg/flagutil"
+	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/config/secret"
 	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	"k8s.io/test-infra/prow/interrupts"
 	"k8s.io/test-infra/prow/logrusutil"
+	"k8s.io/test-infra/prow/metrics"
 	"k8s.io/test-infra/prow/pjutil"
 	"k8s.io/test-infra/prow/pluginhelp/externalplugins"
 )
@@ -41,9 +43,12 @@ type options struct {
 
 	openaiConfigFile           string
 	openaiConfigFileLarge      string
+	openaiBackendsFile         string
 	openaiTasksFile            string
 	openaiConfigReloadInterval time.Duration
 	openaiTasksReloadInterval  time.Duration
+	budgetsFile                string
+	budgetsReloadInterval      time.Duration
 
 	largeDownThreshold  int
 	maxAcceptDiffSize   int
@@ -75,11 +80,14 @@ func gatherOptions() options {
 	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
 	fs.StringVar(&o.openaiConfigFile, "openai-config-file", "/etc/openai/config.yaml", "Path to the file containing the access credential.")
 	fs.StringVar(&o.openaiConfigFileLarge, "openai-config-file-large", "", "Path to the file containing the access credential route for large pull requests.")
+	fs.StringVar(&o.openaiBackendsFile, "openai-backends-file", "", "Path to the file containing the named backends which can be selected by tasks.")
 	fs.DurationVar(&o.openaiConfigReloadInterval, "openai-config-reload-interval", time.Minute, "Interval to reload the openai access credential file.")
 	fs.StringVar(&o.openaiTasksFile, "openai-tasks-file", "/etc/openai/tasks.yaml", "Path to the file containing the default openai tasks.")
 	fs.DurationVar(&o.openaiTasksReloadInterval, "openai-tasks-reload-interval", time.Minute, "Interval to reload the openai tasks file.")
-	fs.IntVar(&o.largeDownThreshold, "large-down-threshold", 3*4096, "down threshold bytes of message will route to client given by `openai-config-file-large` option.")
-	fs.IntVar(&o.maxAcceptDiffSize, "max-accept-diff-size", 80000, "maximum bytes of PR diff")
+	fs.StringVar(&o.budgetsFile, "budgets-file", "", "Path to the file containing the budgets of orgs, repos and users, no limit when empty.")
+	fs.DurationVar(&o.budgetsReloadInterval, "budgets-reload-interval", time.Minute, "Interval to reload the budgets file.")
+	fs.IntVar(&o.largeDownThreshold, "large-down-threshold", 3*4096, "Deprecated: the backend is selected by the tokens of message, it has no effect now.")
+	fs.IntVar(&o.maxAcceptDiffSize, "max-accept-diff-size", 500000, "maximum bytes of PR diff, the larger diffs are skipped. The diff too large for one message will be sent in multiple parts. 0 means no limit.")
 	fs.StringVar(&o.issueCommentCommand, "issue-comment-command", "review", "comment command to match for, such as `command1` (you should send comment with `/command1 ...`)")
 	fs.StringVar(&o.logLevel, "log-level", "debug", fmt.Sprintf("Log level is one of %v.", logrus.AllLevels))
 	for _, group := range []flagutil.OptionGroup{&o.github, &o.instrumentationOptions} {
@@ -112,7 +120,7 @@ func main() {
 		logrus.WithError(err).Fatal("Error getting GitHub client.")
 	}
 
-	openaiAgent, err := NewWrapOpenaiAgent(o.openaiConfigFile, o.openaiConfigFileLarge, o.largeDownThreshold, o.openaiConfigReloadInterval)
+	openaiAgent, err := NewWrapOpenaiAgent(o.openaiConfigFile, o.openaiConfigFileLarge, o.openaiBackendsFile, o.openaiConfigReloadInterval)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error load OpenAI config.")
 	}
@@ -122,6 +130,15 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to start task agent")
 	}
 
+	var tracker *budgetTracker
+	if o.budgetsFile != "" {
+		budgetAgent, err := NewBudgetAgent(o.budgetsFile, o.budgetsReloadInterval)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to start budget agent")
+		}
+		tracker = newBudgetTracker(budgetAgent)
+	}
+
 	issueCommentMatchRegex := regexp.MustCompile(fmt.Sprintf(`(?m)^/%s\s+(.+)$`, o.issueCommentCommand))
 	server := &Server{
 		ghc:                    githubClient,
@@ -129,10 +146,14 @@ func main() {
 		log:                    log,
 		openaiClientAgent:      openaiAgent,
 		openaiTaskAgent:        taskAgent,
+		contextFetcher:         newExternalContextFetcher(githubClient),
+		budgetTracker:          tracker,
 		maxDiffSize:            o.maxAcceptDiffSize,
 		tokenGenerator:         secret.GetTokenGenerator(o.webhookSecretFile),
 	}
 
+	metrics.ExposeMetrics(pluginName, config.PushGateway{}, o.instrumentationOptions.MetricsPort)
+
 	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	health.ServeReady()
 
@@ -7,6 +7,8 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
+const defaultEncoding = "cl100k_base"
+
 // Ref: https://platform.openai.com/docs/models
 var maxTokens = map[string]int{
 	openai.CodexCodeDavinci002: 8001,
@@ -20,11 +22,26 @@ var maxTokens = map[string]int{
 	openai.GPT432K0314:         32768,
 }
 
+// encodingForModel returns the encoding of the model, the models unknown such
+// as self-hosted ones fallback to the `cl100k_base` encoding.
+func encodingForModel(model string) (*tiktoken.Tiktoken, error) {
+	tkm, err := tiktoken.EncodingForModel(model)
+	if err == nil {
+		return tkm, nil
+	}
+
+	tkm, err = tiktoken.GetEncoding(defaultEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("EncodingForModel: %v", err)
+	}
+	return tkm, nil
+}
+
 // ref: https://github.com/pkoukk/tiktoken-go#counting-tokens-for-chat-api-calls
 func numTokensFromMessages(messages []openai.ChatCompletionMessage, model string) (int, error) {
-	tkm, err := tiktoken.EncodingForModel(model)
+	tkm, err := encodingForModel(model)
 	if err != nil {
-		return 0, fmt.Errorf("EncodingForModel: %v", err)
+		return 0, err
 	}
 
 	var tokens_per_message int
@@ -56,3 +73,15 @@ func numTokensFromMessages(messages []openai.ChatCompletionMessage, model string
 
 	return num_tokens, nil
 }
+
+// tokenCounterFor returns a function to count the tokens of text for the model.
+func tokenCounterFor(model string) (func(string) int, error) {
+	tkm, err := encodingForModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(text string) int {
+		return len(tkm.Encode(text, nil, nil))
+	}, nil
+}
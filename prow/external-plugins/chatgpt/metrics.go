@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	modelField = "model"
+	taskField  = "task"
+	orgField   = "org"
+	repoField  = "repo"
+)
+
+var (
+	promptTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "chatgpt_prompt_tokens_total",
+		Help: "Number of prompt tokens sent to the AI server.",
+	}, []string{modelField, taskField})
+	completionTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "chatgpt_completion_tokens_total",
+		Help: "Number of completion tokens responded by the AI server.",
+	}, []string{modelField, taskField})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "chatgpt_request_duration_seconds",
+		Help:    "Latency of the requests to the AI server.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{modelField, taskField})
+	requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "chatgpt_request_errors_total",
+		Help: "Number of the failed requests to the AI server.",
+	}, []string{modelField, taskField})
+	budgetRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "chatgpt_budget_rejections_total",
+		Help: "Number of the requests rejected since budgets exhausted.",
+	}, []string{orgField, repoField})
+)
+
+func init() {
+	prometheus.MustRegister(promptTokens)
+	prometheus.MustRegister(completionTokens)
+	prometheus.MustRegister(requestDuration)
+	prometheus.MustRegister(requestErrors)
+	prometheus.MustRegister(budgetRejections)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// chatForPatch asks the AI server the question about the patch.
+//
+// When the patch is too large for one message, it will be split into parts
+// and sent with the protocol described in splitInstructionMessageText. When
+// the parts are still too large for one conversation, they will be grouped
+// into segments and reviewed separately, so it returns one review for every
+// segment.
+func (s *Server) chatForPatch(logger *logrus.Entry, scope *usageScope, task *Task, prompt *taskPrompt) ([]string, error) {
+	logger.Debugf("user message len: %d", len(prompt.Message))
+
+	backends := s.openaiClientAgent.BackendsFor(task.Backends)
+	if len(backends) == 0 {
+		return nil, errors.New("no backend available")
+	}
+
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: prompt.SystemMessage,
+		},
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: prompt.Message,
+		},
+	}
+
+	// the backend with the largest context is used to split the patch when
+	// the message fits none of the backends.
+	var largest *chatBackendRef
+	var errs []error
+	for _, b := range backends {
+		fits, needTokens, err := b.fits(messages, task.MaxResponseTokens)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to count tokens for backend %s, skip it.", b.name)
+			errs = append(errs, fmt.Errorf("backend %s: %w", b.name, err))
+			continue
+		}
+		if fits {
+			history, err := trimHistory(b, prompt.History, b.contextTokens-task.MaxResponseTokens-needTokens)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := s.chatWithAIServer(logger, scope, backends, withHistory(messages, history), task.MaxResponseTokens)
+			if err != nil {
+				return nil, err
+			}
+			return []string{resp}, nil
+		}
+		if largest == nil || b.contextTokens > largest.contextTokens {
+			largest = b
+		}
+	}
+	if largest == nil {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	countTokens, err := largest.tokenCounter()
+	if err != nil {
+		return nil, err
+	}
+	// tokens taken by the conversation without any parts and history.
+	baseTokens, err := largest.numTokens(multiPartMessages(task, prompt, nil, 1, 2))
+	if err != nil {
+		return nil, err
+	}
+	// the history takes no more than half of the tokens left for the parts.
+	if len(prompt.History) != 0 {
+		history, err := trimHistory(largest, prompt.History, (largest.contextTokens-task.MaxResponseTokens-baseTokens)/2)
+		if err != nil {
+			return nil, err
+		}
+		withTrimmedHistory := *prompt
+		withTrimmedHistory.History = history
+		prompt = &withTrimmedHistory
+		if baseTokens, err = largest.numTokens(multiPartMessages(task, prompt, nil, 1, 2)); err != nil {
+			return nil, err
+		}
+	}
+	available := largest.contextTokens - task.MaxResponseTokens - baseTokens
+	if available <= splitorHoldingTokenCount {
+		return nil, fmt.Errorf("message too large(need tokens: %d)", baseTokens)
+	}
+
+	parts := splitDiff(prompt.Patch, available-splitorHoldingTokenCount, countTokens)
+	segments := groupParts(parts, available, countTokens)
+	logger.Debugf("split the patch into %d parts in %d segments for backend %s", len(parts), len(segments), largest.name)
+
+	var reviews []string
+	for i, segment := range segments {
+		resp, err := s.chatWithAIServer(logger, scope, backends,
+			multiPartMessages(task, prompt, segment, i+1, len(segments)), task.MaxResponseTokens)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, resp)
+	}
+
+	return reviews, nil
+}
+
+// mergeReviews merges the reviews for the segments into one, the reviews will be
+// concatenated when failed to merge them.
+func (s *Server) mergeReviews(logger *logrus.Entry, scope *usageScope, task *Task, reviews []string) string {
+	if len(reviews) == 1 {
+		return reviews[0]
+	}
+
+	var sections []string
+	for i, review := range reviews {
+		sections = append(sections, fmt.Sprintf("### Review for segment %d/%d\n\n%s", i+1, len(reviews), review))
+	}
+	concatenated := strings.Join(sections, "\n\n")
+
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: task.SystemMessage,
+		},
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: strings.Join([]string{mergeReviewsMessageText, concatenated}, "\n\n"),
+		},
+	}
+	merged, err := s.chatWithAIServer(logger, scope, s.openaiClientAgent.BackendsFor(task.Backends), messages, task.MaxResponseTokens)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to merge the reviews, fallback to concatenate them.")
+		return concatenated
+	}
+
+	return merged
+}
+
+// multiPartMessages composes the conversation to send the parts one by one.
+func multiPartMessages(task *Task, prompt *taskPrompt, parts []string, segment, segments int) []openai.ChatCompletionMessage {
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: prompt.SystemMessage,
+		},
+	}
+	messages = append(messages, prompt.History...)
+	messages = append(messages, []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: splitInstructionMessageText,
+		},
+		{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: "OK",
+		},
+	}...)
+
+	total := len(parts)
+	for i, part := range parts {
+		messages = append(messages,
+			openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("[START PART %d/%d]\n```diff\n%s\n```\n[END PART %d/%d]", i+1, total, part, i+1, total),
+			},
+			openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: fmt.Sprintf("Received part %d/%d", i+1, total),
+			},
+		)
+	}
+
+	lastMessage := []string{
+		"ALL PARTS SENT",
+		prompt.Question,
+		task.PatchIntroducePrompt,
+		"It is the content of all the parts I sent.",
+	}
+	if segments > 1 {
+		lastMessage = append(lastMessage, fmt.Sprintf(
+			"Note: it is only the segment %d/%d of the diff, the other segments are reviewed separately.", segment, segments))
+	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: strings.Join(lastMessage, "\n"),
+	})
+
+	return messages
+}
+
+// groupParts groups the adjacent parts into segments, the parts of each segment
+// take no more than maxTokens tokens including the holding tokens of every part.
+func groupParts(parts []string, maxTokens int, countTokens func(string) int) [][]string {
+	var segments [][]string
+	var current []string
+	currentTokens := 0
+	for _, part := range parts {
+		partTokens := countTokens(part) + splitorHoldingTokenCount
+		if len(current) > 0 && currentTokens+partTokens > maxTokens {
+			segments = append(segments, current)
+			current, currentTokens = nil, 0
+		}
+		current = append(current, part)
+		currentTokens += partTokens
+	}
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+
+	return segments
+}
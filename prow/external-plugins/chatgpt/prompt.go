@@ -0,0 +1,246 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/sashabaranov/go-openai"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+var promptTemplateFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+}
+
+// PromptContext is the data to render the prompt templates of the tasks.
+type PromptContext struct {
+	Org              string
+	Repo             string
+	Number           int
+	Title            string
+	Body             string
+	Author           string
+	BaseRef          string
+	BaseSHA          string
+	HeadRef          string
+	HeadSHA          string
+	Labels           []string
+	Files            []PromptFile
+	Diff             string
+	IncrementalFrom  string // the last reviewed SHA when the diff is the incremental diff since it.
+	Commenter        string // the user who triggered the task by comment, empty when triggered by pull request events.
+	ExternalContexts []PromptExternalContext
+}
+
+// PromptFile is a changed file of the pull request.
+type PromptFile struct {
+	Name string
+	Diff string
+}
+
+// PromptExternalContext is a fetched external context.
+type PromptExternalContext struct {
+	ResURL  string
+	Content string
+	Prompt  string // the content rendered with the prompt_tpl of the external context.
+}
+
+func newPromptContext(pr *github.PullRequest, comment *github.IssueComment, diff string, externalContexts []PromptExternalContext) *PromptContext {
+	ctx := &PromptContext{
+		Org:              pr.Base.Repo.Owner.Login,
+		Repo:             pr.Base.Repo.Name,
+		Number:           pr.Number,
+		Title:            pr.Title,
+		Body:             pr.Body,
+		Author:           pr.User.Login,
+		BaseRef:          pr.Base.Ref,
+		BaseSHA:          pr.Base.SHA,
+		HeadRef:          pr.Head.Ref,
+		HeadSHA:          pr.Head.SHA,
+		Diff:             diff,
+		ExternalContexts: externalContexts,
+	}
+	for _, label := range pr.Labels {
+		ctx.Labels = append(ctx.Labels, label.Name)
+	}
+	for _, fileDiff := range splitByLinePrefix(diff, diffFileHeaderPrefix) {
+		if name := diffFileName(fileDiff); name != "" {
+			ctx.Files = append(ctx.Files, PromptFile{Name: name, Diff: fileDiff})
+		}
+	}
+	if comment != nil {
+		ctx.Commenter = comment.User.Login
+	}
+
+	return ctx
+}
+
+// withoutDiff returns a copy of the context without any diff contents.
+func (ctx *PromptContext) withoutDiff() *PromptContext {
+	ret := *ctx
+	ret.Diff = ""
+	ret.Files = make([]PromptFile, 0, len(ctx.Files))
+	for _, f := range ctx.Files {
+		ret.Files = append(ret.Files, PromptFile{Name: f.Name})
+	}
+
+	return &ret
+}
+
+// externalContextsPrompt returns the prompt composed by all the external contexts.
+func (ctx *PromptContext) externalContextsPrompt() string {
+	if len(ctx.ExternalContexts) == 0 {
+		return ""
+	}
+
+	prompts := []string{defaultExternalContextPrompt}
+	for _, ec := range ctx.ExternalContexts {
+		prompts = append(prompts, ec.Prompt)
+	}
+
+	return strings.Join(prompts, "\n")
+}
+
+// diffFileName returns the file name in the new version from the diff of a file.
+func diffFileName(fileDiff string) string {
+	header, _, _ := strings.Cut(fileDiff, "\n")
+	if !strings.HasPrefix(header, diffFileHeaderPrefix) {
+		return ""
+	}
+
+	_, name, found := strings.Cut(header, " b/")
+	if !found {
+		return ""
+	}
+
+	return name
+}
+
+// taskPrompt is the composed prompt of a task.
+type taskPrompt struct {
+	SystemMessage string
+	Message       string // the whole user message including the patch.
+	Question      string // the user message without the patch, used when the patch is sent in multiple parts.
+	Patch         string
+	History       []openai.ChatCompletionMessage // the earlier conversation for follow-up questions.
+}
+
+// composePrompt renders the prompt templates of the task with the context.
+func (t *Task) composePrompt(ctx *PromptContext) (*taskPrompt, error) {
+	systemMessage, err := renderTemplate(t.systemMessageTpl, t.SystemMessage, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.messageTpl != nil {
+		message, err := renderTemplate(t.messageTpl, t.MessageTpl, ctx)
+		if err != nil {
+			return nil, err
+		}
+		question, err := renderTemplate(t.messageTpl, t.MessageTpl, ctx.withoutDiff())
+		if err != nil {
+			return nil, err
+		}
+
+		return &taskPrompt{SystemMessage: systemMessage, Message: message, Question: question, Patch: ctx.Diff}, nil
+	}
+
+	userPrompt, err := renderTemplate(t.userPromptTpl, t.UserPrompt, ctx)
+	if err != nil {
+		return nil, err
+	}
+	question := strings.Join([]string{
+		userPrompt,
+		ctx.externalContextsPrompt(),
+		"This is the pr title:",
+		"```text",
+		ctx.Title,
+		"```",
+		"This is the pr description:",
+		"```text",
+		ctx.Body,
+		"```",
+	}, "\n")
+	patchIntroducePrompt := t.PatchIntroducePrompt
+	if ctx.IncrementalFrom != "" {
+		patchIntroducePrompt = strings.Join([]string{
+			patchIntroducePrompt,
+			fmt.Sprintf("It is the incremental diff since the last reviewed commit %s.", ctx.IncrementalFrom),
+		}, "\n")
+	}
+	message := strings.Join([]string{
+		question,
+		patchIntroducePrompt,
+		"```diff",
+		ctx.Diff,
+		"```",
+	}, "\n")
+
+	return &taskPrompt{SystemMessage: systemMessage, Message: message, Question: question, Patch: ctx.Diff}, nil
+}
+
+// initTemplates parses the templates of the task, and validates them by
+// rendering with an empty context.
+func (t *Task) initTemplates() error {
+	for _, item := range []struct {
+		name string
+		text string
+		tpl  **template.Template
+	}{
+		{name: "system_message", text: t.SystemMessage, tpl: &t.systemMessageTpl},
+		{name: "user_prompt", text: t.UserPrompt, tpl: &t.userPromptTpl},
+		{name: "message_tpl", text: t.MessageTpl, tpl: &t.messageTpl},
+	} {
+		*item.tpl = nil
+		if item.text == "" {
+			continue
+		}
+
+		tpl, err := template.New(item.name).Funcs(promptTemplateFuncs).Option("missingkey=error").Parse(item.text)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s template: %w", item.name, err)
+		}
+		if err := tpl.Execute(io.Discard, &PromptContext{}); err != nil {
+			return fmt.Errorf("failed to render %s template: %w", item.name, err)
+		}
+		*item.tpl = tpl
+	}
+
+	return nil
+}
+
+// renderTemplate renders the template with the data, the raw text will be
+// returned when the template is not initialized.
+func renderTemplate(tpl *template.Template, text string, data any) (string, error) {
+	if tpl == nil {
+		return text, nil
+	}
+
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", tpl.Name(), err)
+	}
+
+	return sb.String(), nil
+}
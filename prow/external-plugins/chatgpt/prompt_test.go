@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+func Test_newPromptContext(t *testing.T) {
+	diff := testFileHeaderA + testHunkA1 + testFileHeaderB + testHunkB1
+	pr := &github.PullRequest{
+		Number: 1,
+		Title:  "title",
+		User:   github.User{Login: "author"},
+		Labels: []github.Label{{Name: "lgtm"}},
+		Base: github.PullRequestBranch{
+			Ref:  "master",
+			Repo: github.Repo{Name: "repo", Owner: github.User{Login: "org"}},
+		},
+	}
+	comment := &github.IssueComment{User: github.User{Login: "commenter"}}
+
+	got := newPromptContext(pr, comment, diff, nil)
+	want := &PromptContext{
+		Org:     "org",
+		Repo:    "repo",
+		Number:  1,
+		Title:   "title",
+		Author:  "author",
+		BaseRef: "master",
+		Labels:  []string{"lgtm"},
+		Files: []PromptFile{
+			{Name: "a.go", Diff: testFileHeaderA + testHunkA1},
+			{Name: "b.go", Diff: testFileHeaderB + testHunkB1},
+		},
+		Diff:      diff,
+		Commenter: "commenter",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("newPromptContext() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTask_composePrompt(t *testing.T) {
+	ctx := &PromptContext{
+		Org:    "org",
+		Repo:   "repo",
+		Number: 1,
+		Title:  "title",
+		Labels: []string{"lgtm", "approved"},
+		Files:  []PromptFile{{Name: "a.go", Diff: "diff a.go"}},
+		Diff:   "diff a.go",
+	}
+
+	tests := []struct {
+		name    string
+		task    *Task
+		want    *taskPrompt
+		wantErr bool
+	}{
+		{
+			name: "raw text without templates",
+			task: &Task{
+				SystemMessage:        "system",
+				UserPrompt:           "prompt",
+				PatchIntroducePrompt: "diff:",
+			},
+			want: &taskPrompt{
+				SystemMessage: "system",
+				Question:      "prompt\n\nThis is the pr title:\n```text\ntitle\n```\nThis is the pr description:\n```text\n\n```",
+				Message:       "prompt\n\nThis is the pr title:\n```text\ntitle\n```\nThis is the pr description:\n```text\n\n```\ndiff:\n```diff\ndiff a.go\n```",
+				Patch:         "diff a.go",
+			},
+		},
+		{
+			name: "whole message template",
+			task: &Task{
+				SystemMessage: "review for {{ .Org }}/{{ .Repo }}",
+				MessageTpl:    `PR #{{ .Number }} labels: {{ join .Labels "," }}{{ range .Files }} {{ .Name }}:[{{ .Diff }}]{{ end }}`,
+			},
+			want: &taskPrompt{
+				SystemMessage: "review for org/repo",
+				Question:      "PR #1 labels: lgtm,approved a.go:[]",
+				Message:       "PR #1 labels: lgtm,approved a.go:[diff a.go]",
+				Patch:         "diff a.go",
+			},
+		},
+		{
+			name: "invalid template field",
+			task: &Task{
+				MessageTpl: "{{ .NotExisted }}",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.task.initTemplates()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("initTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := tt.task.composePrompt(ctx)
+			if err != nil {
+				t.Fatalf("composePrompt() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("composePrompt() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
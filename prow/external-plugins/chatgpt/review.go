@@ -0,0 +1,203 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+const reviewFindingsInstruction = `Please answer in JSON format only without any other text, the JSON schema is:
+{
+  "summary": "<the overall review in markdown format>",
+  "findings": [
+    {
+      "file": "<the file path>",
+      "line": <the line number in the new version of the file>,
+      "severity": "<one of: critical, major, minor, info>",
+      "comment": "<the problem description in markdown format>",
+      "suggestion": "<optional, the code to replace the line>"
+    }
+  ]
+}`
+
+// reviewResult is the structured review answered by the AI server.
+type reviewResult struct {
+	Summary  string          `json:"summary"`
+	Findings []reviewFinding `json:"findings"`
+}
+
+// reviewFinding is a problem found on a line of the pull request.
+type reviewFinding struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Severity   string `json:"severity"`
+	Comment    string `json:"comment"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func (f *reviewFinding) body() string {
+	body := fmt.Sprintf("**[%s]** %s", f.Severity, f.Comment)
+	if f.Suggestion != "" {
+		body += fmt.Sprintf("\n```suggestion\n%s\n```", strings.TrimSuffix(f.Suggestion, "\n"))
+	}
+
+	return body
+}
+
+// parseReviewResult parses the structured review from the response, the JSON
+// content may be wrapped by markdown code fences.
+func parseReviewResult(resp string) (*reviewResult, error) {
+	start := strings.Index(resp, "{")
+	end := strings.LastIndex(resp, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("no JSON object found in the response")
+	}
+
+	var ret reviewResult
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the review result: %w", err)
+	}
+
+	return &ret, nil
+}
+
+// createReview posts the findings as a pull request review with line comments,
+// the findings on lines outside the diff will be listed in the review body.
+//
+// On pull request events, the marker of the task is put in the review body and
+// the previous comment and review of the task are outdated.
+func (s *Server) createReview(logger *logrus.Entry, scope *usageScope, task *Task, pr *github.PullRequest, comment *github.IssueComment, taskName string, previous, previousReview *taskComment, reviews []string) error {
+	org := pr.Base.Repo.Owner.Login
+	repo := pr.Base.Repo.Name
+	num := pr.Number
+
+	var summaries []string
+	var findings []reviewFinding
+	for _, review := range reviews {
+		ret, err := parseReviewResult(review)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to parse the structured review, fallback to comment.")
+			resp := s.formatResponse(task, s.mergeReviews(logger, scope, task, reviews))
+			if comment != nil {
+				return s.createComment(logger, org, repo, num, comment, resp)
+			}
+			if err := s.upsertTaskComment(logger, pr, taskName, previous, resp); err != nil {
+				return err
+			}
+			s.outdateTaskReviews(logger, pr, nil, previousReview)
+			return nil
+		}
+		if ret.Summary != "" {
+			summaries = append(summaries, ret.Summary)
+		}
+		findings = append(findings, ret.Findings...)
+	}
+
+	changes, err := s.ghc.GetPullRequestChanges(org, repo, num)
+	if err != nil {
+		return err
+	}
+	positions := make(map[string]map[int]int)
+	for _, change := range changes {
+		positions[change.Filename] = diffLinePositions(change.Patch)
+	}
+
+	var lineComments []github.DraftReviewComment
+	var outsideFindings []string
+	for i := range findings {
+		f := &findings[i]
+		if position, ok := positions[f.File][f.Line]; ok {
+			lineComments = append(lineComments, github.DraftReviewComment{
+				Path:     f.File,
+				Position: position,
+				Body:     f.body(),
+			})
+			continue
+		}
+		outsideFindings = append(outsideFindings, fmt.Sprintf("- `%s#L%d`: %s", f.File, f.Line, f.body()))
+	}
+
+	body := strings.Join(summaries, "\n\n")
+	if len(outsideFindings) != 0 {
+		body = strings.Join(append([]string{body, "", "Findings outside the diff:"}, outsideFindings...), "\n")
+	}
+	body = s.formatResponse(task, body)
+	if comment != nil {
+		body = plugins.FormatICResponse(*comment, "\n"+body)
+	} else {
+		body = taskCommentMarker(taskName, pr.Head.SHA) + body
+	}
+
+	if err := s.ghc.CreateReview(org, repo, num, github.DraftReview{
+		CommitSHA: pr.Head.SHA,
+		Body:      body,
+		Action:    github.Comment,
+		Comments:  lineComments,
+	}); err != nil {
+		logger.WithError(err).Warn("failed to create review")
+		return err
+	}
+
+	logger.Debugf("Created review with %d line comments", len(lineComments))
+	// outdate the previous ones after the review is created, so that the
+	// reviewed head is not lost when creating the review failed.
+	if comment == nil {
+		s.outdateTaskReviews(logger, pr, previous, previousReview)
+	}
+	return nil
+}
+
+// Matches the hunk line in unified diffs, such as `@@ -l,s +l,s @@ section head`.
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// diffLinePositions returns the positions in the patch of the lines in the new
+// version of the file, it's a map from line to patch position.
+//
+// The patch is the one from GitHub pull request file changes, the position is
+// the number of lines down from the first hunk header.
+// Ref: https://docs.github.com/en/rest/pulls/comments#create-a-review-comment-for-a-pull-request
+func diffLinePositions(patch string) map[int]int {
+	positions := make(map[int]int)
+	newLine := 0
+	for position, line := range strings.Split(patch, "\n") {
+		if matches := hunkHeaderRe.FindStringSubmatch(line); matches != nil {
+			newLine, _ = strconv.Atoi(matches[1])
+			continue
+		}
+		if line == "" || newLine == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case '+', ' ':
+			positions[newLine] = position
+			newLine++
+		}
+	}
+
+	return positions
+}
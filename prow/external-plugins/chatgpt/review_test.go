@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseReviewResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    *reviewResult
+		wantErr bool
+	}{
+		{
+			name: "plain JSON",
+			resp: `{"summary": "LGTM", "findings": []}`,
+			want: &reviewResult{Summary: "LGTM", Findings: []reviewFinding{}},
+		},
+		{
+			name: "JSON in code fences",
+			resp: "```json\n" + `{"summary": "some problems", "findings": [{"file": "a.go", "line": 3, "severity": "minor", "comment": "typo", "suggestion": "fixed"}]}` + "\n```",
+			want: &reviewResult{
+				Summary: "some problems",
+				Findings: []reviewFinding{
+					{File: "a.go", Line: 3, Severity: "minor", Comment: "typo", Suggestion: "fixed"},
+				},
+			},
+		},
+		{
+			name:    "not JSON",
+			resp:    "LGTM",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReviewResult(tt.resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseReviewResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseReviewResult() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_diffLinePositions(t *testing.T) {
+	patch := "@@ -1,3 +1,4 @@\n" +
+		" line1\n" +
+		"-line2\n" +
+		"+line2 changed\n" +
+		"+line2 added\n" +
+		" line3\n" +
+		"@@ -10,1 +11,1 @@\n" +
+		"-line10\n" +
+		"+line11"
+
+	want := map[int]int{
+		1:  1,
+		2:  3,
+		3:  4,
+		4:  5,
+		11: 8,
+	}
+	if diff := cmp.Diff(want, diffLinePositions(patch)); diff != "" {
+		t.Errorf("diffLinePositions() mismatch (-want +got):\n%s", diff)
+	}
+}
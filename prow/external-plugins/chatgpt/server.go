@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -28,8 +29,10 @@ import (
 	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/slices"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/gitattributes"
 	"k8s.io/test-infra/prow/github"
 	"k8s.io/test-infra/prow/pluginhelp"
 	"k8s.io/test-infra/prow/plugins"
@@ -39,7 +42,7 @@ const (
 	pluginName                  = "chatgpt"
 	gitHostBaseURL              = "https://github.com"
 	defaultIssueReviewWorld     = "default"
-	splitorHoldingByteCount     = 200
+	splitorHoldingTokenCount    = 50
 	splitInstructionMessageText = `The total length of the content that I want to send you is too large to send in only one piece.
 
 For sending you that content, I will follow this rule:
@@ -52,22 +55,33 @@ Then you just answer: "Received part 1/10"
 
 And when I tell you "ALL PARTS SENT", then you can continue processing the data and answering my requests.
 `
+	mergeReviewsMessageText = `The diff of the pull request is too large, so it was split into several segments and reviewed separately.
+Here are the reviews of all the segments, please merge them into one review and remove the duplicated contents:`
 )
 
 type githubClient interface {
 	AddLabel(org, repo string, number int, label string) error
 	AssignIssue(org, repo string, number int, logins []string) error
+	BotUserChecker() (func(candidate string) bool, error)
 	CreateComment(org, repo string, number int, comment string) error
 	CreateFork(org, repo string) (string, error)
+	CreateReview(org, repo string, number int, r github.DraftReview) error
+	EditComment(org, repo string, id int, comment string) error
 	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error)
 	CreateIssue(org, repo, title, body string, milestone int, labels, assignees []string) (int, error)
 	EnsureFork(forkingUser, org, repo string) (string, error)
 	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
 	GetPullRequestDiff(org, repo string, number int) ([]byte, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetCompareDiff(org, repo, base, head string) ([]byte, error)
 	GetPullRequests(org, repo string) ([]github.PullRequest, error)
+	GetFile(org, repo, filepath, commit string) ([]byte, error)
 	GetRepo(owner, name string) (github.FullRepo, error)
 	IsMember(org, user string) (bool, error)
 	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	ListPullRequestComments(org, repo string, number int) ([]github.ReviewComment, error)
+	ListReviews(org, repo string, number int) ([]github.Review, error)
+	UpdateReview(org, repo string, number, reviewID int, body string) error
 	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
 	ListOrgMembers(org, role string) ([]github.TeamMember, error)
 }
@@ -100,6 +114,8 @@ type Server struct {
 
 	openaiClientAgent *OpenaiWrapAgent
 	openaiTaskAgent   *TaskAgent
+	contextFetcher    *externalContextFetcher
+	budgetTracker     *budgetTracker
 
 	issueCommentMatchRegex *regexp.Regexp
 	maxDiffSize            int
@@ -229,7 +245,7 @@ func (s *Server) handle(logger *logrus.Entry, pr *github.PullRequest, comment *g
 	if err != nil {
 		return err
 	}
-	if len(diff) > s.maxDiffSize {
+	if s.maxDiffSize > 0 && len(diff) > s.maxDiffSize {
 		skipMessage := fmt.Sprintf("I Skip it since the diff size(%d bytes > %d bytes) is too large", len(diff), s.maxDiffSize)
 		logger.Debug(skipMessage)
 		return s.createComment(logger, org, repo, num, comment, skipMessage)
@@ -241,8 +257,16 @@ func (s *Server) handle(logger *logrus.Entry, pr *github.PullRequest, comment *g
 		return err
 	}
 
+	// read the .gitattributes at the base SHA, so the pull request can not
+	// exclude its own changes from reviewing.
+	ga, err := gitattributes.NewGroup(func() ([]byte, error) { return s.ghc.GetFile(org, repo, ".gitattributes", pr.Base.SHA) })
+	if err != nil {
+		logger.WithError(err).Warn("Failed to load .gitattributes, no files will be treated as generated.")
+		ga = &gitattributes.Group{}
+	}
+
 	for n, task := range tasks {
-		if err := s.taskRun(logger.WithField("ai-task", n), task, pr, string(diff), comment); err != nil {
+		if err := s.taskRun(logger.WithField("ai-task", n), n, task, pr, string(diff), comment, ga.IsLinguistGenerated); err != nil {
 			return err
 		}
 	}
@@ -291,78 +315,259 @@ func (s *Server) getPullRequestDiff(l *logrus.Entry, org, repo string, num int)
 	return diff, nil
 }
 
-func (s *Server) taskRun(logger *logrus.Entry, task *Task, pr *github.PullRequest, patch string, comment *github.IssueComment) error {
+func (s *Server) taskRun(logger *logrus.Entry, taskName string, task *Task, pr *github.PullRequest, patch string, comment *github.IssueComment, isGenerated func(string) bool) error {
 	// when triggered by pull request update or open events.
 	if comment == nil && !shouldRunTaskForPR(task, pr) {
 		return nil
 	}
 
+	// the comments posted on pull request events will be updated in place, the
+	// reviews will be outdated by the newer ones, and the head reviewed by the
+	// previous comment or review will not be reviewed again.
+	var previous, previousReview *taskComment
+	incrementalFrom := ""
+	if comment == nil {
+		var err error
+		previous, err = s.findTaskComment(pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, taskName)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to find the previous comment of the task.")
+		}
+		reviewed := previous
+		// the review mode falls back to comments, only the latest comment or
+		// review of the task keeps its marker.
+		if task.OutputMode == TaskOutputModeReview {
+			previousReview, err = s.findTaskReview(pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, taskName)
+			if err != nil {
+				logger.WithError(err).Warn("Failed to find the previous review of the task.")
+			}
+			if previousReview != nil {
+				reviewed = previousReview
+			}
+		}
+		if reviewed != nil {
+			if reviewed.SHA == pr.Head.SHA {
+				logger.Debugf("Skip since the head %s has been reviewed.", pr.Head.SHA)
+				return nil
+			}
+			if incremental, ok := s.incrementalPatch(logger, pr, reviewed.SHA, patch); ok {
+				patch, incrementalFrom = incremental, reviewed.SHA
+			}
+		}
+	}
+
+	filter := &fileFilter{includePaths: task.IncludePaths, excludePaths: task.ExcludePaths, isGenerated: isGenerated}
+	patch, excluded := filter.filterDiff(patch)
+	if len(excluded) != 0 {
+		logger.Debugf("Excluded files: %v", excluded)
+	}
+	if strings.TrimSpace(patch) == "" {
+		logger.Debug("Skip since all the changed files are excluded.")
+		if comment != nil {
+			return s.createComment(logger, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, comment,
+				"I Skip it since all the changed files are excluded.")
+		}
+		return nil
+	}
+
 	logger.Debugf("start deal task %s...", task.Description)
-	message := strings.Join([]string{
-		task.UserPrompt,
-		"This is the pr title:",
-		"```text",
-		pr.Title,
-
-		"```",
-		"This is the pr description:",
-		"```text",
-		pr.Body,
-		"```",
-		task.PatchIntroducePrompt,
-		"```diff",
-		patch,
-		"```",
-	}, "\n")
-
-	resp, err := s.chatWithAIServer(logger, task.SystemMessage, message, task.MaxResponseTokens)
-	if err != nil {
-		logger.Errorf("Failed to send message to OpenAI server: %v", err)
-		return s.createComment(logger, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, comment,
-			"Sorry, some error happened!")
+	scope := &usageScope{
+		Org:  pr.Base.Repo.Owner.Login,
+		Repo: pr.Base.Repo.Name,
+		User: pr.User.Login,
+		Task: taskName,
+	}
+	if comment != nil {
+		scope.User = comment.User.Login
 	}
 
-	if task.OutputStaticHeadNote != "" {
-		resp = fmt.Sprintf("%s\n%s", task.OutputStaticHeadNote, resp)
+	// the patch of every file is reviewed separately in per file mode.
+	units := []string{patch}
+	if task.PerFile {
+		units = splitByLinePrefix(patch, diffFileHeaderPrefix)
+	}
+
+	externalContexts := s.fetchExternalContexts(logger, task, pr)
+	// follow-up questions keep the context of the earlier conversation.
+	var history []openai.ChatCompletionMessage
+	if comment != nil {
+		var err error
+		history, err = s.conversationHistory(pr, comment)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to rebuild the conversation history, ask without it.")
+		}
+	}
+	var reviews, sections []string
+	for _, unit := range units {
+		promptCtx := newPromptContext(pr, comment, unit, externalContexts)
+		promptCtx.IncrementalFrom = incrementalFrom
+		prompt, err := task.composePrompt(promptCtx)
+		if err != nil {
+			logger.WithError(err).Error("Failed to compose the prompt")
+			return s.createComment(logger, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, comment,
+				"Sorry, some error happened!")
+		}
+		if task.OutputMode == TaskOutputModeReview {
+			prompt.Message = strings.Join([]string{prompt.Message, reviewFindingsInstruction}, "\n")
+			prompt.Question = strings.Join([]string{prompt.Question, reviewFindingsInstruction}, "\n")
+		}
+		prompt.History = history
+
+		unitReviews, err := s.chatForPatch(logger, scope, task, prompt)
+		if err != nil {
+			var budgetErr *budgetExceededError
+			if errors.As(err, &budgetErr) {
+				logger.WithError(err).Info("Skip since the budget is exhausted.")
+				notice := fmt.Sprintf("Sorry, I skip it since %s, please try again later.", budgetErr)
+				if comment != nil {
+					return s.createComment(logger, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, comment, notice)
+				}
+				// the notice on pull request events is put in the comment of the
+				// task, the reviews of review mode tasks can't hold it.
+				if task.OutputMode == TaskOutputModeReview {
+					return nil
+				}
+				return s.upsertTaskNotice(logger, pr, taskName, previous, notice)
+			}
+
+			logger.Errorf("Failed to send message to OpenAI server: %v", err)
+			return s.createComment(logger, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, comment,
+				"Sorry, some error happened!")
+		}
+
+		if task.OutputMode == TaskOutputModeReview {
+			reviews = append(reviews, unitReviews...)
+			continue
+		}
+
+		section := s.mergeReviews(logger, scope, task, unitReviews)
+		if task.PerFile {
+			section = fmt.Sprintf("#### `%s`\n\n%s", diffFileName(unit), section)
+		}
+		sections = append(sections, section)
+	}
+
+	if task.OutputMode == TaskOutputModeReview {
+		return s.createReview(logger, scope, task, pr, comment, taskName, previous, previousReview, reviews)
+	}
+
+	resp := s.formatResponse(task, strings.Join(sections, "\n\n"))
+	if comment == nil {
+		return s.upsertTaskComment(logger, pr, taskName, previous, resp)
 	}
 	return s.createComment(logger, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Number, comment, resp)
 }
 
-func (s *Server) chatWithAIServer(logger *logrus.Entry, systemMessage string, message string, maxResponseTokens int) (string, error) {
-	msgLen := len(message)
-	logger.Debugf("user message len: %d", msgLen)
+// formatResponse adds the static head note of the task to the response.
+func (s *Server) formatResponse(task *Task, resp string) string {
+	if task.OutputStaticHeadNote != "" {
+		return fmt.Sprintf("%s\n%s", task.OutputStaticHeadNote, resp)
+	}
+
+	return resp
+}
 
-	openaiClient, model := s.openaiClientAgent.ClientFor(msgLen)
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: systemMessage,
-		},
-		{
-			Role:    openai.ChatMessageRoleUser,
-			Content: message,
-		},
+// fetchExternalContexts fetches the external contexts of the task and renders them.
+//
+// the contexts failed to fetch will be skipped.
+func (s *Server) fetchExternalContexts(logger *logrus.Entry, task *Task, pr *github.PullRequest) []PromptExternalContext {
+	if len(task.ExternalContexts) == 0 || s.contextFetcher == nil {
+		return nil
 	}
 
-	needTokens, err := numTokensFromMessages(messages, model)
-	if err != nil {
-		logger.Error(err)
-		return "", err
+	var ret []PromptExternalContext
+	for _, ec := range task.ExternalContexts {
+		content, err := ec.Content(s.contextFetcher, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Base.SHA)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to fetch external context %s, skip it.", ec.ResURL)
+			continue
+		}
+		ret = append(ret, PromptExternalContext{
+			ResURL:  ec.ResURL,
+			Content: string(content),
+			Prompt:  ec.Prompt(content),
+		})
 	}
-	logger.Debugf("need tokens: %d", needTokens)
-	for needTokens+maxResponseTokens >= maxTokens[model] {
-		return "", fmt.Errorf("message too large(need tokens: %d)", needTokens)
+
+	return ret
+}
+
+// chatWithAIServer sends the messages to the first backend whose context fits
+// them, the next backends are tried in order when it fails or is rate limited.
+//
+// The request rejected by budgets will not fallback.
+func (s *Server) chatWithAIServer(logger *logrus.Entry, scope *usageScope, backends []*chatBackendRef, messages []openai.ChatCompletionMessage, maxResponseTokens int) (string, error) {
+	var errs []error
+	for _, backend := range backends {
+		fits, needTokens, err := backend.fits(messages, maxResponseTokens)
+		if err != nil {
+			logger.Error(err)
+			errs = append(errs, fmt.Errorf("backend %s: %w", backend.name, err))
+			continue
+		}
+		logger.Debugf("need tokens: %d", needTokens)
+		if !fits {
+			errs = append(errs, fmt.Errorf("backend %s: message too large(need tokens: %d)", backend.name, needTokens))
+			continue
+		}
+
+		resp, err := s.chatWithBackend(logger, scope, backend, messages, needTokens, maxResponseTokens)
+		if err == nil {
+			return resp, nil
+		}
+
+		var budgetErr *budgetExceededError
+		if errors.As(err, &budgetErr) {
+			return "", err
+		}
+		if isRateLimited(err) {
+			logger.Warnf("Backend %s is rate limited, try the next one.", backend.name)
+		} else {
+			logger.WithError(err).Warnf("Failed to chat with backend %s, try the next one.", backend.name)
+		}
+		errs = append(errs, fmt.Errorf("backend %s: %w", backend.name, err))
+	}
+
+	if len(errs) == 0 {
+		return "", errors.New("no backend available")
 	}
+	return "", utilerrors.NewAggregate(errs)
+}
+
+func (s *Server) chatWithBackend(logger *logrus.Entry, scope *usageScope, backend *chatBackendRef, messages []openai.ChatCompletionMessage, needTokens, maxResponseTokens int) (string, error) {
+	model := backend.model
 
-	resp, err := openaiClient.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+	var usage *usageRecord
+	if s.budgetTracker != nil {
+		var err error
+		usage, err = s.budgetTracker.reserve(scope, needTokens+maxResponseTokens)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	start := time.Now()
+	resp, err := backend.client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
 		Model:       model,
 		MaxTokens:   maxResponseTokens,
 		Temperature: defaultTemperature,
 		Messages:    messages,
 	})
+	requestDuration.WithLabelValues(model, scope.Task).Observe(time.Since(start).Seconds())
 	if err != nil {
+		requestErrors.WithLabelValues(model, scope.Task).Inc()
+		if usage != nil {
+			s.budgetTracker.settle(usage, 0)
+		}
 		return "", fmt.Errorf("ChatCompletion error: %w", err)
 	}
+	promptTokens.WithLabelValues(model, scope.Task).Add(float64(resp.Usage.PromptTokens))
+	completionTokens.WithLabelValues(model, scope.Task).Add(float64(resp.Usage.CompletionTokens))
+	if usage != nil {
+		s.budgetTracker.settle(usage, resp.Usage.TotalTokens)
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("ChatCompletion error: no choices responded")
+	}
 
 	result := resp.Choices[0].Message.Content
 	if isTruncated := resp.Choices[0].FinishReason == "length"; isTruncated {
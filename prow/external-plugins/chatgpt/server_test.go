@@ -18,11 +18,60 @@ package main
 
 import (
 	_ "embed"
+	"fmt"
 	"testing"
 
 	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/github/fakegithub"
 )
 
+// fakeGitHubClient implements the methods that fakegithub.FakeClient missed.
+type fakeGitHubClient struct {
+	*fakegithub.FakeClient
+	diffs        map[int][]byte
+	compareDiffs map[string][]byte
+}
+
+func newFakeGitHubClient() *fakeGitHubClient {
+	return &fakeGitHubClient{
+		FakeClient:   fakegithub.NewFakeClient(),
+		diffs:        make(map[int][]byte),
+		compareDiffs: make(map[string][]byte),
+	}
+}
+
+func (f *fakeGitHubClient) CreateFork(org, repo string) (string, error) {
+	return repo, nil
+}
+
+func (f *fakeGitHubClient) EnsureFork(forkingUser, org, repo string) (string, error) {
+	return repo, nil
+}
+
+func (f *fakeGitHubClient) GetPullRequests(org, repo string) ([]github.PullRequest, error) {
+	var ret []github.PullRequest
+	for _, pr := range f.PullRequests {
+		ret = append(ret, *pr)
+	}
+	return ret, nil
+}
+
+func (f *fakeGitHubClient) GetPullRequestDiff(org, repo string, number int) ([]byte, error) {
+	return f.diffs[number], nil
+}
+
+func (f *fakeGitHubClient) GetCompareDiff(org, repo, base, head string) ([]byte, error) {
+	diff, ok := f.compareDiffs[base+"..."+head]
+	if !ok {
+		return nil, fmt.Errorf("commit %s not found", base)
+	}
+	return diff, nil
+}
+
+func (f *fakeGitHubClient) ListOrgMembers(org, role string) ([]github.TeamMember, error) {
+	return nil, nil
+}
+
 func Test_shouldRunTaskForPR(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,234 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+const (
+	previousReviewSummaryPrefix = "<details><summary>Previous review for "
+	outdatedReviewSummaryPrefix = "<details><summary>Outdated review for "
+	taskNoticePrefix            = "> **Note**: "
+
+	// maxCommentBodySize is the maximum size of the comment bodies accepted by GitHub.
+	maxCommentBodySize = 65536
+)
+
+// taskCommentMarkerRe matches the hidden marker in the comments and reviews
+// posted by tasks on pull request events, it records the task name and the
+// reviewed head SHA.
+var taskCommentMarkerRe = regexp.MustCompile(`<!-- chatgpt-task: (\S+) sha: (\w+) -->\n?`)
+
+func taskCommentMarker(taskName, sha string) string {
+	return fmt.Sprintf("<!-- chatgpt-task: %s sha: %s -->\n", taskName, sha)
+}
+
+// taskComment is the comment or review posted by a task on pull request events.
+type taskComment struct {
+	ID      int
+	SHA     string // the head SHA reviewed by the comment.
+	Review  string // the latest review in the comment, without the marker and previous reviews.
+	History string // the collapsed previous reviews in the comment, newest first.
+}
+
+// findTaskComment finds the latest comment posted by the bot for the task.
+func (s *Server) findTaskComment(org, repo string, num int, taskName string) (*taskComment, error) {
+	botUserChecker, err := s.ghc.BotUserChecker()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.ghc.ListIssueComments(org, repo, num)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if !botUserChecker(c.User.Login) {
+			continue
+		}
+
+		matches := taskCommentMarkerRe.FindStringSubmatch(c.Body)
+		if matches == nil || matches[1] != taskName {
+			continue
+		}
+
+		review := taskCommentMarkerRe.ReplaceAllString(c.Body, "")
+		// the notice on top of the comment is not a part of the reviews.
+		if strings.HasPrefix(review, taskNoticePrefix) {
+			_, review, _ = strings.Cut(review, "\n\n")
+		}
+		review, history, found := strings.Cut(review, "\n\n"+previousReviewSummaryPrefix)
+		if found {
+			history = previousReviewSummaryPrefix + history
+		}
+		return &taskComment{ID: c.ID, SHA: matches[2], Review: review, History: history}, nil
+	}
+
+	return nil, nil
+}
+
+// findTaskReview finds the latest review posted by the bot for the task.
+func (s *Server) findTaskReview(org, repo string, num int, taskName string) (*taskComment, error) {
+	botUserChecker, err := s.ghc.BotUserChecker()
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.ghc.ListReviews(org, repo, num)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(reviews) - 1; i >= 0; i-- {
+		r := reviews[i]
+		if !botUserChecker(r.User.Login) {
+			continue
+		}
+
+		matches := taskCommentMarkerRe.FindStringSubmatch(r.Body)
+		if matches == nil || matches[1] != taskName {
+			continue
+		}
+
+		return &taskComment{ID: r.ID, SHA: matches[2], Review: taskCommentMarkerRe.ReplaceAllString(r.Body, "")}, nil
+	}
+
+	return nil, nil
+}
+
+// upsertTaskComment edits the previous comment of the task when existed, the
+// previous reviews will be kept collapsed, otherwise creates a new comment. A
+// new comment is created as well when the previous reviews don't fit in the
+// comment anymore, so that none of them is lost.
+func (s *Server) upsertTaskComment(logger *logrus.Entry, pr *github.PullRequest, taskName string, previous *taskComment, resp string) error {
+	org := pr.Base.Repo.Owner.Login
+	repo := pr.Base.Repo.Name
+	body := taskCommentMarker(taskName, pr.Head.SHA) + resp
+	if previous == nil {
+		return s.createComment(logger, org, repo, pr.Number, nil, body)
+	}
+
+	edited := body
+	// the comment has no review when only a notice was posted.
+	if previous.Review != "" {
+		edited = strings.Join([]string{
+			body,
+			"",
+			fmt.Sprintf("%s%s</summary>", previousReviewSummaryPrefix, shortSHA(previous.SHA)),
+			"",
+			previous.Review,
+			"</details>",
+		}, "\n")
+	}
+	if previous.History != "" {
+		edited = strings.Join([]string{edited, previous.History}, "\n\n")
+	}
+	if len(edited) > maxCommentBodySize {
+		logger.Debug("The previous reviews are too large to be kept, create a new comment.")
+		return s.createComment(logger, org, repo, pr.Number, nil, body)
+	}
+	if err := s.ghc.EditComment(org, repo, previous.ID, edited); err != nil {
+		logger.WithError(err).Warn("failed to edit comment")
+		return err
+	}
+
+	logger.Debug("Edited comment")
+	return nil
+}
+
+// upsertTaskNotice puts the notice on top of the comment of the task, so that
+// it's not repeated on every push. The reviewed head and the reviews in the
+// comment are kept, the pull request will be reviewed again on the next push.
+func (s *Server) upsertTaskNotice(logger *logrus.Entry, pr *github.PullRequest, taskName string, previous *taskComment, notice string) error {
+	org := pr.Base.Repo.Owner.Login
+	repo := pr.Base.Repo.Name
+	if previous == nil {
+		// nothing has been reviewed, the diff since the base is the whole pull request.
+		return s.createComment(logger, org, repo, pr.Number, nil, taskCommentMarker(taskName, pr.Base.SHA)+taskNoticePrefix+notice)
+	}
+
+	parts := []string{taskNoticePrefix + notice}
+	for _, part := range []string{previous.Review, previous.History} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if err := s.ghc.EditComment(org, repo, previous.ID, taskCommentMarker(taskName, previous.SHA)+strings.Join(parts, "\n\n")); err != nil {
+		logger.WithError(err).Warn("failed to edit comment")
+		return err
+	}
+
+	logger.Debug("Edited comment")
+	return nil
+}
+
+// outdatedTaskComment collapses the previous review of the task and drops its
+// marker, so that only the latest comment or review of a task has a marker.
+func outdatedTaskComment(previous *taskComment) string {
+	body := fmt.Sprintf("%s%s</summary>\n\n%s\n</details>", outdatedReviewSummaryPrefix, shortSHA(previous.SHA), previous.Review)
+	if previous.History != "" {
+		body = strings.Join([]string{body, previous.History}, "\n\n")
+	}
+	return body
+}
+
+// outdateTaskReviews outdates the previous comment and review of the task in
+// review mode, after a newer one has been posted.
+func (s *Server) outdateTaskReviews(logger *logrus.Entry, pr *github.PullRequest, previousComment, previousReview *taskComment) {
+	org := pr.Base.Repo.Owner.Login
+	repo := pr.Base.Repo.Name
+	if previousComment != nil {
+		if err := s.ghc.EditComment(org, repo, previousComment.ID, outdatedTaskComment(previousComment)); err != nil {
+			logger.WithError(err).Warn("Failed to outdate the previous comment of the task.")
+		}
+	}
+	if previousReview != nil {
+		if err := s.ghc.UpdateReview(org, repo, pr.Number, previousReview.ID, outdatedTaskComment(previousReview)); err != nil {
+			logger.WithError(err).Warn("Failed to outdate the previous review of the task.")
+		}
+	}
+}
+
+// incrementalPatch returns the diff since the last reviewed SHA when it's
+// smaller than the full diff of the pull request.
+func (s *Server) incrementalPatch(logger *logrus.Entry, pr *github.PullRequest, lastSHA, fullPatch string) (string, bool) {
+	diff, err := s.ghc.GetCompareDiff(pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, lastSHA, pr.Head.SHA)
+	if err != nil {
+		// the last reviewed commit may be gone after force pushing.
+		logger.WithError(err).Debugf("Failed to get the diff since %s, use the full diff.", lastSHA)
+		return fullPatch, false
+	}
+	if len(diff) == 0 || len(diff) >= len(fullPatch) {
+		return fullPatch, false
+	}
+
+	return string(diff), true
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestServer_findTaskComment(t *testing.T) {
+	ghc := newFakeGitHubClient()
+	ghc.IssueComments[1] = []github.IssueComment{
+		{ID: 1, Body: taskCommentMarker("task1", "sha1") + "review1", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 2, Body: taskCommentMarker("task2", "sha1") + "review2", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 3, Body: taskCommentMarker("task1", "sha0") + "fake", User: github.User{Login: "someone"}},
+		{ID: 4, Body: taskCommentMarker("task1", "sha2") + "review3\n\n" + previousReviewSummaryPrefix + "sha1</summary>\n\nreview1\n</details>", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 5, Body: taskCommentMarker("task3", "sha3") + "review4\n\n" + previousReviewSummaryPrefix + "sha2</summary>\n\nreview3\n</details>\n\n" +
+			previousReviewSummaryPrefix + "sha1</summary>\n\nreview1\n</details>", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 6, Body: taskCommentMarker("task5", "sha1") + taskNoticePrefix + "notice\n\nreview5", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 7, Body: taskCommentMarker("task6", "base") + taskNoticePrefix + "notice", User: github.User{Login: "k8s-ci-robot"}},
+	}
+	s := &Server{ghc: ghc}
+
+	tests := []struct {
+		name     string
+		taskName string
+		want     *taskComment
+	}{
+		{
+			name:     "latest comment of the task",
+			taskName: "task1",
+			want: &taskComment{ID: 4, SHA: "sha2", Review: "review3",
+				History: previousReviewSummaryPrefix + "sha1</summary>\n\nreview1\n</details>"},
+		},
+		{
+			name:     "comment with several previous reviews",
+			taskName: "task3",
+			want: &taskComment{ID: 5, SHA: "sha3", Review: "review4",
+				History: previousReviewSummaryPrefix + "sha2</summary>\n\nreview3\n</details>\n\n" +
+					previousReviewSummaryPrefix + "sha1</summary>\n\nreview1\n</details>"},
+		},
+		{
+			name:     "other task",
+			taskName: "task2",
+			want:     &taskComment{ID: 2, SHA: "sha1", Review: "review2"},
+		},
+		{
+			name:     "comment with a notice",
+			taskName: "task5",
+			want:     &taskComment{ID: 6, SHA: "sha1", Review: "review5"},
+		},
+		{
+			name:     "comment with only a notice",
+			taskName: "task6",
+			want:     &taskComment{ID: 7, SHA: "base"},
+		},
+		{
+			name:     "no comment for the task",
+			taskName: "task4",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.findTaskComment("org", "repo", 1, tt.taskName)
+			if err != nil {
+				t.Fatalf("findTaskComment() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("findTaskComment() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServer_findTaskReview(t *testing.T) {
+	ghc := newFakeGitHubClient()
+	ghc.Reviews[1] = []github.Review{
+		{ID: 1, Body: taskCommentMarker("task1", "sha1") + "review1", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 2, Body: taskCommentMarker("task1", "sha2") + "review2", User: github.User{Login: "k8s-ci-robot"}},
+		{ID: 3, Body: taskCommentMarker("task1", "sha0") + "fake", User: github.User{Login: "someone"}},
+		{ID: 4, Body: taskCommentMarker("task2", "sha1") + "review3", User: github.User{Login: "k8s-ci-robot"}},
+	}
+	s := &Server{ghc: ghc}
+
+	tests := []struct {
+		name     string
+		taskName string
+		want     *taskComment
+	}{
+		{
+			name:     "latest review of the task",
+			taskName: "task1",
+			want:     &taskComment{ID: 2, SHA: "sha2", Review: "review2"},
+		},
+		{
+			name:     "no review for the task",
+			taskName: "task3",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.findTaskReview("org", "repo", 1, tt.taskName)
+			if err != nil {
+				t.Fatalf("findTaskReview() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("findTaskReview() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServer_upsertTaskComment(t *testing.T) {
+	pr := &github.PullRequest{
+		Number: 1,
+		Head:   github.PullRequestBranch{SHA: "sha2"},
+		Base: github.PullRequestBranch{
+			Repo: github.Repo{Name: "repo", Owner: github.User{Login: "org"}},
+		},
+	}
+
+	t.Run("create new comment", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		if err := s.upsertTaskComment(logrus.NewEntry(logrus.New()), pr, "task1", nil, "review"); err != nil {
+			t.Fatalf("upsertTaskComment() error = %v", err)
+		}
+
+		want := []string{"org/repo#1:" + taskCommentMarker("task1", "sha2") + "review"}
+		if diff := cmp.Diff(want, ghc.IssueCommentsAdded); diff != "" {
+			t.Errorf("added comments mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("edit previous comment", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		previous := &taskComment{ID: 5, SHA: "0123456789", Review: "old review"}
+		if err := s.upsertTaskComment(logrus.NewEntry(logrus.New()), pr, "task1", previous, "review"); err != nil {
+			t.Fatalf("upsertTaskComment() error = %v", err)
+		}
+
+		want := []string{"org/repo#5:" + taskCommentMarker("task1", "sha2") + "review\n\n" +
+			previousReviewSummaryPrefix + "0123456</summary>\n\nold review\n</details>"}
+		if diff := cmp.Diff(want, ghc.IssueCommentsEdited); diff != "" {
+			t.Errorf("edited comments mismatch (-want +got):\n%s", diff)
+		}
+		if len(ghc.IssueCommentsAdded) != 0 {
+			t.Errorf("unexpected added comments: %v", ghc.IssueCommentsAdded)
+		}
+	})
+
+	t.Run("replace the comment with only a notice", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		previous := &taskComment{ID: 5, SHA: "base"}
+		if err := s.upsertTaskComment(logrus.NewEntry(logrus.New()), pr, "task1", previous, "review"); err != nil {
+			t.Fatalf("upsertTaskComment() error = %v", err)
+		}
+
+		want := []string{"org/repo#5:" + taskCommentMarker("task1", "sha2") + "review"}
+		if diff := cmp.Diff(want, ghc.IssueCommentsEdited); diff != "" {
+			t.Errorf("edited comments mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("keep all previous reviews", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		history := previousReviewSummaryPrefix + "sha0</summary>\n\noldest review\n</details>"
+		previous := &taskComment{ID: 5, SHA: "sha1", Review: "old review", History: history}
+		if err := s.upsertTaskComment(logrus.NewEntry(logrus.New()), pr, "task1", previous, "review"); err != nil {
+			t.Fatalf("upsertTaskComment() error = %v", err)
+		}
+
+		want := []string{"org/repo#5:" + taskCommentMarker("task1", "sha2") + "review\n\n" +
+			previousReviewSummaryPrefix + "sha1</summary>\n\nold review\n</details>\n\n" + history}
+		if diff := cmp.Diff(want, ghc.IssueCommentsEdited); diff != "" {
+			t.Errorf("edited comments mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("create new comment when the previous reviews don't fit", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		previous := &taskComment{ID: 5, SHA: "sha1", Review: strings.Repeat("x", maxCommentBodySize)}
+		if err := s.upsertTaskComment(logrus.NewEntry(logrus.New()), pr, "task1", previous, "review"); err != nil {
+			t.Fatalf("upsertTaskComment() error = %v", err)
+		}
+
+		want := []string{"org/repo#1:" + taskCommentMarker("task1", "sha2") + "review"}
+		if diff := cmp.Diff(want, ghc.IssueCommentsAdded); diff != "" {
+			t.Errorf("added comments mismatch (-want +got):\n%s", diff)
+		}
+		if len(ghc.IssueCommentsEdited) != 0 {
+			t.Errorf("unexpected edited comments: %v", ghc.IssueCommentsEdited)
+		}
+	})
+}
+
+func TestServer_upsertTaskNotice(t *testing.T) {
+	pr := &github.PullRequest{
+		Number: 1,
+		Head:   github.PullRequestBranch{SHA: "sha2"},
+		Base: github.PullRequestBranch{
+			SHA:  "base",
+			Repo: github.Repo{Name: "repo", Owner: github.User{Login: "org"}},
+		},
+	}
+
+	t.Run("create new comment without reviewed head", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		if err := s.upsertTaskNotice(logrus.NewEntry(logrus.New()), pr, "task1", nil, "notice"); err != nil {
+			t.Fatalf("upsertTaskNotice() error = %v", err)
+		}
+
+		want := []string{"org/repo#1:" + taskCommentMarker("task1", "base") + taskNoticePrefix + "notice"}
+		if diff := cmp.Diff(want, ghc.IssueCommentsAdded); diff != "" {
+			t.Errorf("added comments mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("keep the reviewed head and reviews", func(t *testing.T) {
+		ghc := newFakeGitHubClient()
+		s := &Server{ghc: ghc}
+		history := previousReviewSummaryPrefix + "sha0</summary>\n\noldest review\n</details>"
+		previous := &taskComment{ID: 5, SHA: "sha1", Review: "old review", History: history}
+		if err := s.upsertTaskNotice(logrus.NewEntry(logrus.New()), pr, "task1", previous, "notice"); err != nil {
+			t.Fatalf("upsertTaskNotice() error = %v", err)
+		}
+
+		want := []string{"org/repo#5:" + taskCommentMarker("task1", "sha1") + taskNoticePrefix + "notice\n\nold review\n\n" + history}
+		if diff := cmp.Diff(want, ghc.IssueCommentsEdited); diff != "" {
+			t.Errorf("edited comments mismatch (-want +got):\n%s", diff)
+		}
+		if len(ghc.IssueCommentsAdded) != 0 {
+			t.Errorf("unexpected added comments: %v", ghc.IssueCommentsAdded)
+		}
+	})
+}
+
+func TestServer_incrementalPatch(t *testing.T) {
+	ghc := newFakeGitHubClient()
+	ghc.compareDiffs["sha1...sha3"] = []byte("small diff")
+	ghc.compareDiffs["sha2...sha3"] = []byte("the incremental diff is larger than the full diff of the pull request")
+	s := &Server{ghc: ghc}
+	pr := &github.PullRequest{
+		Number: 1,
+		Head:   github.PullRequestBranch{SHA: "sha3"},
+		Base: github.PullRequestBranch{
+			Repo: github.Repo{Name: "repo", Owner: github.User{Login: "org"}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		lastSHA         string
+		want            string
+		wantIncremental bool
+	}{
+		{
+			name:            "incremental diff is smaller",
+			lastSHA:         "sha1",
+			want:            "small diff",
+			wantIncremental: true,
+		},
+		{
+			name:    "incremental diff is larger",
+			lastSHA: "sha2",
+			want:    "the full diff of the pull request",
+		},
+		{
+			name:    "last reviewed commit is gone",
+			lastSHA: "sha0",
+			want:    "the full diff of the pull request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, incremental := s.incrementalPatch(logrus.NewEntry(logrus.New()), pr, tt.lastSHA, "the full diff of the pull request")
+			if got != tt.want || incremental != tt.wantIncremental {
+				t.Errorf("incrementalPatch() = (%q, %v), want (%q, %v)", got, incremental, tt.want, tt.wantIncremental)
+			}
+		})
+	}
+}
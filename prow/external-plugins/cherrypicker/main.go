@@ -44,11 +44,12 @@ type options struct {
 	instrumentationOptions prowflagutil.InstrumentationOptions
 	logLevel               string
 
-	webhookSecretFile string
-	prowAssignments   bool
-	allowAll          bool
-	issueOnConflict   bool
-	labelPrefix       string
+	webhookSecretFile    string
+	prowAssignments      bool
+	allowAll             bool
+	issueOnConflict      bool
+	cherryPickOnConflict bool
+	labelPrefix          string
 }
 
 func (o *options) Validate() error {
@@ -72,6 +73,7 @@ func gatherOptions() options {
 	fs.BoolVar(&o.prowAssignments, "use-prow-assignments", true, "Use prow commands to assign cherrypicked PRs.")
 	fs.BoolVar(&o.allowAll, "allow-all", false, "Allow anybody to use automated cherrypicks by skipping GitHub organization membership checks.")
 	fs.BoolVar(&o.issueOnConflict, "create-issue-on-conflict", false, "Create a GitHub issue and assign it to the requestor on cherrypick conflict.")
+	fs.BoolVar(&o.cherryPickOnConflict, "cherry-pick-on-conflict", false, "Cherry-pick the merge commit of the PR, or its commits one by one for multi-commit PRs, on conflict and open a draft PR with the conflict markers, assigned to the requestor.")
 	fs.StringVar(&o.labelPrefix, "label-prefix", defaultLabelPrefix, "Set a custom label prefix.")
 	for _, group := range []flagutil.OptionGroup{&o.github, &o.instrumentationOptions} {
 		group.AddFlags(fs)
@@ -135,11 +137,12 @@ func main() {
 		ghc: githubClient,
 		log: log,
 
-		labels:          o.labels.Strings(),
-		prowAssignments: o.prowAssignments,
-		allowAll:        o.allowAll,
-		issueOnConflict: o.issueOnConflict,
-		labelPrefix:     o.labelPrefix,
+		labels:               o.labels.Strings(),
+		prowAssignments:      o.prowAssignments,
+		allowAll:             o.allowAll,
+		issueOnConflict:      o.issueOnConflict,
+		cherryPickOnConflict: o.cherryPickOnConflict,
+		labelPrefix:          o.labelPrefix,
 
 		bare:     &http.Client{},
 		patchURL: "https://patch-diff.githubusercontent.com",
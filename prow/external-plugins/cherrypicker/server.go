@@ -31,6 +31,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/config"
 	cherrypicker "k8s.io/test-infra/prow/external-plugins/cherrypicker/lib"
 	"k8s.io/test-infra/prow/git/v2"
@@ -54,6 +55,7 @@ type githubClient interface {
 	CreateComment(org, repo string, number int, comment string) error
 	CreateFork(org, repo string) (string, error)
 	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error)
+	CreateDraftPullRequest(org, repo, title, body, head, base string, canModify bool) (int, error)
 	CreateIssue(org, repo, title, body string, milestone int, labels, assignees []string) (int, error)
 	EnsureFork(forkingUser, org, repo string) (string, error)
 	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
@@ -62,6 +64,7 @@ type githubClient interface {
 	GetRepo(owner, name string) (github.FullRepo, error)
 	IsMember(org, user string) (bool, error)
 	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	ListPullRequestCommits(org, repo string, number int) ([]github.RepositoryCommit, error)
 	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
 	ListOrgMembers(org, role string) ([]github.TeamMember, error)
 }
@@ -103,6 +106,9 @@ type Server struct {
 	allowAll bool
 	// Create an issue on cherrypick conflict.
 	issueOnConflict bool
+	// Cherry-pick the merge commit or the commits of the PR on conflict and
+	// open a draft PR with the remaining conflicts.
+	cherryPickOnConflict bool
 	// Set a custom label prefix.
 	labelPrefix string
 
@@ -469,22 +475,36 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 	title = fmt.Sprintf("%s%s", titleTargetBranchIndicator, omitBaseBranchFromTitle(title, baseBranch))
 
 	// Apply the patch.
+	var conflicts []string
 	if err := r.Am(localPath); err != nil {
 		errs := []error{fmt.Errorf("failed to `git am`: %w", err)}
 		logger.WithError(err).Warn("failed to apply PR on top of target branch")
 		resp := fmt.Sprintf("#%d failed to apply on top of branch %q:\n```\n%v\n```", num, targetBranch, err)
-		if err := s.createComment(logger, org, repo, num, comment, resp); err != nil {
-			errs = append(errs, fmt.Errorf("failed to create comment: %w", err))
+		applied := false
+		if s.cherryPickOnConflict {
+			// Fall back to a 3-way cherry-pick of the PR, which commits the
+			// remaining conflicts for the requestor to resolve.
+			if conflicts, err = s.cherryPickCommits(r, org, repo, num); err != nil {
+				errs = append(errs, fmt.Errorf("failed to cherry-pick: %w", err))
+				logger.WithError(err).Warn("failed to cherry-pick PR on top of target branch")
+			} else {
+				applied = true
+			}
 		}
+		if !applied {
+			if err := s.createComment(logger, org, repo, num, comment, resp); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create comment: %w", err))
+			}
 
-		if s.issueOnConflict {
-			resp = fmt.Sprintf("Manual cherrypick required.\n\n%v", resp)
-			if err := s.createIssue(logger, org, repo, title, resp, num, comment, nil, []string{requestor}); err != nil {
-				errs = append(errs, fmt.Errorf("failed to create issue: %w", err))
+			if s.issueOnConflict {
+				resp = fmt.Sprintf("Manual cherrypick required.\n\n%v", resp)
+				if err := s.createIssue(logger, org, repo, title, resp, num, comment, nil, []string{requestor}); err != nil {
+					errs = append(errs, fmt.Errorf("failed to create issue: %w", err))
+				}
 			}
-		}
 
-		return utilerrors.NewAggregate(errs)
+			return utilerrors.NewAggregate(errs)
+		}
 	}
 
 	push := r.PushToNamedFork
@@ -506,7 +526,13 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 		cherryPickBody = cherrypicker.CreateCherrypickBody(num, "", releaseNoteFromParentPR(body))
 	}
 	head := fmt.Sprintf("%s:%s", s.botUser.Login, newBranch)
-	createdNum, err := s.ghc.CreatePullRequest(org, repo, title, cherryPickBody, head, targetBranch, true)
+	var createdNum int
+	if len(conflicts) > 0 {
+		cherryPickBody += conflictsBody(requestor, conflicts)
+		createdNum, err = s.ghc.CreateDraftPullRequest(org, repo, title, cherryPickBody, head, targetBranch, true)
+	} else {
+		createdNum, err = s.ghc.CreatePullRequest(org, repo, title, cherryPickBody, head, targetBranch, true)
+	}
 	if err != nil {
 		logger.WithError(err).Warn("failed to create new pull request")
 		resp := fmt.Sprintf("new pull request could not be created: %v", err)
@@ -514,6 +540,9 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 	}
 	*logger = *logger.WithField("new_pull_request_number", createdNum)
 	resp := fmt.Sprintf("new pull request created: #%d", createdNum)
+	if len(conflicts) > 0 {
+		resp = fmt.Sprintf("new draft pull request with conflicts created: #%d", createdNum)
+	}
 	logger.Info("new pull request created")
 	if err := s.createComment(logger, org, repo, num, comment, resp); err != nil {
 		return fmt.Errorf("failed to create comment: %w", err)
@@ -523,7 +552,8 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 			return fmt.Errorf("failed to add label %s: %w", label, err)
 		}
 	}
-	if s.prowAssignments {
+	// The requestor is always assigned to resolve the conflicts.
+	if s.prowAssignments || len(conflicts) > 0 {
 		if err := s.ghc.AssignIssue(org, repo, createdNum, []string{requestor}); err != nil {
 			logger.WithError(err).Warn("failed to assign to new PR")
 			// Ignore returning errors on failure to assign as this is most likely
@@ -617,6 +647,78 @@ func (s *Server) getPatch(org, repo, targetBranch string, num int) (string, erro
 	return localPath, nil
 }
 
+// cherryPickCommits cherry-picks the changes of the PR on top of the current
+// branch with a 3-way merge. The merge commit of the PR is picked, unless the
+// PR has multiple commits which are then picked one by one. Conflicts are
+// committed with their markers and the conflicting files are returned.
+func (s *Server) cherryPickCommits(r git.RepoClient, org, repo string, num int) ([]string, error) {
+	commits, err := s.ghc.ListPullRequestCommits(org, repo, num)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %s/%s#%d: %w", org, repo, num, err)
+	}
+	if len(commits) <= 1 {
+		return s.cherryPickMergeCommit(r, org, repo, num)
+	}
+
+	if err := r.FetchRef(fmt.Sprintf("pull/%d/head", num)); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s/%s#%d: %w", org, repo, num, err)
+	}
+	var conflicts []string
+	seen := sets.New[string]()
+	for _, commit := range commits {
+		// Merge commits only bring in changes from the base branch.
+		if len(commit.Parents) > 1 {
+			continue
+		}
+		files, err := r.CherryPick(commit.SHA, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !seen.Has(file) {
+				seen.Insert(file)
+				conflicts = append(conflicts, file)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// cherryPickMergeCommit cherry-picks the commit the PR was merged with, which
+// is either a merge commit picked relative to the base branch, or the squashed
+// or rebased commit of the PR.
+func (s *Server) cherryPickMergeCommit(r git.RepoClient, org, repo string, num int) ([]string, error) {
+	pr, err := s.ghc.GetPullRequest(org, repo, num)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s#%d: %w", org, repo, num, err)
+	}
+	if !pr.Merged || pr.MergeSHA == nil {
+		return nil, fmt.Errorf("%s/%s#%d has no merge commit", org, repo, num)
+	}
+	mergeSHA := *pr.MergeSHA
+	if exists, err := r.ObjectExists(mergeSHA); err != nil || !exists {
+		if err := r.FetchRef(mergeSHA); err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", mergeSHA, err)
+		}
+	}
+	mainline := 0
+	if _, err := r.RevParse(mergeSHA + "^2"); err == nil {
+		mainline = 1
+	}
+	return r.CherryPick(mergeSHA, mainline)
+}
+
+// conflictsBody lists the files left with conflict markers in the
+// cherry-pick PR.
+func conflictsBody(requestor string, conflicts []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\nThe cherry-pick has conflicts in the following files, @%s please resolve them:\n", requestor)
+	for _, file := range conflicts {
+		fmt.Fprintf(&b, "- `%s`\n", file)
+	}
+	return b.String()
+}
+
 func normalize(input string) string {
 	return strings.Replace(input, "/", "-", -1)
 }
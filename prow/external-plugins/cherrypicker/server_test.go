@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 
@@ -49,6 +51,7 @@ type fghc struct {
 	prLabels   []github.Label
 	orgMembers []github.TeamMember
 	issues     []github.Issue
+	commits    []github.RepositoryCommit
 }
 
 func (f *fghc) AddLabel(org, repo string, number int, label string) error {
@@ -176,6 +179,14 @@ func (f *fghc) CreateIssue(org, repo, title, body string, milestone int, labels,
 }
 
 func (f *fghc) CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error) {
+	return f.createPullRequest(title, body, head, base, false)
+}
+
+func (f *fghc) CreateDraftPullRequest(org, repo, title, body, head, base string, canModify bool) (int, error) {
+	return f.createPullRequest(title, body, head, base, true)
+}
+
+func (f *fghc) createPullRequest(title, body, head, base string, draft bool) (int, error) {
 	f.Lock()
 	defer f.Unlock()
 	var num int
@@ -191,10 +202,17 @@ func (f *fghc) CreatePullRequest(org, repo, title, body, head, base string, canM
 		Number: num,
 		Head:   github.PullRequestBranch{Ref: head},
 		Base:   github.PullRequestBranch{Ref: base},
+		Draft:  draft,
 	})
 	return num, nil
 }
 
+func (f *fghc) ListPullRequestCommits(org, repo string, number int) ([]github.RepositoryCommit, error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.commits, nil
+}
+
 func (f *fghc) ListIssueComments(org, repo string, number int) ([]github.IssueComment, error) {
 	f.Lock()
 	defer f.Unlock()
@@ -993,6 +1011,164 @@ func testCherryPickPRAssignments(clients localgit.Clients, t *testing.T) {
 	}
 }
 
+func TestCherryPickOnConflictV2(t *testing.T) {
+	t.Parallel()
+	testCherryPickOnConflict(localgit.NewV2, t)
+}
+
+func testCherryPickOnConflict(clients localgit.Clients, t *testing.T) {
+	fixed := bytes.Replace(initialFiles["bar.go"], []byte("42"), []byte("49"), 1)
+	testCases := []struct {
+		name    string
+		commits []map[string][]byte
+		// merge merges the PR with a merge commit instead of a squashed commit.
+		merge bool
+	}{
+		{
+			name:    "squashed commit is picked",
+			commits: []map[string][]byte{{"bar.go": fixed}},
+		},
+		{
+			name:    "merge commit is picked",
+			commits: []map[string][]byte{{"bar.go": fixed}},
+			merge:   true,
+		},
+		{
+			name:    "multiple commits are picked one by one",
+			commits: []map[string][]byte{{"bar.go": fixed}, {"baz.go": []byte("package bar\n")}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			iNumber := fakePR.GetPRNumber()
+			lg, c := makeFakeRepoWithCommit(clients, t)
+			if err := lg.CheckoutNewBranch("foo", "bar", "stage"); err != nil {
+				t.Fatalf("Checking out pull branch: %v", err)
+			}
+			if err := lg.AddCommit("foo", "bar", map[string][]byte{"bar.go": bytes.Replace(initialFiles["bar.go"], []byte("42"), []byte("43"), 1)}); err != nil {
+				t.Fatalf("Adding conflicting commit: %v", err)
+			}
+			// The commits of the PR are fetched from its head ref.
+			if err := lg.Checkout("foo", "bar", "stage~1"); err != nil {
+				t.Fatalf("Checking out base commit: %v", err)
+			}
+			headRef := fmt.Sprintf("pull/%d/head", iNumber)
+			if err := lg.CheckoutNewBranch("foo", "bar", headRef); err != nil {
+				t.Fatalf("Checking out PR branch: %v", err)
+			}
+			var commits []github.RepositoryCommit
+			for _, files := range tc.commits {
+				if err := lg.AddCommit("foo", "bar", files); err != nil {
+					t.Fatalf("Adding PR commit: %v", err)
+				}
+				sha, err := lg.RevParse("foo", "bar", "HEAD")
+				if err != nil {
+					t.Fatalf("Parsing PR commit: %v", err)
+				}
+				commits = append(commits, github.RepositoryCommit{SHA: strings.TrimSpace(sha)})
+			}
+			mergeSHA := commits[len(commits)-1].SHA
+			if tc.merge {
+				if err := lg.Checkout("foo", "bar", "stage~1"); err != nil {
+					t.Fatalf("Checking out base commit: %v", err)
+				}
+				if err := lg.CheckoutNewBranch("foo", "bar", "merged"); err != nil {
+					t.Fatalf("Checking out merged branch: %v", err)
+				}
+				if _, err := lg.Merge("foo", "bar", headRef); err != nil {
+					t.Fatalf("Merging PR: %v", err)
+				}
+				sha, err := lg.RevParse("foo", "bar", "HEAD")
+				if err != nil {
+					t.Fatalf("Parsing merge commit: %v", err)
+				}
+				mergeSHA = strings.TrimSpace(sha)
+			}
+
+			ghc := &fghc{
+				pr: &github.PullRequest{
+					Base: github.PullRequestBranch{
+						Ref: "master",
+					},
+					Merged:   true,
+					MergeSHA: &mergeSHA,
+					Title:    "This is a fix for X",
+					Body:     body,
+				},
+				isMember: true,
+				patch:    patch,
+				commits:  commits,
+			}
+			ic := github.IssueCommentEvent{
+				Action: github.IssueCommentActionCreated,
+				Repo: github.Repo{
+					Owner: github.User{
+						Login: "foo",
+					},
+					Name:     "bar",
+					FullName: "foo/bar",
+				},
+				Issue: github.Issue{
+					Number:      iNumber,
+					State:       "closed",
+					PullRequest: &struct{}{},
+				},
+				Comment: github.IssueComment{
+					User: github.User{
+						Login: "wiseguy",
+					},
+					Body: "/cherrypick stage",
+				},
+			}
+
+			botUser := &github.UserData{Login: "ci-robot", Email: "ci-robot@users.noreply.github.com"}
+			expectedTitle := "[stage] This is a fix for X"
+			expectedBody := fmt.Sprintf("This is an automated cherry-pick of #%d\n\n```release-note\nUpdate the magic number from 42 to 49\n```\n\nThe cherry-pick has conflicts in the following files, @wiseguy please resolve them:\n- `bar.go`\n", iNumber)
+			expectedBase := "stage"
+			expectedHead := fmt.Sprintf(botUser.Login+":"+cherryPickBranchFmt, iNumber, expectedBase)
+			expectedLabels := []string{}
+			expected := fmt.Sprintf(expectedFmt, expectedTitle, expectedBody, expectedHead, expectedBase, expectedLabels)
+
+			getSecret := func() []byte {
+				return []byte("sha=abcdefg")
+			}
+
+			s := &Server{
+				botUser:        botUser,
+				gc:             c,
+				push:           func(forkName, newBranch string, force bool) error { return nil },
+				ghc:            ghc,
+				tokenGenerator: getSecret,
+				log:            logrus.StandardLogger().WithField("client", "cherrypicker"),
+				repos:          []github.Repo{{Fork: true, FullName: "ci-robot/bar"}},
+
+				cherryPickOnConflict: true,
+			}
+
+			if err := s.handleIssueComment(logrus.NewEntry(logrus.StandardLogger()), ic); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ghc.prs) != 1 {
+				t.Fatalf("Expected a single PR, got %d", len(ghc.prs))
+			}
+			got := prToString(ghc.prs[0])
+			if got != expected {
+				t.Errorf("Expected (%d):\n%s\nGot (%d):\n%+v\n", len(expected), expected, len(got), got)
+			}
+			if !ghc.prs[0].Draft {
+				t.Error("Expected a draft PR")
+			}
+			if expected := []github.User{{Login: "wiseguy"}}; !cmp.Equal(ghc.prs[0].Assignees, expected) {
+				t.Errorf("Expected assignees %+v, got %+v", expected, ghc.prs[0].Assignees)
+			}
+			if expected := "new draft pull request with conflicts created: #1"; len(ghc.comments) != 1 || !strings.Contains(ghc.comments[0], expected) {
+				t.Errorf("Expected a comment containing %q, got %q", expected, ghc.comments)
+			}
+		})
+	}
+}
+
 func TestHandleLocks(t *testing.T) {
 	t.Parallel()
 	s := &Server{
@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gangway
+
+import (
+	context "context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	prowcrd "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/pjutil"
+)
+
+const (
+	// AbortedByAnnotation records the API client that aborted the Prow Job.
+	AbortedByAnnotation = "prow.k8s.io/gangway-aborted-by"
+	// RerunByAnnotation records the API client that requested the rerun, on
+	// the new Prow Job.
+	RerunByAnnotation = "prow.k8s.io/gangway-rerun-by"
+	// RerunOfAnnotation records the name of the rerun Prow Job, on the new
+	// Prow Job.
+	RerunOfAnnotation = "prow.k8s.io/gangway-rerun-of"
+)
+
+// AbortJobExecution aborts a triggered or pending Prow job. Only the clients
+// authorized for the job (see ClientAuthorized()) can abort it.
+func (gw *Gangway) AbortJobExecution(ctx context.Context, ajer *AbortJobExecutionRequest) (*JobExecution, error) {
+	l, allowedApiClient, pj, err := gw.getAuthorizedProwJob(ctx, ajer.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if pj.Status.State != prowcrd.TriggeredState && pj.Status.State != prowcrd.PendingState {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot abort job with state: %q", pj.Status.State)
+	}
+
+	requester, err := requesterOf(allowedApiClient)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// The resourceVersion makes the patch fail if the job changed since we
+	// read it, e.g. if it completed in the meantime.
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": pj.ResourceVersion,
+			"annotations": map[string]string{
+				AbortedByAnnotation: requester,
+			},
+		},
+		"status": map[string]interface{}{
+			"state":       prowcrd.AbortedState,
+			"description": fmt.Sprintf("%s successfully aborted %s.", requester, pj.Name),
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	aborted, err := gw.ProwJobClient.Patch(ctx, pj.Name, ktypes.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		if kerrors.IsConflict(err) {
+			return nil, status.Error(codes.Aborted, "the job changed while being aborted, please retry")
+		}
+		l.WithError(err).Error("could not patch aborted job")
+		return nil, status.Error(codes.Internal, "could not abort job")
+	}
+	l.WithField("requester", requester).Info("Aborted job.")
+
+	return ToJobExecution(aborted), nil
+}
+
+// RerunJobExecution triggers a new Prow job with the same spec as an existing
+// one. Only the clients authorized for the job (see ClientAuthorized()) can
+// rerun it.
+func (gw *Gangway) RerunJobExecution(ctx context.Context, rjer *RerunJobExecutionRequest) (*JobExecution, error) {
+	l, allowedApiClient, pj, err := gw.getAuthorizedProwJob(ctx, rjer.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	requester, err := requesterOf(allowedApiClient)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	annotations := make(map[string]string)
+	for k, v := range pj.Annotations {
+		annotations[k] = v
+	}
+	// The annotations of the actions on the original job don't apply to the
+	// new one.
+	delete(annotations, AbortedByAnnotation)
+	annotations[RerunByAnnotation] = requester
+	annotations[RerunOfAnnotation] = pj.Name
+
+	newPJ := pjutil.NewProwJob(pj.Spec, pj.Labels, annotations)
+	newPJ.Status.Description = fmt.Sprintf("%s successfully reran %s.", requester, pj.Name)
+
+	created, err := gw.ProwJobClient.Create(ctx, &newPJ, metav1.CreateOptions{})
+	if err != nil {
+		l.WithError(err).Error("could not create rerun job")
+		return nil, status.Error(codes.Internal, "could not rerun job")
+	}
+	l.WithFields(logrus.Fields{"requester": requester, "new-prowjob": created.Name}).Info("Reran job.")
+
+	return ToJobExecution(created), nil
+}
+
+// getAuthorizedProwJob identifies the client from the request metadata, and
+// gets the Prow Job only if the client is authorized for it.
+func (gw *Gangway) getAuthorizedProwJob(ctx context.Context, id string) (*logrus.Entry, *config.AllowedApiClient, *prowcrd.ProwJob, error) {
+	err, md := getHttpRequestHeaders(ctx)
+	if err != nil {
+		logrus.WithError(err).Debug("could not find request HTTP headers")
+		return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if id == "" {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, "id field cannot be empty")
+	}
+
+	mainConfig := gw.ConfigAgent.Config()
+	allowedApiClient, err := mainConfig.IdentifyAllowedClient(md)
+	if err != nil {
+		logrus.WithError(err).Debug("could not find client in allowlist")
+		return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	l, err := getDecoratedLoggerEntry(allowedApiClient, md)
+	if err != nil {
+		l = logrus.NewEntry(logrus.New())
+	}
+	l = l.WithField("prowjob", id)
+
+	pj, err := gw.ProwJobClient.Get(ctx, id, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil, nil, status.Error(codes.NotFound, "Prow Job not found")
+		}
+		l.WithError(err).Error("could not get prow job")
+		return nil, nil, nil, status.Error(codes.Internal, "could not get Prow Job")
+	}
+
+	if !ClientAuthorized(allowedApiClient, *pj) {
+		l.Debug("client is not authorized for the prow job")
+		return nil, nil, nil, status.Error(codes.PermissionDenied, "client is not authorized for this Prow Job")
+	}
+
+	return l, allowedApiClient, pj, nil
+}
+
+// requesterOf identifies the API client in the annotations of the Prow Jobs.
+func requesterOf(allowedApiClient *config.AllowedApiClient) (string, error) {
+	cv, err := allowedApiClient.GetApiClientCloudVendor()
+	if err != nil {
+		return "", err
+	}
+
+	return cv.GetUUID(), nil
+}
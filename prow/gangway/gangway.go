@@ -18,17 +18,23 @@ package gangway
 
 import (
 	context "context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 	prowcrd "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/config"
@@ -47,13 +53,19 @@ type Gangway struct {
 	ConfigAgent        *config.Agent
 	ProwJobClient      ProwJobClient
 	InRepoConfigGetter config.InRepoConfigGetter
+	// JobWatcher is required by WatchJobExecutions. ListJobExecutions is served
+	// from its cache when set.
+	JobWatcher *JobWatcher
 }
 
 // ProwJobClient describes a Kubernetes client for the Prow Job CR. Unlike a
-// general-purpose client, it only expects 2 methods, Create() and Get().
+// general-purpose client, it only expects 4 methods, Create(), Get(), List()
+// and Patch().
 type ProwJobClient interface {
 	Create(context.Context, *prowcrd.ProwJob, metav1.CreateOptions) (*prowcrd.ProwJob, error)
 	Get(context.Context, string, metav1.GetOptions) (*prowcrd.ProwJob, error)
+	List(context.Context, metav1.ListOptions) (*prowcrd.ProwJobList, error)
+	Patch(ctx context.Context, name string, pt ktypes.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*prowcrd.ProwJob, error)
 }
 
 // CreateJobExecution triggers a new Prow job.
@@ -115,33 +127,155 @@ func (gw *Gangway) GetJobExecution(ctx context.Context, gjer *GetJobExecutionReq
 		return nil, err
 	}
 
-	var jobStatus JobExecutionStatus
+	jobExec := &JobExecution{
+		Id:        prowJobCR.Name,
+		JobStatus: toJobExecutionStatus(prowJobCR.Status.State),
+	}
+
+	return jobExec, nil
+}
+
+// ListJobExecutions returns the Prow job executions matching all the filters
+// in the request, most recently created first. Only the executions that the
+// client is authorized for (see ClientAuthorized()) are returned.
+func (gw *Gangway) ListJobExecutions(ctx context.Context, ljer *ListJobExecutionsRequest) (*JobExecutions, error) {
+	err, md := getHttpRequestHeaders(ctx)
+	if err != nil {
+		logrus.WithError(err).Debug("could not find request HTTP headers")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := ljer.Validate(); err != nil {
+		logrus.WithError(err).Debug("could not validate request fields")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	mainConfig := gw.ConfigAgent.Config()
+	allowedApiClient, err := mainConfig.IdentifyAllowedClient(md)
+	if err != nil {
+		logrus.WithError(err).Debug("could not find client in allowlist")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
-	// Translate ProwJobStatus.State in the Prow Job CR into a JobExecutionStatus.
-	switch prowJobCR.Status.State {
+	cursor, err := decodePageToken(ljer.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	matched, err := gw.listProwJobs(ctx, func(pj *prowcrd.ProwJob) bool {
+		return ClientAuthorized(allowedApiClient, *pj) && ljer.matches(pj)
+	})
+	if err != nil {
+		logrus.WithError(err).Error("failed to list prow jobs")
+		return nil, status.Error(codes.Internal, "failed to list prow jobs")
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return prowJobBefore(&matched[i], &matched[j])
+	})
+
+	// Skip the executions returned by the previous pages.
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return !prowJobBefore(&matched[i], cursor) && !samePosition(&matched[i], cursor)
+		})
+	}
+
+	pageSize := ljer.pageSize()
+	jobExecs := &JobExecutions{}
+	for i := start; i < len(matched) && len(jobExecs.JobExecution) < pageSize; i++ {
+		jobExecs.JobExecution = append(jobExecs.JobExecution, ToJobExecution(&matched[i]))
+	}
+	if end := start + len(jobExecs.JobExecution); end < len(matched) {
+		jobExecs.NextPageToken = encodePageToken(&matched[end-1])
+	}
+
+	return jobExecs, nil
+}
+
+// listProwJobs returns the Prow Job CRs matching the filter. They are listed
+// from the cache of the JobWatcher once it is synced, and from the API server
+// in chunks otherwise.
+func (gw *Gangway) listProwJobs(ctx context.Context, filter func(*prowcrd.ProwJob) bool) ([]prowcrd.ProwJob, error) {
+	if gw.JobWatcher != nil {
+		if pjs, ok := gw.JobWatcher.list(filter); ok {
+			return pjs, nil
+		}
+	}
+
+	var res []prowcrd.ProwJob
+	opts := metav1.ListOptions{Limit: listChunkSize}
+	for {
+		prowJobList, err := gw.ProwJobClient.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range prowJobList.Items {
+			if filter(&prowJobList.Items[i]) {
+				res = append(res, prowJobList.Items[i])
+			}
+		}
+		if prowJobList.Continue == "" {
+			return res, nil
+		}
+		opts.Continue = prowJobList.Continue
+	}
+}
+
+// ToJobExecution translates a Prow Job CR into a JobExecution.
+func ToJobExecution(pj *prowcrd.ProwJob) *JobExecution {
+	jobExec := &JobExecution{
+		Id:         pj.Name,
+		JobName:    pj.Spec.Job,
+		JobType:    toJobExecutionType(pj.Spec.Type),
+		JobStatus:  toJobExecutionStatus(pj.Status.State),
+		CreateTime: timestamppb.New(pj.Status.StartTime.Time),
+	}
+	if pj.Spec.Refs != nil {
+		// FromCrdRefs only fails on nil refs.
+		jobExec.Refs, _ = FromCrdRefs(pj.Spec.Refs)
+	}
+	if pj.Status.CompletionTime != nil {
+		jobExec.CompletionTime = timestamppb.New(pj.Status.CompletionTime.Time)
+	}
+
+	return jobExec
+}
+
+// Translate ProwJobStatus.State in the Prow Job CR into a JobExecutionStatus.
+func toJobExecutionStatus(state prowcrd.ProwJobState) JobExecutionStatus {
+	switch state {
 	case prowcrd.TriggeredState:
-		jobStatus = JobExecutionStatus_TRIGGERED
+		return JobExecutionStatus_TRIGGERED
 	case prowcrd.PendingState:
-		jobStatus = JobExecutionStatus_PENDING
+		return JobExecutionStatus_PENDING
 	case prowcrd.SuccessState:
-		jobStatus = JobExecutionStatus_SUCCESS
+		return JobExecutionStatus_SUCCESS
 	case prowcrd.FailureState:
-		jobStatus = JobExecutionStatus_FAILURE
+		return JobExecutionStatus_FAILURE
 	case prowcrd.AbortedState:
-		jobStatus = JobExecutionStatus_ABORTED
+		return JobExecutionStatus_ABORTED
 	case prowcrd.ErrorState:
-		jobStatus = JobExecutionStatus_ERROR
+		return JobExecutionStatus_ERROR
 	default:
-		jobStatus = JobExecutionStatus_JOB_EXECUTION_STATUS_UNSPECIFIED
-
+		return JobExecutionStatus_JOB_EXECUTION_STATUS_UNSPECIFIED
 	}
+}
 
-	jobExec := &JobExecution{
-		Id:        prowJobCR.Name,
-		JobStatus: jobStatus,
+// Translate ProwJobSpec.Type in the Prow Job CR into a JobExecutionType.
+func toJobExecutionType(jobType prowcrd.ProwJobType) JobExecutionType {
+	switch jobType {
+	case prowcrd.PeriodicJob:
+		return JobExecutionType_PERIODIC
+	case prowcrd.PostsubmitJob:
+		return JobExecutionType_POSTSUBMIT
+	case prowcrd.PresubmitJob:
+		return JobExecutionType_PRESUBMIT
+	case prowcrd.BatchJob:
+		return JobExecutionType_BATCH
+	default:
+		return JobExecutionType_JOB_EXECUTION_TYPE_UNSPECIFIED
 	}
-
-	return jobExec, nil
 }
 
 // ClientAuthorized checks whether or not a client can run a Prow job based on
@@ -150,6 +284,9 @@ func (gw *Gangway) GetJobExecution(ctx context.Context, gjer *GetJobExecutionReq
 // allowlist (allowed_api_clients) allows it.
 func ClientAuthorized(allowedApiClient *config.AllowedApiClient, prowJobCR prowcrd.ProwJob) bool {
 	pjd := prowJobCR.Spec.ProwJobDefault
+	if pjd == nil {
+		return false
+	}
 	for _, allowedJobsFilter := range allowedApiClient.AllowedJobsFilters {
 		if allowedJobsFilter.TenantID == pjd.TenantID {
 			return true
@@ -349,6 +486,153 @@ func (cjer *CreateJobExecutionRequest) Validate() error {
 	return nil
 }
 
+const (
+	defaultListPageSize = 100
+	maxListPageSize     = 1000
+	// listChunkSize is the number of Prow Job CRs listed from the API server
+	// at once when the JobWatcher cache can't be used.
+	listChunkSize = 500
+)
+
+func (ljer *ListJobExecutionsRequest) Validate() error {
+	if ljer.GetPageSize() < 0 {
+		return fmt.Errorf("page_size cannot be negative: %d", ljer.GetPageSize())
+	}
+
+	if ljer.GetPullNumber() < 0 {
+		return fmt.Errorf("pull_number cannot be negative: %d", ljer.GetPullNumber())
+	}
+
+	for name, ts := range map[string]*timestamppb.Timestamp{
+		"created_after":  ljer.GetCreatedAfter(),
+		"created_before": ljer.GetCreatedBefore(),
+	} {
+		if ts == nil {
+			continue
+		}
+		if err := ts.CheckValid(); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (ljer *ListJobExecutionsRequest) pageSize() int {
+	pageSize := int(ljer.GetPageSize())
+	if pageSize == 0 {
+		return defaultListPageSize
+	}
+	if pageSize > maxListPageSize {
+		return maxListPageSize
+	}
+	return pageSize
+}
+
+// matches checks whether the Prow Job CR matches all the filters of the
+// request. Empty (zero-value) filters match everything.
+func (ljer *ListJobExecutionsRequest) matches(pj *prowcrd.ProwJob) bool {
+	if ljer.GetJobName() != "" && ljer.GetJobName() != pj.Spec.Job {
+		return false
+	}
+	if ljer.GetStatus() != JobExecutionStatus_JOB_EXECUTION_STATUS_UNSPECIFIED && ljer.GetStatus() != toJobExecutionStatus(pj.Status.State) {
+		return false
+	}
+	if ljer.GetJobType() != JobExecutionType_JOB_EXECUTION_TYPE_UNSPECIFIED && ljer.GetJobType() != toJobExecutionType(pj.Spec.Type) {
+		return false
+	}
+
+	if ljer.GetOrg() != "" || ljer.GetRepo() != "" || ljer.GetBaseRef() != "" || ljer.GetBaseSha() != "" || ljer.GetPullNumber() != 0 {
+		refs := pj.Spec.Refs
+		if refs == nil {
+			return false
+		}
+		if ljer.GetOrg() != "" && ljer.GetOrg() != refs.Org {
+			return false
+		}
+		if ljer.GetRepo() != "" && ljer.GetRepo() != refs.Repo {
+			return false
+		}
+		if ljer.GetBaseRef() != "" && ljer.GetBaseRef() != refs.BaseRef {
+			return false
+		}
+		if ljer.GetBaseSha() != "" && ljer.GetBaseSha() != refs.BaseSHA {
+			return false
+		}
+		if ljer.GetPullNumber() != 0 {
+			found := false
+			for _, pull := range refs.Pulls {
+				if pull.Number == int(ljer.GetPullNumber()) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+
+	createTime := pj.Status.StartTime.Time
+	if ljer.GetCreatedAfter() != nil && createTime.Before(ljer.GetCreatedAfter().AsTime()) {
+		return false
+	}
+	if ljer.GetCreatedBefore() != nil && !createTime.Before(ljer.GetCreatedBefore().AsTime()) {
+		return false
+	}
+
+	return true
+}
+
+// prowJobBefore defines the order of the listed executions: most recently
+// created first, with ties broken by name so that the order is stable across
+// pages.
+func prowJobBefore(a, b *prowcrd.ProwJob) bool {
+	if !a.Status.StartTime.Equal(&b.Status.StartTime) {
+		return a.Status.StartTime.After(b.Status.StartTime.Time)
+	}
+	return a.Name < b.Name
+}
+
+func samePosition(a, b *prowcrd.ProwJob) bool {
+	return a.Status.StartTime.Equal(&b.Status.StartTime) && a.Name == b.Name
+}
+
+// encodePageToken encodes the position of the last execution of a page into
+// an opaque token. The next page starts right after this position, so that
+// pagination is not disturbed by executions created in the meantime.
+func encodePageToken(last *prowcrd.ProwJob) string {
+	cursor := fmt.Sprintf("%d/%s", last.Status.StartTime.UnixNano(), last.Name)
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+// decodePageToken decodes the token from encodePageToken() into a Prow Job
+// CR holding only the position fields. It returns nil for an empty token.
+func decodePageToken(token string) (*prowcrd.ProwJob, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("invalid page_token: %q", token)
+	cursor, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	nanos, name, found := strings.Cut(string(cursor), "/")
+	if !found {
+		return nil, invalid
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &prowcrd.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     prowcrd.ProwJobStatus{StartTime: metav1.NewTime(time.Unix(0, unixNano))},
+	}, nil
+}
+
 func (gitRefs *Refs) Validate() error {
 	if len(gitRefs.Org) == 0 {
 		return fmt.Errorf("gitRefs: Org cannot be empty")
@@ -22,6 +22,9 @@ const (
 	Prow_CreateJobExecution_FullMethodName = "/Prow/CreateJobExecution"
 	Prow_GetJobExecution_FullMethodName    = "/Prow/GetJobExecution"
 	Prow_ListJobExecutions_FullMethodName  = "/Prow/ListJobExecutions"
+	Prow_AbortJobExecution_FullMethodName  = "/Prow/AbortJobExecution"
+	Prow_RerunJobExecution_FullMethodName  = "/Prow/RerunJobExecution"
+	Prow_WatchJobExecutions_FullMethodName = "/Prow/WatchJobExecutions"
 )
 
 // ProwClient is the client API for Prow service.
@@ -36,6 +39,13 @@ type ProwClient interface {
 	CreateJobExecution(ctx context.Context, in *CreateJobExecutionRequest, opts ...grpc.CallOption) (*JobExecution, error)
 	GetJobExecution(ctx context.Context, in *GetJobExecutionRequest, opts ...grpc.CallOption) (*JobExecution, error)
 	ListJobExecutions(ctx context.Context, in *ListJobExecutionsRequest, opts ...grpc.CallOption) (*JobExecutions, error)
+	// Aborts a triggered or pending Prow job execution.
+	AbortJobExecution(ctx context.Context, in *AbortJobExecutionRequest, opts ...grpc.CallOption) (*JobExecution, error)
+	// Triggers a new Prow job execution with the same spec as an existing one.
+	RerunJobExecution(ctx context.Context, in *RerunJobExecutionRequest, opts ...grpc.CallOption) (*JobExecution, error)
+	// Streams the status updates of the Prow job executions as they happen,
+	// instead of polling GetJobExecution.
+	WatchJobExecutions(ctx context.Context, in *WatchJobExecutionsRequest, opts ...grpc.CallOption) (Prow_WatchJobExecutionsClient, error)
 }
 
 type prowClient struct {
@@ -73,6 +83,56 @@ func (c *prowClient) ListJobExecutions(ctx context.Context, in *ListJobExecution
 	return out, nil
 }
 
+func (c *prowClient) AbortJobExecution(ctx context.Context, in *AbortJobExecutionRequest, opts ...grpc.CallOption) (*JobExecution, error) {
+	out := new(JobExecution)
+	err := c.cc.Invoke(ctx, Prow_AbortJobExecution_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prowClient) RerunJobExecution(ctx context.Context, in *RerunJobExecutionRequest, opts ...grpc.CallOption) (*JobExecution, error) {
+	out := new(JobExecution)
+	err := c.cc.Invoke(ctx, Prow_RerunJobExecution_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prowClient) WatchJobExecutions(ctx context.Context, in *WatchJobExecutionsRequest, opts ...grpc.CallOption) (Prow_WatchJobExecutionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Prow_ServiceDesc.Streams[0], Prow_WatchJobExecutions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &prowWatchJobExecutionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Prow_WatchJobExecutionsClient interface {
+	Recv() (*JobExecutionUpdate, error)
+	grpc.ClientStream
+}
+
+type prowWatchJobExecutionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *prowWatchJobExecutionsClient) Recv() (*JobExecutionUpdate, error) {
+	m := new(JobExecutionUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ProwServer is the server API for Prow service.
 // All implementations must embed UnimplementedProwServer
 // for forward compatibility
@@ -85,6 +145,13 @@ type ProwServer interface {
 	CreateJobExecution(context.Context, *CreateJobExecutionRequest) (*JobExecution, error)
 	GetJobExecution(context.Context, *GetJobExecutionRequest) (*JobExecution, error)
 	ListJobExecutions(context.Context, *ListJobExecutionsRequest) (*JobExecutions, error)
+	// Aborts a triggered or pending Prow job execution.
+	AbortJobExecution(context.Context, *AbortJobExecutionRequest) (*JobExecution, error)
+	// Triggers a new Prow job execution with the same spec as an existing one.
+	RerunJobExecution(context.Context, *RerunJobExecutionRequest) (*JobExecution, error)
+	// Streams the status updates of the Prow job executions as they happen,
+	// instead of polling GetJobExecution.
+	WatchJobExecutions(*WatchJobExecutionsRequest, Prow_WatchJobExecutionsServer) error
 	mustEmbedUnimplementedProwServer()
 }
 
@@ -101,6 +168,15 @@ func (UnimplementedProwServer) GetJobExecution(context.Context, *GetJobExecution
 func (UnimplementedProwServer) ListJobExecutions(context.Context, *ListJobExecutionsRequest) (*JobExecutions, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListJobExecutions not implemented")
 }
+func (UnimplementedProwServer) AbortJobExecution(context.Context, *AbortJobExecutionRequest) (*JobExecution, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbortJobExecution not implemented")
+}
+func (UnimplementedProwServer) RerunJobExecution(context.Context, *RerunJobExecutionRequest) (*JobExecution, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RerunJobExecution not implemented")
+}
+func (UnimplementedProwServer) WatchJobExecutions(*WatchJobExecutionsRequest, Prow_WatchJobExecutionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchJobExecutions not implemented")
+}
 func (UnimplementedProwServer) mustEmbedUnimplementedProwServer() {}
 
 // UnsafeProwServer may be embedded to opt out of forward compatibility for this service.
@@ -168,6 +244,63 @@ func _Prow_ListJobExecutions_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Prow_AbortJobExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbortJobExecutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProwServer).AbortJobExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prow_AbortJobExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProwServer).AbortJobExecution(ctx, req.(*AbortJobExecutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prow_RerunJobExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RerunJobExecutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProwServer).RerunJobExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prow_RerunJobExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProwServer).RerunJobExecution(ctx, req.(*RerunJobExecutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prow_WatchJobExecutions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchJobExecutionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProwServer).WatchJobExecutions(m, &prowWatchJobExecutionsServer{stream})
+}
+
+type Prow_WatchJobExecutionsServer interface {
+	Send(*JobExecutionUpdate) error
+	grpc.ServerStream
+}
+
+type prowWatchJobExecutionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *prowWatchJobExecutionsServer) Send(m *JobExecutionUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Prow_ServiceDesc is the grpc.ServiceDesc for Prow service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -187,7 +320,21 @@ var Prow_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListJobExecutions",
 			Handler:    _Prow_ListJobExecutions_Handler,
 		},
+		{
+			MethodName: "AbortJobExecution",
+			Handler:    _Prow_AbortJobExecution_Handler,
+		},
+		{
+			MethodName: "RerunJobExecution",
+			Handler:    _Prow_RerunJobExecution_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchJobExecutions",
+			Handler:       _Prow_WatchJobExecutions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "gangway.proto",
 }
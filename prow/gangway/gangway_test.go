@@ -0,0 +1,327 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gangway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	prowcrd "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowfake "k8s.io/test-infra/prow/client/clientset/versioned/fake"
+	"k8s.io/test-infra/prow/config"
+)
+
+func newTestGangway(objects ...runtime.Object) *Gangway {
+	ca := &config.Agent{}
+	ca.Set(&config.Config{
+		ProwConfig: config.ProwConfig{
+			Gangway: config.Gangway{
+				AllowedApiClients: []config.AllowedApiClient{
+					{
+						GCP: &config.ApiClientGcp{
+							EndpointApiConsumerType:   "PROJECT",
+							EndpointApiConsumerNumber: "123",
+						},
+						AllowedJobsFilters: []config.AllowedJobsFilter{{TenantID: "tenant"}},
+					},
+				},
+			},
+		},
+	})
+
+	return &Gangway{
+		ConfigAgent:   ca,
+		ProwJobClient: prowfake.NewSimpleClientset(objects...).ProwV1().ProwJobs("prowjobs"),
+	}
+}
+
+func newTestContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		HEADER_API_CONSUMER_TYPE, "PROJECT",
+		HEADER_API_CONSUMER_ID, "123",
+	))
+}
+
+func TestListJobExecutions(t *testing.T) {
+	base := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	pj := func(name, job, tenant string, jobType prowcrd.ProwJobType, state prowcrd.ProwJobState, minute int, refs *prowcrd.Refs) runtime.Object {
+		return &prowcrd.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+			Spec: prowcrd.ProwJobSpec{
+				Job:            job,
+				Type:           jobType,
+				Refs:           refs,
+				ProwJobDefault: &prowcrd.ProwJobDefault{TenantID: tenant},
+			},
+			Status: prowcrd.ProwJobStatus{
+				State:     state,
+				StartTime: metav1.NewTime(base.Add(time.Duration(minute) * time.Minute)),
+			},
+		}
+	}
+	refs := func(org, repo string, pulls ...int) *prowcrd.Refs {
+		r := &prowcrd.Refs{Org: org, Repo: repo, BaseRef: "main", BaseSHA: "abc"}
+		for _, n := range pulls {
+			r.Pulls = append(r.Pulls, prowcrd.Pull{Number: n})
+		}
+		return r
+	}
+	gw := newTestGangway(
+		pj("a", "periodic-job", "tenant", prowcrd.PeriodicJob, prowcrd.SuccessState, 1, nil),
+		pj("b", "presubmit-job", "tenant", prowcrd.PresubmitJob, prowcrd.FailureState, 2, refs("org", "repo", 1)),
+		pj("c", "presubmit-job", "tenant", prowcrd.PresubmitJob, prowcrd.PendingState, 3, refs("org", "repo", 2)),
+		pj("d", "postsubmit-job", "tenant", prowcrd.PostsubmitJob, prowcrd.SuccessState, 3, refs("org", "other")),
+		pj("e", "periodic-job", "other-tenant", prowcrd.PeriodicJob, prowcrd.SuccessState, 4, nil),
+	)
+
+	tests := []struct {
+		name     string
+		req      *ListJobExecutionsRequest
+		wantIDs  []string
+		wantCode codes.Code
+	}{
+		{
+			name:    "all authorized executions, most recent first",
+			req:     &ListJobExecutionsRequest{},
+			wantIDs: []string{"c", "d", "b", "a"},
+		},
+		{
+			name:    "by job name and status",
+			req:     &ListJobExecutionsRequest{JobName: "presubmit-job", Status: JobExecutionStatus_FAILURE},
+			wantIDs: []string{"b"},
+		},
+		{
+			name:    "by job type",
+			req:     &ListJobExecutionsRequest{JobType: JobExecutionType_PERIODIC},
+			wantIDs: []string{"a"},
+		},
+		{
+			name:    "by refs",
+			req:     &ListJobExecutionsRequest{Org: "org", Repo: "repo", PullNumber: 2},
+			wantIDs: []string{"c"},
+		},
+		{
+			name: "by creation time range",
+			req: &ListJobExecutionsRequest{
+				CreatedAfter:  timestamppb.New(base.Add(2 * time.Minute)),
+				CreatedBefore: timestamppb.New(base.Add(3 * time.Minute)),
+			},
+			wantIDs: []string{"b"},
+		},
+		{
+			name:     "negative page size",
+			req:      &ListJobExecutionsRequest{PageSize: -1},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid page token",
+			req:      &ListJobExecutionsRequest{PageToken: "???"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gw.ListJobExecutions(newTestContext(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("ListJobExecutions() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			var gotIDs []string
+			for _, je := range got.JobExecution {
+				gotIDs = append(gotIDs, je.Id)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ListJobExecutions() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		var gotIDs []string
+		req := &ListJobExecutionsRequest{PageSize: 3}
+		for pages := 0; ; pages++ {
+			if pages > 2 {
+				t.Fatal("too many pages")
+			}
+			got, err := gw.ListJobExecutions(newTestContext(), req)
+			if err != nil {
+				t.Fatalf("ListJobExecutions() error = %v", err)
+			}
+			for _, je := range got.JobExecution {
+				gotIDs = append(gotIDs, je.Id)
+			}
+			if got.NextPageToken == "" {
+				break
+			}
+			req.PageToken = got.NextPageToken
+		}
+		if diff := cmp.Diff([]string{"c", "d", "b", "a"}, gotIDs); diff != "" {
+			t.Errorf("paginated ListJobExecutions() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestListJobExecutionsFromCache(t *testing.T) {
+	pj := func(name string) *prowcrd.ProwJob {
+		return &prowcrd.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+			Spec:       prowcrd.ProwJobSpec{Job: "job", ProwJobDefault: &prowcrd.ProwJobDefault{TenantID: "tenant"}},
+		}
+	}
+	jobs := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := jobs.Add(pj("cached")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		synced  bool
+		wantIDs []string
+	}{
+		{name: "synced cache", synced: true, wantIDs: []string{"cached"}},
+		{name: "cache not synced yet", wantIDs: []string{"listed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newTestGangway(pj("listed"))
+			gw.JobWatcher = &JobWatcher{
+				subscribers: map[int]*subscriber{},
+				jobs:        jobs,
+				hasSynced:   func() bool { return tt.synced },
+			}
+			got, err := gw.ListJobExecutions(newTestContext(), &ListJobExecutionsRequest{})
+			if err != nil {
+				t.Fatalf("ListJobExecutions() error = %v", err)
+			}
+
+			var gotIDs []string
+			for _, je := range got.JobExecution {
+				gotIDs = append(gotIDs, je.Id)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ListJobExecutions() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAbortJobExecution(t *testing.T) {
+	pj := func(name, tenant string, state prowcrd.ProwJobState) runtime.Object {
+		return &prowcrd.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+			Spec:       prowcrd.ProwJobSpec{Job: "job", ProwJobDefault: &prowcrd.ProwJobDefault{TenantID: tenant}},
+			Status:     prowcrd.ProwJobStatus{State: state},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode codes.Code
+	}{
+		{name: "pending job", id: "pending"},
+		{name: "completed job", id: "completed", wantCode: codes.FailedPrecondition},
+		{name: "job of another tenant", id: "other-tenant", wantCode: codes.PermissionDenied},
+		{name: "missing job", id: "missing", wantCode: codes.NotFound},
+		{name: "empty id", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newTestGangway(
+				pj("pending", "tenant", prowcrd.PendingState),
+				pj("completed", "tenant", prowcrd.SuccessState),
+				pj("other-tenant", "other-tenant", prowcrd.PendingState),
+			)
+			_, err := gw.AbortJobExecution(newTestContext(), &AbortJobExecutionRequest{Id: tt.id})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("AbortJobExecution() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := gw.ProwJobClient.Get(context.Background(), tt.id, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.State != prowcrd.AbortedState {
+				t.Errorf("state = %q, want %q", got.Status.State, prowcrd.AbortedState)
+			}
+			if got.Annotations[AbortedByAnnotation] != "gcp-PROJECT-123" {
+				t.Errorf("annotations = %v, want the requester in %s", got.Annotations, AbortedByAnnotation)
+			}
+		})
+	}
+}
+
+func TestRerunJobExecution(t *testing.T) {
+	gw := newTestGangway(&prowcrd.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "original",
+			Namespace:   "prowjobs",
+			Annotations: map[string]string{AbortedByAnnotation: "gcp-PROJECT-123", "foo": "bar"},
+		},
+		Spec: prowcrd.ProwJobSpec{
+			Job:            "job",
+			Type:           prowcrd.PeriodicJob,
+			ProwJobDefault: &prowcrd.ProwJobDefault{TenantID: "tenant"},
+		},
+		Status: prowcrd.ProwJobStatus{State: prowcrd.AbortedState},
+	})
+
+	jobExec, err := gw.RerunJobExecution(newTestContext(), &RerunJobExecutionRequest{Id: "original"})
+	if err != nil {
+		t.Fatalf("RerunJobExecution() error = %v", err)
+	}
+	if jobExec.Id == "original" || jobExec.JobStatus != JobExecutionStatus_TRIGGERED {
+		t.Errorf("RerunJobExecution() = %v, want a new triggered execution", jobExec)
+	}
+
+	got, err := gw.ProwJobClient.Get(context.Background(), jobExec.Id, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.Job != "job" {
+		t.Errorf("job = %q, want %q", got.Spec.Job, "job")
+	}
+	for k, want := range map[string]string{
+		RerunByAnnotation:   "gcp-PROJECT-123",
+		RerunOfAnnotation:   "original",
+		AbortedByAnnotation: "",
+		"foo":               "bar",
+	} {
+		if got.Annotations[k] != want {
+			t.Errorf("annotation %s = %q, want %q", k, got.Annotations[k], want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gangway
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	prowcrd "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// watchBufferSize is the number of updates buffered for each watch stream.
+// Streams that fall further behind are closed, so that a slow client cannot
+// hold up the informer.
+const watchBufferSize = 100
+
+// JobWatcher fans out the changes of the Prow Job CRs observed by an informer
+// to the WatchJobExecutions streams, and serves ListJobExecutions from the
+// cache of the informer.
+type JobWatcher struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]*subscriber
+
+	jobs      cache.Store
+	hasSynced func() bool
+}
+
+type subscriber struct {
+	filter  func(*prowcrd.ProwJob) bool
+	updates chan *JobExecutionUpdate
+}
+
+// NewJobWatcher creates a JobWatcher and registers it to the Prow Job
+// informer. The informer must be started separately.
+func NewJobWatcher(informer cache.SharedIndexInformer) *JobWatcher {
+	jw := &JobWatcher{
+		subscribers: map[int]*subscriber{},
+		jobs:        informer.GetStore(),
+		hasSynced:   informer.HasSynced,
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pj, ok := obj.(*prowcrd.ProwJob); ok {
+				jw.publish(nil, pj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPJ, ok := oldObj.(*prowcrd.ProwJob)
+			if !ok {
+				return
+			}
+			if newPJ, ok := newObj.(*prowcrd.ProwJob); ok {
+				jw.publish(oldPJ, newPJ)
+			}
+		},
+	})
+	return jw
+}
+
+// list returns the cached Prow Jobs matching the filter. It returns false if
+// the cache is not synced yet.
+func (jw *JobWatcher) list(filter func(*prowcrd.ProwJob) bool) ([]prowcrd.ProwJob, bool) {
+	if !jw.hasSynced() {
+		return nil, false
+	}
+	var res []prowcrd.ProwJob
+	for _, obj := range jw.jobs.List() {
+		if pj, ok := obj.(*prowcrd.ProwJob); ok && filter(pj) {
+			res = append(res, *pj)
+		}
+	}
+	return res, true
+}
+
+// publish sends an update to all the subscribers interested in the Prow Job,
+// if its status, URL or status description changed.
+func (jw *JobWatcher) publish(oldPJ, newPJ *prowcrd.ProwJob) {
+	if oldPJ != nil && oldPJ.Status.State == newPJ.Status.State &&
+		oldPJ.Status.URL == newPJ.Status.URL &&
+		oldPJ.Status.Description == newPJ.Status.Description {
+		return
+	}
+
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	var update *JobExecutionUpdate
+	for id, sub := range jw.subscribers {
+		if !sub.filter(newPJ) {
+			continue
+		}
+		if update == nil {
+			update = toJobExecutionUpdate(oldPJ, newPJ)
+		}
+		select {
+		case sub.updates <- update:
+		default:
+			logrus.WithField("subscriber", id).Warn("Closing the job execution watch that fell behind.")
+			delete(jw.subscribers, id)
+			close(sub.updates)
+		}
+	}
+}
+
+// subscribe registers a subscriber for the Prow Jobs matching the filter. The
+// returned channel is closed if the subscriber falls behind, and the returned
+// function must be called to unsubscribe.
+func (jw *JobWatcher) subscribe(filter func(*prowcrd.ProwJob) bool) (<-chan *JobExecutionUpdate, func()) {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	id := jw.nextID
+	jw.nextID++
+	sub := &subscriber{filter: filter, updates: make(chan *JobExecutionUpdate, watchBufferSize)}
+	jw.subscribers[id] = sub
+
+	return sub.updates, func() {
+		jw.mu.Lock()
+		defer jw.mu.Unlock()
+		if _, ok := jw.subscribers[id]; ok {
+			delete(jw.subscribers, id)
+			close(sub.updates)
+		}
+	}
+}
+
+func toJobExecutionUpdate(oldPJ, newPJ *prowcrd.ProwJob) *JobExecutionUpdate {
+	update := &JobExecutionUpdate{
+		JobExecution: ToJobExecution(newPJ),
+		Url:          newPJ.Status.URL,
+		Description:  newPJ.Status.Description,
+	}
+	if oldPJ != nil {
+		update.PreviousStatus = toJobExecutionStatus(oldPJ.Status.State)
+	}
+	return update
+}
+
+// WatchJobExecutions streams the status updates of a single Prow job execution
+// or of all the executions matching the filters of the request.
+func (gw *Gangway) WatchJobExecutions(wjer *WatchJobExecutionsRequest, stream Prow_WatchJobExecutionsServer) error {
+	ctx := stream.Context()
+	err, md := getHttpRequestHeaders(ctx)
+	if err != nil {
+		logrus.WithError(err).Debug("could not find request HTTP headers")
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := wjer.Validate(); err != nil {
+		logrus.WithError(err).Debug("could not validate request fields")
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	mainConfig := gw.ConfigAgent.Config()
+	allowedApiClient, err := mainConfig.IdentifyAllowedClient(md)
+	if err != nil {
+		logrus.WithError(err).Debug("could not find client in allowlist")
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if gw.JobWatcher == nil {
+		return status.Error(codes.Unimplemented, "watching job executions is not enabled")
+	}
+
+	filter := wjer.toListRequest()
+	updates, unsubscribe := gw.JobWatcher.subscribe(func(pj *prowcrd.ProwJob) bool {
+		if wjer.GetId() != "" && pj.Name != wjer.GetId() {
+			return false
+		}
+		return ClientAuthorized(allowedApiClient, *pj) && filter.matches(pj)
+	})
+	defer unsubscribe()
+
+	// Subscribe before reading the current status, so that no status
+	// transition can be missed in between.
+	if wjer.GetId() != "" {
+		pj, err := gw.ProwJobClient.Get(ctx, wjer.GetId(), metav1.GetOptions{})
+		if err != nil {
+			logrus.WithError(err).Debugf("could not find prow job %s", wjer.GetId())
+			return status.Error(codes.NotFound, "Prow Job not found")
+		}
+		if !ClientAuthorized(allowedApiClient, *pj) {
+			return status.Error(codes.PermissionDenied, "client is not authorized to watch this Prow Job")
+		}
+		if !filter.matches(pj) {
+			return nil
+		}
+		if err := stream.Send(toJobExecutionUpdate(nil, pj)); err != nil {
+			return err
+		}
+		if pj.Complete() {
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "the client fell behind the job execution updates")
+			}
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+			if wjer.GetId() != "" && update.GetJobExecution().GetCompletionTime() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (wjer *WatchJobExecutionsRequest) Validate() error {
+	if wjer.GetPullNumber() < 0 {
+		return fmt.Errorf("pull_number cannot be negative: %d", wjer.GetPullNumber())
+	}
+
+	return nil
+}
+
+// toListRequest converts the filters into a ListJobExecutionsRequest, to match
+// the Prow Jobs in the same way as ListJobExecutions.
+func (wjer *WatchJobExecutionsRequest) toListRequest() *ListJobExecutionsRequest {
+	return &ListJobExecutionsRequest{
+		JobName:    wjer.GetJobName(),
+		JobType:    wjer.GetJobType(),
+		Org:        wjer.GetOrg(),
+		Repo:       wjer.GetRepo(),
+		BaseRef:    wjer.GetBaseRef(),
+		BaseSha:    wjer.GetBaseSha(),
+		PullNumber: wjer.GetPullNumber(),
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gangway
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowcrd "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+func TestJobWatcher(t *testing.T) {
+	pj := func(name string, state prowcrd.ProwJobState, url string) *prowcrd.ProwJob {
+		return &prowcrd.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       prowcrd.ProwJobSpec{Job: "job-" + name},
+			Status:     prowcrd.ProwJobStatus{State: state, URL: url, Description: string(state)},
+		}
+	}
+
+	jw := &JobWatcher{subscribers: map[int]*subscriber{}}
+	updates, unsubscribe := jw.subscribe(func(pj *prowcrd.ProwJob) bool { return pj.Name == "a" })
+
+	jw.publish(nil, pj("a", prowcrd.TriggeredState, ""))
+	jw.publish(nil, pj("b", prowcrd.TriggeredState, ""))
+	// Nothing visible changed.
+	jw.publish(pj("a", prowcrd.TriggeredState, ""), pj("a", prowcrd.TriggeredState, ""))
+	jw.publish(pj("a", prowcrd.TriggeredState, ""), pj("a", prowcrd.PendingState, ""))
+	jw.publish(pj("a", prowcrd.PendingState, ""), pj("a", prowcrd.PendingState, "https://prow/a"))
+	unsubscribe()
+	// Unsubscribing twice is harmless.
+	unsubscribe()
+	jw.publish(pj("a", prowcrd.PendingState, "https://prow/a"), pj("a", prowcrd.SuccessState, "https://prow/a"))
+
+	type update struct {
+		status, previous JobExecutionStatus
+		url, description string
+	}
+	var got []update
+	for u := range updates {
+		got = append(got, update{
+			status:      u.GetJobExecution().GetJobStatus(),
+			previous:    u.GetPreviousStatus(),
+			url:         u.GetUrl(),
+			description: u.GetDescription(),
+		})
+	}
+	want := []update{
+		{status: JobExecutionStatus_TRIGGERED, description: "triggered"},
+		{status: JobExecutionStatus_PENDING, previous: JobExecutionStatus_TRIGGERED, description: "pending"},
+		{status: JobExecutionStatus_PENDING, previous: JobExecutionStatus_PENDING, url: "https://prow/a", description: "pending"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(update{})); diff != "" {
+		t.Errorf("updates mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJobWatcherClosesSlowSubscribers(t *testing.T) {
+	jw := &JobWatcher{subscribers: map[int]*subscriber{}}
+	updates, unsubscribe := jw.subscribe(func(*prowcrd.ProwJob) bool { return true })
+	defer unsubscribe()
+
+	for i := 0; i <= watchBufferSize; i++ {
+		jw.publish(nil, &prowcrd.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	}
+
+	received := 0
+	for range updates {
+		received++
+	}
+	if received != watchBufferSize {
+		t.Errorf("received %d updates, want %d", received, watchBufferSize)
+	}
+	if len(jw.subscribers) != 0 {
+		t.Errorf("slow subscriber was not removed")
+	}
+}
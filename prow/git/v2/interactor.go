@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -59,6 +60,9 @@ type Interactor interface {
 	MergeAndCheckout(baseSHA string, mergeStrategy string, headSHAs ...string) error
 	// Am calls `git am`
 	Am(path string) error
+	// CherryPick calls `git cherry-pick -x`, relative to the mainline parent if it is set, committing
+	// any conflicts with their markers
+	CherryPick(commitlike string, mainline int) (conflicts []string, err error)
 	// Fetch calls `git fetch arg...`
 	Fetch(arg ...string) error
 	// FetchRef fetches the refspec
@@ -431,6 +435,77 @@ func (i *interactor) Am(path string) error {
 	return errors.New(string(bytes.TrimPrefix(out, []byte("The copy of the patch that failed is found in: .git/rebase-apply/patch"))))
 }
 
+// CherryPick runs `git cherry-pick -x` of the commitlike, relative to the
+// mainline parent if it is set for merge commits. If the commit does not
+// apply cleanly, the conflicting files are committed with their conflict
+// markers so that the conflicts can be resolved later, and they are returned.
+// The commit is skipped if its changes are already on the branch.
+func (i *interactor) CherryPick(commitlike string, mainline int) ([]string, error) {
+	i.logger.Infof("Cherry-picking %q", commitlike)
+	args := []string{"cherry-pick", "-x"}
+	if mainline > 0 {
+		args = append(args, "-m", strconv.Itoa(mainline))
+	}
+	out, err := i.executor.Run(append(args, commitlike)...)
+	if err == nil {
+		return nil, nil
+	}
+	i.logger.WithError(err).Infof("Cherry-pick failed with output: %s", string(out))
+	conflicts, err := i.unmergedFiles()
+	if err == nil {
+		if len(conflicts) > 0 {
+			err = i.commitConflicts()
+		} else {
+			err = i.skipEmptyCherryPick()
+		}
+	}
+	if err != nil {
+		if abortOut, abortErr := i.executor.Run("cherry-pick", "--abort"); abortErr != nil {
+			i.logger.WithError(abortErr).Warningf("Aborting cherry-pick failed with output: %s", string(abortOut))
+		}
+		return nil, fmt.Errorf("error cherry-picking %q: %w %v", commitlike, err, string(out))
+	}
+	return conflicts, nil
+}
+
+// unmergedFiles returns the files with conflicts.
+func (i *interactor) unmergedFiles() ([]string, error) {
+	out, err := i.executor.Run("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, fmt.Errorf("error listing conflicts: %w %v", err, string(out))
+	}
+	var conflicts []string
+	scan := bufio.NewScanner(bytes.NewReader(out))
+	scan.Split(bufio.ScanLines)
+	for scan.Scan() {
+		conflicts = append(conflicts, scan.Text())
+	}
+	return conflicts, nil
+}
+
+// commitConflicts commits the conflicts of an ongoing cherry-pick.
+func (i *interactor) commitConflicts() error {
+	if out, err := i.executor.Run("add", "--all"); err != nil {
+		return fmt.Errorf("error adding conflicts: %w %v", err, string(out))
+	}
+	if out, err := i.executor.Run("-c", "core.editor=true", "cherry-pick", "--continue"); err != nil {
+		return fmt.Errorf("error committing conflicts: %w %v", err, string(out))
+	}
+	return nil
+}
+
+// skipEmptyCherryPick skips an ongoing cherry-pick that has no changes to
+// commit, as they are already on the branch.
+func (i *interactor) skipEmptyCherryPick() error {
+	if _, err := i.executor.Run("diff", "--cached", "--quiet"); err != nil {
+		return errors.New("no conflicts to commit")
+	}
+	if out, err := i.executor.Run("cherry-pick", "--skip"); err != nil {
+		return fmt.Errorf("error skipping empty cherry-pick: %w %v", err, string(out))
+	}
+	return nil
+}
+
 // FetchCommits only fetches those commits which we want, and only if they are
 // missing.
 func (i *interactor) FetchCommits(commitSHAs []string) error {
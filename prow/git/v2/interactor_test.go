@@ -1193,6 +1193,177 @@ func TestInteractor_Am(t *testing.T) {
 	}
 }
 
+func TestInteractor_CherryPick(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		commitlike        string
+		mainline          int
+		responses         map[string]execResponse
+		expectedCalls     [][]string
+		expectedConflicts []string
+		expectedErr       bool
+	}{
+		{
+			name:       "happy case",
+			commitlike: "shasum",
+			responses: map[string]execResponse{
+				"cherry-pick -x shasum": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"cherry-pick", "-x", "shasum"},
+			},
+			expectedErr: false,
+		},
+		{
+			name:       "conflicts are committed",
+			commitlike: "shasum",
+			responses: map[string]execResponse{
+				"cherry-pick -x shasum": {
+					err: errors.New("oops"),
+				},
+				"diff --name-only --diff-filter=U": {
+					out: []byte("bar.go\nfoo/baz.go\n"),
+				},
+				"add --all": {
+					out: []byte(`ok`),
+				},
+				"-c core.editor=true cherry-pick --continue": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"cherry-pick", "-x", "shasum"},
+				{"diff", "--name-only", "--diff-filter=U"},
+				{"add", "--all"},
+				{"-c", "core.editor=true", "cherry-pick", "--continue"},
+			},
+			expectedConflicts: []string{"bar.go", "foo/baz.go"},
+			expectedErr:       false,
+		},
+		{
+			name:       "merge commit is picked relative to its mainline",
+			commitlike: "shasum",
+			mainline:   1,
+			responses: map[string]execResponse{
+				"cherry-pick -x -m 1 shasum": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"cherry-pick", "-x", "-m", "1", "shasum"},
+			},
+			expectedErr: false,
+		},
+		{
+			name:       "empty cherry-pick is skipped",
+			commitlike: "shasum",
+			responses: map[string]execResponse{
+				"cherry-pick -x shasum": {
+					err: errors.New("oops"),
+				},
+				"diff --name-only --diff-filter=U": {
+					out: []byte(``),
+				},
+				"diff --cached --quiet": {
+					out: []byte(``),
+				},
+				"cherry-pick --skip": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"cherry-pick", "-x", "shasum"},
+				{"diff", "--name-only", "--diff-filter=U"},
+				{"diff", "--cached", "--quiet"},
+				{"cherry-pick", "--skip"},
+			},
+			expectedErr: false,
+		},
+		{
+			name:       "cherry-pick fails without conflicts",
+			commitlike: "shasum",
+			responses: map[string]execResponse{
+				"cherry-pick -x shasum": {
+					err: errors.New("oops"),
+				},
+				"diff --name-only --diff-filter=U": {
+					out: []byte(``),
+				},
+				"diff --cached --quiet": {
+					err: errors.New("oops"),
+				},
+				"cherry-pick --abort": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"cherry-pick", "-x", "shasum"},
+				{"diff", "--name-only", "--diff-filter=U"},
+				{"diff", "--cached", "--quiet"},
+				{"cherry-pick", "--abort"},
+			},
+			expectedErr: true,
+		},
+		{
+			name:       "committing conflicts fails",
+			commitlike: "shasum",
+			responses: map[string]execResponse{
+				"cherry-pick -x shasum": {
+					err: errors.New("oops"),
+				},
+				"diff --name-only --diff-filter=U": {
+					out: []byte("bar.go\n"),
+				},
+				"add --all": {
+					out: []byte(`ok`),
+				},
+				"-c core.editor=true cherry-pick --continue": {
+					err: errors.New("oops"),
+				},
+				"cherry-pick --abort": {
+					out: []byte(`ok`),
+				},
+			},
+			expectedCalls: [][]string{
+				{"cherry-pick", "-x", "shasum"},
+				{"diff", "--name-only", "--diff-filter=U"},
+				{"add", "--all"},
+				{"-c", "core.editor=true", "cherry-pick", "--continue"},
+				{"cherry-pick", "--abort"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			e := fakeExecutor{
+				records:   [][]string{},
+				responses: testCase.responses,
+			}
+			i := interactor{
+				executor: &e,
+				logger:   logrus.WithField("test", testCase.name),
+			}
+			actualConflicts, actualErr := i.CherryPick(testCase.commitlike, testCase.mainline)
+			if testCase.expectedErr && actualErr == nil {
+				t.Errorf("%s: expected an error but got none", testCase.name)
+			}
+			if !testCase.expectedErr && actualErr != nil {
+				t.Errorf("%s: expected no error but got one: %v", testCase.name, actualErr)
+			}
+			if actual, expected := actualConflicts, testCase.expectedConflicts; !reflect.DeepEqual(actual, expected) {
+				t.Errorf("%s: got incorrect conflicts: %v", testCase.name, diff.ObjectReflectDiff(actual, expected))
+			}
+			if actual, expected := e.records, testCase.expectedCalls; !reflect.DeepEqual(actual, expected) {
+				t.Errorf("%s: got incorrect git calls: %v", testCase.name, diff.ObjectReflectDiff(actual, expected))
+			}
+		})
+	}
+}
+
 func TestInteractor_RemoteUpdate(t *testing.T) {
 	var testCases = []struct {
 		name          string
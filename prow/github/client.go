@@ -129,7 +129,9 @@ type PullRequestClient interface {
 	EditPullRequest(org, repo string, number int, pr *PullRequest) (*PullRequest, error)
 	GetPullRequestDiff(org, repo string, number int) ([]byte, error)
 	GetPullRequestPatch(org, repo string, number int) ([]byte, error)
+	GetCompareDiff(org, repo, base, head string) ([]byte, error)
 	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error)
+	CreateDraftPullRequest(org, repo, title, body, head, base string, canModify bool) (int, error)
 	UpdatePullRequest(org, repo string, number int, title, body *string, open *bool, branch *string, canModify *bool) error
 	GetPullRequestChanges(org, repo string, number int) ([]PullRequestChange, error)
 	ListPullRequestComments(org, repo string, number int) ([]ReviewComment, error)
@@ -138,6 +140,7 @@ type PullRequestClient interface {
 	ClosePullRequest(org, repo string, number int) error
 	ReopenPullRequest(org, repo string, number int) error
 	CreateReview(org, repo string, number int, r DraftReview) error
+	UpdateReview(org, repo string, number, reviewID int, body string) error
 	RequestReview(org, repo string, number int, logins []string) error
 	UnrequestReview(org, repo string, number int, logins []string) error
 	Merge(org, repo string, pr int, details MergeDetails) error
@@ -158,6 +161,7 @@ type CommitClient interface {
 	DeleteRef(org, repo, ref string) error
 	ListFileCommits(org, repo, path string) ([]RepositoryCommit, error)
 	CreateCheckRun(org, repo string, checkRun CheckRun) error
+	UpdateCheckRun(org, repo string, id int64, checkRun CheckRun) error
 }
 
 // RepositoryClient interface for repository related API actions
@@ -2233,6 +2237,23 @@ func (c *client) GetPullRequestPatch(org, repo string, number int) ([]byte, erro
 	return patch, err
 }
 
+// GetCompareDiff gets the diff version of the comparison between two commits.
+//
+// See https://docs.github.com/en/rest/commits/commits#compare-two-commits
+func (c *client) GetCompareDiff(org, repo, base, head string) ([]byte, error) {
+	durationLogger := c.log("GetCompareDiff", org, repo, base, head)
+	defer durationLogger()
+
+	_, diff, err := c.requestRaw(&request{
+		accept:    "application/vnd.github.diff",
+		method:    http.MethodGet,
+		path:      fmt.Sprintf("/repos/%s/%s/compare/%s...%s", org, repo, base, head),
+		org:       org,
+		exitCodes: []int{200},
+	})
+	return diff, err
+}
+
 // CreatePullRequest creates a new pull request and returns its number if
 // the creation is successful, otherwise any error that is encountered.
 //
@@ -2241,6 +2262,21 @@ func (c *client) CreatePullRequest(org, repo, title, body, head, base string, ca
 	durationLogger := c.log("CreatePullRequest", org, repo, title)
 	defer durationLogger()
 
+	return c.createPullRequest(org, repo, title, body, head, base, canModify, false)
+}
+
+// CreateDraftPullRequest creates a new draft pull request and returns its
+// number if the creation is successful, otherwise any error that is encountered.
+//
+// See https://developer.github.com/v3/pulls/#create-a-pull-request
+func (c *client) CreateDraftPullRequest(org, repo, title, body, head, base string, canModify bool) (int, error) {
+	durationLogger := c.log("CreateDraftPullRequest", org, repo, title)
+	defer durationLogger()
+
+	return c.createPullRequest(org, repo, title, body, head, base, canModify, true)
+}
+
+func (c *client) createPullRequest(org, repo, title, body, head, base string, canModify, draft bool) (int, error) {
 	data := struct {
 		Title string `json:"title"`
 		Body  string `json:"body"`
@@ -2249,6 +2285,7 @@ func (c *client) CreatePullRequest(org, repo, title, body, head, base string, ca
 		// MaintainerCanModify allows maintainers of the repo to modify this
 		// pull request, eg. push changes to it before merging.
 		MaintainerCanModify bool `json:"maintainer_can_modify"`
+		Draft               bool `json:"draft,omitempty"`
 	}{
 		Title: title,
 		Body:  body,
@@ -2256,6 +2293,7 @@ func (c *client) CreatePullRequest(org, repo, title, body, head, base string, ca
 		Base:  base,
 
 		MaintainerCanModify: canModify,
+		Draft:               draft,
 	}
 	var resp struct {
 		Num int `json:"number"`
@@ -3029,6 +3067,23 @@ func (c *client) CreateReview(org, repo string, number int, r DraftReview) error
 	return err
 }
 
+// UpdateReview changes the body of a review.
+//
+// https://docs.github.com/en/rest/pulls/reviews#update-a-review-for-a-pull-request
+func (c *client) UpdateReview(org, repo string, number, reviewID int, body string) error {
+	durationLogger := c.log("UpdateReview", org, repo, number, reviewID, body)
+	defer durationLogger()
+
+	_, err := c.request(&request{
+		method:      http.MethodPut,
+		path:        fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews/%d", org, repo, number, reviewID),
+		org:         org,
+		requestBody: map[string]string{"body": body},
+		exitCodes:   []int{200},
+	}, nil)
+	return err
+}
+
 // prepareReviewersBody separates reviewers from team_reviewers and prepares a map
 //
 //	{
@@ -4906,6 +4961,22 @@ func (c *client) CreateCheckRun(org, repo string, checkRun CheckRun) error {
 	return nil
 }
 
+// UpdateCheckRun updates the check run with the given ID.
+//
+// See https://docs.github.com/en/rest/checks/runs#update-a-check-run
+func (c *client) UpdateCheckRun(org, repo string, id int64, checkRun CheckRun) error {
+	durationLogger := c.log("UpdateCheckRun", org, repo, id, checkRun)
+	defer durationLogger()
+	_, err := c.request(&request{
+		method:      http.MethodPatch,
+		path:        fmt.Sprintf("/repos/%s/%s/check-runs/%d", org, repo, id),
+		org:         org,
+		requestBody: &checkRun,
+		exitCodes:   []int{200},
+	}, nil)
+	return err
+}
+
 // Simple function to check if GitHub App Authentication is being used
 func (c *client) UsesAppAuth() bool {
 	return c.delegate.usesAppsAuth
@@ -1334,6 +1334,33 @@ func TestListReviews(t *testing.T) {
 	}
 }
 
+func TestUpdateReview(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/k8s/kuber/pulls/15/reviews/3" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("Could not read request body: %v", err)
+		}
+		var review Review
+		if err := json.Unmarshal(b, &review); err != nil {
+			t.Errorf("Could not unmarshal request: %v", err)
+		} else if review.Body != "updated" {
+			t.Errorf("Wrong body: %s", review.Body)
+		}
+		http.Error(w, "200 OK", http.StatusOK)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	if err := c.UpdateReview("k8s", "kuber", 15, 3, "updated"); err != nil {
+		t.Errorf("Didn't expect error: %v", err)
+	}
+}
+
 func TestPrepareReviewersBody(t *testing.T) {
 	var tests = []struct {
 		name         string
@@ -3413,6 +3440,37 @@ func TestCreatePullRequestReviewComment(t *testing.T) {
 	}
 }
 
+func TestCreateDraftPullRequest(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/k8s/kuber/pulls" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("Could not read request body: %v", err)
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(b, &data); err != nil {
+			t.Errorf("Could not unmarshal request: %v", err)
+		} else if data["draft"] != true || data["head"] != "bot:branch" || data["base"] != "release" {
+			t.Errorf("Wrong request: %s", string(b))
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 42}`)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	num, err := c.CreateDraftPullRequest("k8s", "kuber", "title", "body", "bot:branch", "release", true)
+	if err != nil {
+		t.Errorf("Didn't expect error: %v", err)
+	} else if num != 42 {
+		t.Errorf("Expected pull request 42, got %d", num)
+	}
+}
+
 func TestThrottlerRespectsContexts(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -3485,6 +3543,35 @@ func TestCreateCheckRun(t *testing.T) {
 	}
 }
 
+func TestUpdateCheckRun(t *testing.T) {
+	checkRun := CheckRun{
+		Name:       "foo",
+		Status:     "completed",
+		Conclusion: "failure",
+		Actions:    []CheckRunAction{{Label: "Re-run", Description: "Re-run the job", Identifier: "rerun"}},
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/k8s/kuber/check-runs/42" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		var cr CheckRun
+		if err := json.NewDecoder(r.Body).Decode(&cr); err != nil {
+			t.Errorf("Could not unmarshal request: %v", err)
+		} else if diff := cmp.Diff(checkRun, cr); diff != "" {
+			t.Errorf("expected checkrun differs from actual: %s", diff)
+		}
+		http.Error(w, "200 OK", http.StatusOK)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	if err := c.UpdateCheckRun("k8s", "kuber", 42, checkRun); err != nil {
+		t.Errorf("Didn't expect error: %v", err)
+	}
+}
+
 func TestIsAppInstalled(t *testing.T) {
 	testCases := []struct {
 		name     string
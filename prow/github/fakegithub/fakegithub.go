@@ -320,6 +320,19 @@ func (f *FakeClient) CreateReview(org, repo string, number int, r github.DraftRe
 	return nil
 }
 
+// UpdateReview changes the body of a review.
+func (f *FakeClient) UpdateReview(org, repo string, number, reviewID int, body string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, r := range f.Reviews[number] {
+		if r.ID == reviewID {
+			f.Reviews[number][i].Body = body
+			return nil
+		}
+	}
+	return fmt.Errorf("review %d not found in %s/%s#%d", reviewID, org, repo, number)
+}
+
 // CreateCommentReaction adds emoji to a comment.
 func (f *FakeClient) CreateCommentReaction(org, repo string, ID int, reaction string) error {
 	f.lock.Lock()
@@ -1058,6 +1071,14 @@ func (f *FakeClient) GetTeamBySlug(slug string, org string) (*github.Team, error
 }
 
 func (f *FakeClient) CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error) {
+	return f.createPullRequest(org, repo, title, body, head, base, false)
+}
+
+func (f *FakeClient) CreateDraftPullRequest(org, repo, title, body, head, base string, canModify bool) (int, error) {
+	return f.createPullRequest(org, repo, title, body, head, base, true)
+}
+
+func (f *FakeClient) createPullRequest(org, repo, title, body, head, base string, draft bool) (int, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 	if f.PullRequests == nil {
@@ -1072,6 +1093,7 @@ func (f *FakeClient) CreatePullRequest(org, repo, title, body, head, base string
 		}
 		f.PullRequests[i] = &github.PullRequest{
 			Number: i,
+			Draft:  draft,
 			Base: github.PullRequestBranch{
 				Ref:  base,
 				Repo: github.Repo{Owner: github.User{Login: org}, Name: repo},
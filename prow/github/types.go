@@ -915,6 +915,40 @@ type StatusEvent struct {
 	GUID string
 }
 
+// CheckRunEventAction enumerates the triggers of a CheckRunEvent.
+type CheckRunEventAction string
+
+const (
+	// CheckRunActionCreated means the check run was created.
+	CheckRunActionCreated CheckRunEventAction = "created"
+	// CheckRunActionCompleted means the check run was completed.
+	CheckRunActionCompleted CheckRunEventAction = "completed"
+	// CheckRunActionRerequested means someone asked to re-run the check run
+	// from the GitHub UI.
+	CheckRunActionRerequested CheckRunEventAction = "rerequested"
+	// CheckRunActionRequestedAction means someone clicked one of the actions
+	// of the check run.
+	CheckRunActionRequestedAction CheckRunEventAction = "requested_action"
+)
+
+// CheckRunEvent is what GitHub sends us when a check run changes.
+type CheckRunEvent struct {
+	Action   CheckRunEventAction `json:"action"`
+	CheckRun CheckRun            `json:"check_run"`
+	// RequestedAction is only set for the requested_action action.
+	RequestedAction *CheckRunRequestedAction `json:"requested_action,omitempty"`
+	Repo            Repo                     `json:"repository"`
+	Sender          User                     `json:"sender"`
+
+	// GUID is included in the header of the request received by GitHub.
+	GUID string
+}
+
+// CheckRunRequestedAction is the action of a check run a user clicked on.
+type CheckRunRequestedAction struct {
+	Identifier string `json:"identifier"`
+}
+
 // IssuesSearchResult represents the result of an issues search.
 type IssuesSearchResult struct {
 	Total  int     `json:"total_count,omitempty"`
@@ -1057,6 +1091,8 @@ type ReviewComment struct {
 	HTMLURL   string    `json:"html_url"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// InReplyTo is the ID of the review comment replied to, 0 if it is not a reply.
+	InReplyTo int `json:"in_reply_to_id,omitempty"`
 	// Position will be nil if the code has changed such that the comment is no
 	// longer relevant.
 	Position  *int     `json:"position,omitempty"`
@@ -1439,6 +1475,21 @@ type CheckRun struct {
 	CheckSuite   CheckSuite     `json:"check_suite,omitempty"`
 	App          App            `json:"app,omitempty"`
 	PullRequests []PullRequest  `json:"pull_requests,omitempty"`
+	// Actions are the buttons displayed with the check run, which trigger
+	// a check_run event with the requested_action action when clicked.
+	Actions []CheckRunAction `json:"actions,omitempty"`
+}
+
+// CheckRunAction is a button the user can click on a check run.
+//
+// See https://docs.github.com/en/rest/guides/using-the-rest-api-to-interact-with-checks#check-runs-and-requested-actions
+type CheckRunAction struct {
+	// Label is the text displayed on the button, 20 characters at most.
+	Label string `json:"label"`
+	// Description is displayed when hovering the button, 40 characters at most.
+	Description string `json:"description"`
+	// Identifier is sent back in the check_run event, 20 characters at most.
+	Identifier string `json:"identifier"`
 }
 
 type CheckRunOutput struct {
@@ -0,0 +1,307 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitlab implements a minimal client for the GitLab REST API v4, with
+// the endpoints needed to merge merge requests.
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/version"
+)
+
+// perPage is the maximum page size allowed by GitLab.
+const perPage = 100
+
+// Client interacts with a GitLab instance. Projects are identified by their
+// full path, such as "group/subgroup/project".
+type Client interface {
+	// ListMergeRequests lists the merge requests of a project.
+	// https://docs.gitlab.com/ee/api/merge_requests.html#list-project-merge-requests
+	ListMergeRequests(project string, opts ListMergeRequestsOptions) ([]MergeRequest, error)
+	// GetMergeRequest gets a single merge request of a project.
+	GetMergeRequest(project string, iid int) (*MergeRequest, error)
+	// ListMergeRequestDiffs lists the files changed by a merge request.
+	ListMergeRequestDiffs(project string, iid int) ([]Diff, error)
+	// AcceptMergeRequest merges a merge request.
+	AcceptMergeRequest(project string, iid int, opts AcceptMergeRequestOptions) (*MergeRequest, error)
+	// CreateMergeRequestNote comments on a merge request.
+	CreateMergeRequestNote(project string, iid int, body string) error
+	// GetProject gets a project, including its merge settings.
+	GetProject(project string) (*Project, error)
+	// GetBranch gets a branch of a project, including its head commit.
+	GetBranch(project, branch string) (*Branch, error)
+	// ListCommitStatuses lists the statuses of a commit.
+	ListCommitStatuses(project, sha string) ([]CommitStatus, error)
+}
+
+type client struct {
+	logger *logrus.Entry
+	client *http.Client
+	// endpoint is the base URL of the API, such as
+	// https://gitlab.com/api/v4.
+	endpoint string
+	getToken func() []byte
+}
+
+// NewClient returns a client for the GitLab instance at the given URL, such
+// as https://gitlab.com. getToken returns the personal, group or project
+// access token used to authenticate; no authentication is done if it is nil.
+func NewClient(instanceURL string, getToken func() []byte) Client {
+	return &client{
+		logger:   logrus.WithField("client", "gitlab"),
+		client:   &http.Client{Timeout: time.Minute},
+		endpoint: strings.TrimSuffix(instanceURL, "/") + "/api/v4",
+		getToken: getToken,
+	}
+}
+
+func projectPath(project string) string {
+	return "/projects/" + url.PathEscape(project)
+}
+
+func (c *client) ListMergeRequests(project string, opts ListMergeRequestsOptions) ([]MergeRequest, error) {
+	values := url.Values{}
+	if opts.State != "" {
+		values.Set("state", opts.State)
+	}
+	if len(opts.Labels) > 0 {
+		values.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.Milestone != "" {
+		values.Set("milestone", opts.Milestone)
+	}
+	if opts.ExcludeDrafts {
+		values.Set("wip", "no")
+	}
+
+	var res []MergeRequest
+	err := c.list(projectPath(project)+"/merge_requests", values, func(raw []byte) error {
+		var page []MergeRequest
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		res = append(res, page...)
+		return nil
+	})
+	return res, err
+}
+
+func (c *client) GetMergeRequest(project string, iid int) (*MergeRequest, error) {
+	var mr MergeRequest
+	if err := c.do(http.MethodGet, projectPath(project)+"/merge_requests/"+strconv.Itoa(iid), nil, nil, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+func (c *client) ListMergeRequestDiffs(project string, iid int) ([]Diff, error) {
+	var res []Diff
+	err := c.list(projectPath(project)+"/merge_requests/"+strconv.Itoa(iid)+"/diffs", url.Values{}, func(raw []byte) error {
+		var page []Diff
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		res = append(res, page...)
+		return nil
+	})
+	return res, err
+}
+
+func (c *client) AcceptMergeRequest(project string, iid int, opts AcceptMergeRequestOptions) (*MergeRequest, error) {
+	var mr MergeRequest
+	if err := c.do(http.MethodPut, projectPath(project)+"/merge_requests/"+strconv.Itoa(iid)+"/merge", nil, opts, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+func (c *client) CreateMergeRequestNote(project string, iid int, body string) error {
+	note := struct {
+		Body string `json:"body"`
+	}{Body: body}
+	return c.do(http.MethodPost, projectPath(project)+"/merge_requests/"+strconv.Itoa(iid)+"/notes", nil, note, nil)
+}
+
+func (c *client) GetProject(project string) (*Project, error) {
+	var p Project
+	if err := c.do(http.MethodGet, projectPath(project), nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (c *client) GetBranch(project, branch string) (*Branch, error) {
+	var b Branch
+	if err := c.do(http.MethodGet, projectPath(project)+"/repository/branches/"+url.PathEscape(branch), nil, nil, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (c *client) ListCommitStatuses(project, sha string) ([]CommitStatus, error) {
+	var res []CommitStatus
+	// Without all=true only the latest status of each name is returned, which
+	// is what we want.
+	err := c.list(projectPath(project)+"/repository/commits/"+url.PathEscape(sha)+"/statuses", url.Values{}, func(raw []byte) error {
+		var page []CommitStatus
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		res = append(res, page...)
+		return nil
+	})
+	return res, err
+}
+
+// list follows the pagination of a list endpoint, calling handle with the
+// body of each page.
+func (c *client) list(path string, values url.Values, handle func([]byte) error) error {
+	values.Set("per_page", strconv.Itoa(perPage))
+	page := "1"
+	for page != "" {
+		values.Set("page", page)
+		resp, raw, err := c.request(http.MethodGet, path, values, nil)
+		if err != nil {
+			return err
+		}
+		if err := handle(raw); err != nil {
+			return fmt.Errorf("could not unmarshal response of %s: %w", path, err)
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return nil
+}
+
+// do sends a request and unmarshals the response body into out, if not nil.
+func (c *client) do(method, path string, values url.Values, body, out interface{}) error {
+	_, raw, err := c.request(method, path, values, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("could not unmarshal response of %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *client) request(method, path string, values url.Values, body interface{}) (*http.Response, []byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	u := c.endpoint + path
+	if len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.getToken != nil {
+		if token := c.getToken(); len(token) > 0 {
+			req.Header.Set("PRIVATE-TOKEN", string(token))
+		}
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+
+	logger := c.logger.WithFields(logrus.Fields{"method": method, "path": path})
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	logger.WithField("response", resp.StatusCode).Debug("Got response from GitLab.")
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, &requestError{statusCode: resp.StatusCode, message: errorMessage(raw)}
+	}
+	return resp, raw, nil
+}
+
+// errorMessage extracts the message of a GitLab error response, which can be
+// in either the "message" or the "error" field.
+func errorMessage(raw []byte) string {
+	var resp struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return string(raw)
+	}
+	switch {
+	case resp.Message != nil:
+		if s, ok := resp.Message.(string); ok {
+			return s
+		}
+		b, _ := json.Marshal(resp.Message)
+		return string(b)
+	case resp.Error != "":
+		return resp.Error
+	}
+	return string(raw)
+}
+
+type requestError struct {
+	statusCode int
+	message    string
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.statusCode, e.message)
+}
+
+// StatusCode returns the HTTP status code of a failed request, or 0 if the
+// error didn't come from the GitLab API.
+func StatusCode(err error) int {
+	var reqError *requestError
+	if errors.As(err, &reqError) {
+		return reqError.statusCode
+	}
+	return 0
+}
+
+// IsNotFound tells whether the requested object doesn't exist.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
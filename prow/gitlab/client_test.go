@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/test-infra/prow/gitlab"
+	"k8s.io/test-infra/prow/gitlab/fakegitlab"
+)
+
+func newTestServer() *fakegitlab.Server {
+	s := fakegitlab.NewServer()
+	s.Projects["group/sub/project"] = &gitlab.Project{
+		ID:                1,
+		PathWithNamespace: "group/sub/project",
+		MergeMethod:       gitlab.MergeMethodMerge,
+	}
+	s.MergeRequests["group/sub/project"] = []gitlab.MergeRequest{
+		{IID: 1, State: gitlab.MergeRequestStateOpened, SHA: "sha1", Labels: []string{"lgtm", "approved"}},
+		{IID: 2, State: gitlab.MergeRequestStateOpened, SHA: "sha2", Labels: []string{"lgtm"}},
+		{IID: 3, State: gitlab.MergeRequestStateOpened, SHA: "sha3", Labels: []string{"lgtm", "approved"}, Draft: true},
+		{IID: 4, State: gitlab.MergeRequestStateMerged, SHA: "sha4", Labels: []string{"lgtm", "approved"}},
+		{IID: 5, State: gitlab.MergeRequestStateOpened, SHA: "sha5", Labels: []string{"lgtm", "approved"}, Milestone: &gitlab.Milestone{Title: "v1"}},
+	}
+	s.Branches["group/sub/project"] = map[string]string{"main": "base", "release/1.0": "release"}
+	s.Statuses["sha1"] = []gitlab.CommitStatus{{Name: "build", Status: gitlab.CommitStatusSuccess}, {Name: "test", Status: gitlab.CommitStatusRunning}}
+	return s
+}
+
+func TestListMergeRequests(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+	// Make sure that the pagination is followed.
+	s.PageSize = 1
+	c := gitlab.NewClient(s.URL(), func() []byte { return []byte(fakegitlab.Token) })
+
+	tests := []struct {
+		name string
+		opts gitlab.ListMergeRequestsOptions
+		want []int
+	}{
+		{
+			name: "all",
+			want: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "opened with labels",
+			opts: gitlab.ListMergeRequestsOptions{State: gitlab.MergeRequestStateOpened, Labels: []string{"lgtm", "approved"}},
+			want: []int{1, 3, 5},
+		},
+		{
+			name: "without drafts",
+			opts: gitlab.ListMergeRequestsOptions{State: gitlab.MergeRequestStateOpened, Labels: []string{"approved"}, ExcludeDrafts: true},
+			want: []int{1, 5},
+		},
+		{
+			name: "by milestone",
+			opts: gitlab.ListMergeRequestsOptions{Milestone: "v1"},
+			want: []int{5},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mrs, err := c.ListMergeRequests("group/sub/project", tc.opts)
+			if err != nil {
+				t.Fatalf("ListMergeRequests() error = %v", err)
+			}
+			var got []int
+			for _, mr := range mrs {
+				got = append(got, mr.IID)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ListMergeRequests() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetBranch(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+	c := gitlab.NewClient(s.URL(), func() []byte { return []byte(fakegitlab.Token) })
+
+	// Branch names with slashes must be escaped.
+	b, err := c.GetBranch("group/sub/project", "release/1.0")
+	if err != nil {
+		t.Fatalf("GetBranch() error = %v", err)
+	}
+	if b.Commit.ID != "release" {
+		t.Errorf("GetBranch() commit = %q, want %q", b.Commit.ID, "release")
+	}
+
+	if _, err := c.GetBranch("group/sub/project", "missing"); !gitlab.IsNotFound(err) {
+		t.Errorf("GetBranch() error = %v, want a not found error", err)
+	}
+}
+
+func TestListCommitStatuses(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+	c := gitlab.NewClient(s.URL(), func() []byte { return []byte(fakegitlab.Token) })
+
+	got, err := c.ListCommitStatuses("group/sub/project", "sha1")
+	if err != nil {
+		t.Fatalf("ListCommitStatuses() error = %v", err)
+	}
+	if diff := cmp.Diff(s.Statuses["sha1"], got); diff != "" {
+		t.Errorf("ListCommitStatuses() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAcceptMergeRequest(t *testing.T) {
+	squash := true
+	tests := []struct {
+		name     string
+		iid      int
+		opts     gitlab.AcceptMergeRequestOptions
+		wantCode int
+	}{
+		{
+			name: "merged",
+			iid:  1,
+			opts: gitlab.AcceptMergeRequestOptions{SHA: "sha1", Squash: &squash},
+		},
+		{
+			name:     "head changed",
+			iid:      1,
+			opts:     gitlab.AcceptMergeRequestOptions{SHA: "other"},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "already merged",
+			iid:      4,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "missing",
+			iid:      42,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer()
+			defer s.Close()
+			c := gitlab.NewClient(s.URL(), func() []byte { return []byte(fakegitlab.Token) })
+
+			mr, err := c.AcceptMergeRequest("group/sub/project", tc.iid, tc.opts)
+			if code := gitlab.StatusCode(err); code != tc.wantCode {
+				t.Fatalf("AcceptMergeRequest() error = %v, want status code %d", err, tc.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if mr.State != gitlab.MergeRequestStateMerged {
+				t.Errorf("AcceptMergeRequest() state = %q, want %q", mr.State, gitlab.MergeRequestStateMerged)
+			}
+			want := []fakegitlab.Merge{{Project: "group/sub/project", IID: tc.iid, Options: tc.opts}}
+			if diff := cmp.Diff(want, s.Merges); diff != "" {
+				t.Errorf("merges mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+	c := gitlab.NewClient(s.URL(), nil)
+
+	if _, err := c.GetProject("group/sub/project"); gitlab.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("GetProject() error = %v, want an unauthorized error", err)
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakegitlab implements an in-memory GitLab REST API server, serving
+// the endpoints used by the gitlab client, for tests.
+package fakegitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/test-infra/prow/gitlab"
+)
+
+// Token is the only access token accepted by the fake server.
+const Token = "fake-token"
+
+// Merge records a merge request merged through the fake server.
+type Merge struct {
+	Project string
+	IID     int
+	Options gitlab.AcceptMergeRequestOptions
+}
+
+// Note records a comment created through the fake server.
+type Note struct {
+	Project string
+	IID     int
+	Body    string
+}
+
+// Server is a fake GitLab instance. Its fields can be changed while it's
+// running, while holding the lock.
+type Server struct {
+	sync.Mutex
+
+	// Projects by full path.
+	Projects map[string]*gitlab.Project
+	// MergeRequests by project path.
+	MergeRequests map[string][]gitlab.MergeRequest
+	// Branches by project path and branch name.
+	Branches map[string]map[string]string
+	// Statuses by commit SHA.
+	Statuses map[string][]gitlab.CommitStatus
+	// Diffs by project path and merge request IID.
+	Diffs map[string]map[int][]gitlab.Diff
+	// MergeErrors makes merging a merge request fail with the given status
+	// code, by project path and merge request IID.
+	MergeErrors map[string]map[int]int
+
+	Merges []Merge
+	Notes  []Note
+
+	// PageSize forces the pagination of the lists, when non-zero.
+	PageSize int
+
+	server *httptest.Server
+}
+
+// NewServer starts a fake GitLab server. It must be closed by the caller.
+func NewServer() *Server {
+	s := &Server{
+		Projects:      map[string]*gitlab.Project{},
+		MergeRequests: map[string][]gitlab.MergeRequest{},
+		Branches:      map[string]map[string]string{},
+		Statuses:      map[string][]gitlab.CommitStatus{},
+		Diffs:         map[string]map[int][]gitlab.Diff{},
+		MergeErrors:   map[string]map[int]int{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL is the URL of the GitLab instance, to be passed to gitlab.NewClient.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("PRIVATE-TOKEN") != Token {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	// The project path is escaped, so that it is a single path segment.
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/projects/")
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i], _ = url.PathUnescape(segments[i])
+	}
+	project := segments[0]
+	rest := segments[1:]
+
+	s.Lock()
+	defer s.Unlock()
+
+	p, ok := s.Projects[project]
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(rest) == 0:
+		writeJSON(w, p)
+	case r.Method == http.MethodGet && len(rest) == 1 && rest[0] == "merge_requests":
+		s.listMergeRequests(w, r, project)
+	case len(rest) >= 2 && rest[0] == "merge_requests":
+		iid, err := strconv.Atoi(rest[1])
+		if err != nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		s.serveMergeRequest(w, r, project, iid, rest[2:])
+	case r.Method == http.MethodGet && len(rest) == 3 && rest[0] == "repository" && rest[1] == "branches":
+		sha, ok := s.Branches[project][rest[2]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "404 Branch Not Found")
+			return
+		}
+		writeJSON(w, gitlab.Branch{Name: rest[2], Commit: gitlab.Commit{ID: sha}})
+	case r.Method == http.MethodGet && len(rest) == 4 && rest[0] == "repository" && rest[1] == "commits" && rest[3] == "statuses":
+		writePage(w, r, s.PageSize, s.Statuses[rest[2]])
+	default:
+		writeError(w, http.StatusNotFound, "404 Not found")
+	}
+}
+
+func (s *Server) listMergeRequests(w http.ResponseWriter, r *http.Request, project string) {
+	query := r.URL.Query()
+	var labels []string
+	if l := query.Get("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+
+	var res []gitlab.MergeRequest
+	for _, mr := range s.MergeRequests[project] {
+		if state := query.Get("state"); state != "" && mr.State != state {
+			continue
+		}
+		if query.Get("wip") == "no" && mr.Draft {
+			continue
+		}
+		if milestone := query.Get("milestone"); milestone != "" && (mr.Milestone == nil || mr.Milestone.Title != milestone) {
+			continue
+		}
+		if !hasAll(mr.Labels, labels) {
+			continue
+		}
+		res = append(res, mr)
+	}
+	writePage(w, r, s.PageSize, res)
+}
+
+func (s *Server) serveMergeRequest(w http.ResponseWriter, r *http.Request, project string, iid int, rest []string) {
+	idx := -1
+	for i, mr := range s.MergeRequests[project] {
+		if mr.IID == iid {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		writeError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+	mr := &s.MergeRequests[project][idx]
+
+	switch {
+	case r.Method == http.MethodGet && len(rest) == 0:
+		writeJSON(w, mr)
+	case r.Method == http.MethodGet && len(rest) == 1 && rest[0] == "diffs":
+		writePage(w, r, s.PageSize, s.Diffs[project][iid])
+	case r.Method == http.MethodPost && len(rest) == 1 && rest[0] == "notes":
+		var note struct {
+			Body string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.Notes = append(s.Notes, Note{Project: project, IID: iid, Body: note.Body})
+		writeJSON(w, note)
+	case r.Method == http.MethodPut && len(rest) == 1 && rest[0] == "merge":
+		var opts gitlab.AcceptMergeRequestOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if code, ok := s.MergeErrors[project][iid]; ok {
+			writeError(w, code, http.StatusText(code))
+			return
+		}
+		if mr.State != gitlab.MergeRequestStateOpened || mr.HasConflicts {
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+			return
+		}
+		if opts.SHA != "" && opts.SHA != mr.SHA {
+			writeError(w, http.StatusConflict, "SHA does not match HEAD of source branch")
+			return
+		}
+		mr.State = gitlab.MergeRequestStateMerged
+		s.Merges = append(s.Merges, Merge{Project: project, IID: iid, Options: opts})
+		writeJSON(w, mr)
+	default:
+		writeError(w, http.StatusNotFound, "404 Not found")
+	}
+}
+
+// writePage writes the requested page of the items, setting the pagination
+// headers like GitLab.
+func writePage[T any](w http.ResponseWriter, r *http.Request, pageSize int, items []T) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize, _ = strconv.Atoi(r.URL.Query().Get("per_page"))
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	start := (page - 1) * pageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + pageSize
+	if end >= len(items) {
+		end = len(items)
+	} else {
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+	}
+
+	res := items[start:end]
+	if res == nil {
+		res = []T{}
+	}
+	writeJSON(w, res)
+}
+
+func hasAll(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
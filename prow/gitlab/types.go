@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import "time"
+
+// Merge request states.
+const (
+	MergeRequestStateOpened = "opened"
+	MergeRequestStateMerged = "merged"
+)
+
+// Merge statuses of a merge request, as computed by GitLab.
+// https://docs.gitlab.com/ee/api/merge_requests.html#merge-status
+const (
+	MergeStatusCanBeMerged    = "can_be_merged"
+	MergeStatusCannotBeMerged = "cannot_be_merged"
+)
+
+// Merge methods of a project.
+// https://docs.gitlab.com/ee/user/project/merge_requests/methods/
+const (
+	MergeMethodMerge       = "merge"
+	MergeMethodRebaseMerge = "rebase_merge"
+	MergeMethodFastForward = "ff"
+)
+
+// Squash options of a project.
+const (
+	SquashOptionNever      = "never"
+	SquashOptionAlways     = "always"
+	SquashOptionDefaultOn  = "default_on"
+	SquashOptionDefaultOff = "default_off"
+)
+
+// Commit status states.
+// https://docs.gitlab.com/ee/api/commits.html#commit-status
+const (
+	CommitStatusCreated            = "created"
+	CommitStatusWaitingForResource = "waiting_for_resource"
+	CommitStatusPreparing          = "preparing"
+	CommitStatusPending            = "pending"
+	CommitStatusRunning            = "running"
+	CommitStatusSuccess            = "success"
+	CommitStatusFailed             = "failed"
+	CommitStatusCanceled           = "canceled"
+	CommitStatusSkipped            = "skipped"
+	CommitStatusManual             = "manual"
+	CommitStatusScheduled          = "scheduled"
+)
+
+// User is a GitLab user, as embedded in other objects.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// Milestone is a GitLab milestone, as embedded in merge requests.
+type Milestone struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// MergeRequest is a GitLab merge request.
+// https://docs.gitlab.com/ee/api/merge_requests.html
+type MergeRequest struct {
+	ID int `json:"id"`
+	// IID is the number of the merge request within its project.
+	IID             int        `json:"iid"`
+	ProjectID       int        `json:"project_id"`
+	SourceProjectID int        `json:"source_project_id"`
+	TargetProjectID int        `json:"target_project_id"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	State           string     `json:"state"`
+	SourceBranch    string     `json:"source_branch"`
+	TargetBranch    string     `json:"target_branch"`
+	SHA             string     `json:"sha"`
+	Labels          []string   `json:"labels"`
+	Milestone       *Milestone `json:"milestone"`
+	Author          User       `json:"author"`
+	Draft           bool       `json:"draft"`
+	HasConflicts    bool       `json:"has_conflicts"`
+	MergeStatus     string     `json:"merge_status"`
+	Squash          bool       `json:"squash"`
+	WebURL          string     `json:"web_url"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Project is a GitLab project.
+// https://docs.gitlab.com/ee/api/projects.html
+type Project struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	WebURL            string `json:"web_url"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	MergeMethod       string `json:"merge_method"`
+	SquashOption      string `json:"squash_option"`
+}
+
+// Commit is a GitLab commit, as embedded in branches.
+type Commit struct {
+	ID string `json:"id"`
+}
+
+// Branch is a GitLab repository branch.
+// https://docs.gitlab.com/ee/api/branches.html
+type Branch struct {
+	Name   string `json:"name"`
+	Commit Commit `json:"commit"`
+}
+
+// CommitStatus is the status of a commit reported by a CI job or an external
+// system.
+// https://docs.gitlab.com/ee/api/commits.html#list-the-statuses-of-a-commit
+type CommitStatus struct {
+	ID           int    `json:"id"`
+	SHA          string `json:"sha"`
+	Ref          string `json:"ref"`
+	Status       string `json:"status"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	TargetURL    string `json:"target_url"`
+	AllowFailure bool   `json:"allow_failure"`
+}
+
+// Diff is a file changed by a merge request.
+// https://docs.gitlab.com/ee/api/merge_requests.html#list-merge-request-diffs
+type Diff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// ListMergeRequestsOptions filters the merge requests of a project.
+type ListMergeRequestsOptions struct {
+	// State is one of opened, closed, locked or merged. All merge requests are
+	// listed if it is empty.
+	State string
+	// Labels only lists the merge requests that have all the labels.
+	Labels []string
+	// Milestone only lists the merge requests of the milestone, by title.
+	Milestone string
+	// ExcludeDrafts filters out the draft merge requests.
+	ExcludeDrafts bool
+}
+
+// AcceptMergeRequestOptions controls how a merge request is merged.
+// https://docs.gitlab.com/ee/api/merge_requests.html#merge-a-merge-request
+type AcceptMergeRequestOptions struct {
+	// SHA must match the head of the source branch, otherwise the merge fails.
+	SHA                       string `json:"sha,omitempty"`
+	Squash                    *bool  `json:"squash,omitempty"`
+	MergeCommitMessage        string `json:"merge_commit_message,omitempty"`
+	SquashCommitMessage       string `json:"squash_commit_message,omitempty"`
+	ShouldRemoveSourceBranch  bool   `json:"should_remove_source_branch,omitempty"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds,omitempty"`
+}
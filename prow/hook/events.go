@@ -354,6 +354,34 @@ func (s *Server) handleStatusEvent(l *logrus.Entry, se github.StatusEvent) {
 	}
 }
 
+func (s *Server) handleCheckRunEvent(l *logrus.Entry, cre github.CheckRunEvent) {
+	defer s.wg.Done()
+	l = l.WithFields(logrus.Fields{
+		github.OrgLogField:  cre.Repo.Owner.Login,
+		github.RepoLogField: cre.Repo.Name,
+		"name":              cre.CheckRun.Name,
+		"sha":               cre.CheckRun.HeadSHA,
+		"id":                cre.CheckRun.ID,
+		"action":            cre.Action,
+	})
+	l.Infof("Check run %s %s.", cre.CheckRun.Name, cre.Action)
+	for p, h := range s.Plugins.CheckRunEventHandlers(cre.Repo.Owner.Login, cre.Repo.Name) {
+		s.wg.Add(1)
+		go func(p string, h plugins.CheckRunEventHandler) {
+			defer s.wg.Done()
+			agent := plugins.NewAgent(s.ConfigAgent, s.Plugins, s.ClientAgent, cre.Repo.Owner.Login, s.Metrics.Metrics, l, p)
+			start := time.Now()
+			err := errorOnPanic(func() error { return h(agent, cre) })
+			labels := prometheus.Labels{"event_type": l.Data[eventTypeField].(string), "action": string(cre.Action), "plugin": p, "took_action": strconv.FormatBool(agent.TookAction())}
+			if err != nil {
+				agent.Logger.WithError(err).Error("Error handling CheckRunEvent.")
+				s.Metrics.PluginHandleErrors.With(labels).Inc()
+			}
+			s.Metrics.PluginHandleDuration.With(labels).Observe(time.Since(start).Seconds())
+		}(p, h)
+	}
+}
+
 func (s *Server) handleGenericComment(l *logrus.Entry, ce *github.GenericCommentEvent) {
 	for p, h := range s.Plugins.GenericCommentHandlers(ce.Repo.Owner.Login, ce.Repo.Name) {
 		s.wg.Add(1)
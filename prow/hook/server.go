@@ -166,6 +166,17 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 			s.wg.Add(1)
 			go s.handleStatusEvent(l, se)
 		}
+	case "check_run":
+		var cre github.CheckRunEvent
+		if err := json.Unmarshal(payload, &cre); err != nil {
+			return err
+		}
+		cre.GUID = eventGUID
+		srcRepo = cre.Repo.FullName
+		if s.RepoEnabled(cre.Repo.Owner.Login, cre.Repo.Name) {
+			s.wg.Add(1)
+			go s.handleCheckRunEvent(l, cre)
+		}
 	default:
 		var ge github.GenericEvent
 		if err := json.Unmarshal(payload, &ge); err != nil {
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lark contains a client for the custom bots of Lark/Feishu, which
+// post messages to a group chat through a webhook.
+package lark
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// BotsFlag is the flag type mapping the names of the bots to the paths of
+// their secret files.
+type BotsFlag map[string]string
+
+func (b *BotsFlag) String() string {
+	var bots []string
+	for bot, path := range *b {
+		bots = append(bots, bot+"="+path)
+	}
+	return strings.Join(bots, " ")
+}
+
+// Set populates BotsFlag upon flag.Parse()
+func (b *BotsFlag) Set(value string) error {
+	if len(*b) == 0 {
+		*b = map[string]string{}
+	}
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("%s not in the form of bot=secret-path", value)
+	}
+	bot, path := parts[0], parts[1]
+	if _, ok := (*b)[bot]; ok {
+		return fmt.Errorf("duplicate bot: %s", bot)
+	}
+	(*b)[bot] = path
+	return nil
+}
+
+// Bot is the secret of a custom bot. The webhook URL embeds the token of the
+// bot so it must be kept secret too.
+type Bot struct {
+	WebhookURL string `json:"webhook_url"`
+	// Secret is the signature secret of the bot, only needed if its
+	// signature verification is enabled.
+	Secret string `json:"secret,omitempty"`
+}
+
+// ParseBot parses the secret file of a bot.
+func ParseBot(raw []byte) (Bot, error) {
+	var bot Bot
+	if err := yaml.UnmarshalStrict(raw, &bot); err != nil {
+		return bot, fmt.Errorf("failed to unmarshal bot secret: %w", err)
+	}
+	if bot.WebhookURL == "" {
+		return bot, errors.New("webhook_url must be set")
+	}
+	return bot, nil
+}
+
+// Client posts messages through the webhook of a custom bot.
+type Client struct {
+	logger *logrus.Entry
+	bot    func() Bot
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewClient creates a client for the bot returned by the getter, which is
+// called on every message so that the secret can be rotated.
+func NewClient(bot func() Bot) *Client {
+	return &Client{
+		logger: logrus.WithField("client", "lark"),
+		bot:    bot,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Sign computes the signature of a message sent at the given unix timestamp,
+// as documented in https://open.larksuite.com/document/client-docs/bot-v3/add-custom-bot.
+func Sign(timestamp int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(strconv.FormatInt(timestamp, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type message struct {
+	Timestamp string      `json:"timestamp,omitempty"`
+	Sign      string      `json:"sign,omitempty"`
+	MsgType   string      `json:"msg_type"`
+	Card      interface{} `json:"card"`
+}
+
+// SendCard posts an interactive message card to the chat of the bot.
+func (c *Client) SendCard(card interface{}) error {
+	bot := c.bot()
+	msg := message{MsgType: "interactive", Card: card}
+	if bot.Secret != "" {
+		timestamp := c.now().Unix()
+		msg.Timestamp = strconv.FormatInt(timestamp, 10)
+		msg.Sign = Sign(timestamp, bot.Secret)
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	c.logger.Debugf("SendCard(%s)", body)
+
+	resp, err := c.client.Post(bot.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The error contains the webhook URL, which must not be leaked.
+		return errors.New("failed to post message to the bot webhook")
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	apiResponse := struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		// Older deployments of the API use these fields instead.
+		StatusCode    int    `json:"StatusCode"`
+		StatusMessage string `json:"StatusMessage"`
+	}{}
+	if err := json.Unmarshal(respBody, &apiResponse); err != nil {
+		return fmt.Errorf("API returned invalid JSON (%q): %w", string(respBody), err)
+	}
+	if resp.StatusCode != http.StatusOK || apiResponse.Code != 0 || apiResponse.StatusCode != 0 {
+		return fmt.Errorf("request failed with status %d: code %d: %s%s", resp.StatusCode, apiResponse.Code+apiResponse.StatusCode, apiResponse.Msg, apiResponse.StatusMessage)
+	}
+	return nil
+}
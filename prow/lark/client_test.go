@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lark
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBotsFlag(t *testing.T) {
+	var testArg BotsFlag
+	flags := flag.NewFlagSet("foo", flag.PanicOnError)
+	flags.Var(&testArg, "test-arg", "")
+	if err := flags.Parse([]string{"--test-arg", "a=b"}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(BotsFlag(map[string]string{"a": "b"}), testArg); diff != "" {
+		t.Fatalf("Arg parsing mismatch. Want(-), got(+):\n%s", diff)
+	}
+}
+
+func TestParseBot(t *testing.T) {
+	tcs := []struct {
+		name        string
+		raw         string
+		expected    Bot
+		expectedErr bool
+	}{
+		{
+			name:     "with secret",
+			raw:      "webhook_url: https://open.feishu.cn/open-apis/bot/v2/hook/token\nsecret: s3cr3t\n",
+			expected: Bot{WebhookURL: "https://open.feishu.cn/open-apis/bot/v2/hook/token", Secret: "s3cr3t"},
+		},
+		{
+			name:     "without secret",
+			raw:      "webhook_url: https://open.larksuite.com/open-apis/bot/v2/hook/token\n",
+			expected: Bot{WebhookURL: "https://open.larksuite.com/open-apis/bot/v2/hook/token"},
+		},
+		{
+			name:        "no webhook url",
+			raw:         "secret: s3cr3t\n",
+			expectedErr: true,
+		},
+		{
+			name:        "unknown field",
+			raw:         "webhook: https://open.feishu.cn/open-apis/bot/v2/hook/token\n",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			bot, err := ParseBot([]byte(tc.raw))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, bot); diff != "" {
+				t.Errorf("Bot mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSendCard(t *testing.T) {
+	now := time.Unix(1686000000, 0)
+	tcs := []struct {
+		name          string
+		secret        string
+		response      string
+		expectedSign  string
+		expectedError bool
+	}{
+		{
+			name:     "unsigned",
+			response: `{"code":0,"msg":"success","data":{}}`,
+		},
+		{
+			name:         "signed",
+			secret:       "s3cr3t",
+			response:     `{"code":0,"msg":"success","data":{}}`,
+			expectedSign: Sign(now.Unix(), "s3cr3t"),
+		},
+		{
+			name:          "rejected signature",
+			secret:        "s3cr3t",
+			response:      `{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`,
+			expectedSign:  Sign(now.Unix(), "s3cr3t"),
+			expectedError: true,
+		},
+		{
+			name:          "legacy error",
+			response:      `{"StatusCode":9499,"StatusMessage":"Bad Request"}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid response",
+			response:      `not json`,
+			expectedError: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var received message
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("Failed to decode message: %v", err)
+				}
+				w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			client := NewClient(func() Bot { return Bot{WebhookURL: server.URL, Secret: tc.secret} })
+			client.now = func() time.Time { return now }
+			err := client.SendCard(map[string]string{"some": "card"})
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if received.MsgType != "interactive" {
+				t.Errorf("Expected an interactive message, got %q.", received.MsgType)
+			}
+			if received.Sign != tc.expectedSign {
+				t.Errorf("Expected signature %q, got %q.", tc.expectedSign, received.Sign)
+			}
+			if tc.secret != "" && received.Timestamp != "1686000000" {
+				t.Errorf("Expected timestamp 1686000000, got %q.", received.Timestamp)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"sigs.k8s.io/yaml"
@@ -88,6 +89,7 @@ type Configuration struct {
 	ProjectManager       ProjectManager               `json:"project_manager,omitempty"`
 	RequireMatchingLabel []RequireMatchingLabel       `json:"require_matching_label,omitempty"`
 	Retitle              Retitle                      `json:"retitle,omitempty"`
+	RewardOwners         []RewardOwners               `json:"reward_owners,omitempty"`
 	Slack                Slack                        `json:"slack,omitempty"`
 	SigMention           SigMention                   `json:"sigmention,omitempty"`
 	Size                 Size                         `json:"size,omitempty"`
@@ -254,6 +256,60 @@ type Retitle struct {
 	AllowClosedIssues bool `json:"allow_closed_issues,omitempty"`
 }
 
+// RewardOwners action kinds.
+const (
+	// RewardOwnersActionComment comments on the pull request adding the owners.
+	RewardOwnersActionComment = "comment"
+	// RewardOwnersActionLabel labels the pull request adding the owners.
+	RewardOwnersActionLabel = "label"
+	// RewardOwnersActionIssue opens a welcome issue assigned to the new owners.
+	RewardOwnersActionIssue = "issue"
+)
+
+// RewardOwners specifies configuration for the reward-owners plugin.
+type RewardOwners struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// ReviewerMessageTemplate is the Go template of the message rewarding the
+	// new reviewers. The new owners that are also new approvers only get the
+	// approver message. The new reviewers get the default message if it is
+	// unset while ApproverMessageTemplate is set.
+	// For the info struct see prow/plugins/reward-owners/reward-owners.go's RewardInfo
+	ReviewerMessageTemplate string `json:"reviewer_message_template,omitempty"`
+	// ApproverMessageTemplate is the Go template of the message rewarding the
+	// new approvers. The new approvers get the default message if it is unset
+	// while ReviewerMessageTemplate is set.
+	// For the info struct see prow/plugins/reward-owners/reward-owners.go's RewardInfo
+	ApproverMessageTemplate string `json:"approver_message_template,omitempty"`
+	// Action is how the new owners are rewarded, one of:
+	// - comment: comments the messages on the pull request (default).
+	// - label: adds Label to the pull request, no message is posted.
+	// - issue: opens an issue with the messages, assigned to the new owners.
+	Action string `json:"action,omitempty"`
+	// Label is the label added to the pull request by the label action.
+	Label string `json:"label,omitempty"`
+	// IssueTitleTemplate is the Go template of the title of the issue opened by
+	// the issue action. Defaults to "Welcome to the new owners of {{.Org}}/{{.Repo}}".
+	IssueTitleTemplate string `json:"issue_title_template,omitempty"`
+}
+
+// RewardOwnersFor finds the RewardOwners for a repo, the repo config takes
+// precedence over the org config. It returns an empty config if none matches.
+func (c *Configuration) RewardOwnersFor(org, repo string) RewardOwners {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for _, ro := range c.RewardOwners {
+		if sets.New[string](ro.Repos...).Has(fullName) {
+			return ro
+		}
+	}
+	for _, ro := range c.RewardOwners {
+		if sets.New[string](ro.Repos...).Has(org) {
+			return ro
+		}
+	}
+	return RewardOwners{}
+}
+
 // SigMention specifies configuration for the sigmention plugin.
 type SigMention struct {
 	// Regexp parses comments and should return matches to team mentions.
@@ -1314,6 +1370,30 @@ func validateTrigger(triggers []Trigger) error {
 
 var warnRepoMilestone time.Time
 
+func validateRewardOwners(rewardOwners []RewardOwners) error {
+	for i, ro := range rewardOwners {
+		switch ro.Action {
+		case "", RewardOwnersActionComment, RewardOwnersActionIssue:
+		case RewardOwnersActionLabel:
+			if ro.Label == "" {
+				return fmt.Errorf("reward_owners[%d]: label must be set for the %q action", i, ro.Action)
+			}
+		default:
+			return fmt.Errorf("reward_owners[%d]: unknown action %q", i, ro.Action)
+		}
+		for name, tmpl := range map[string]string{
+			"reviewer_message_template": ro.ReviewerMessageTemplate,
+			"approver_message_template": ro.ApproverMessageTemplate,
+			"issue_title_template":      ro.IssueTitleTemplate,
+		} {
+			if _, err := template.New(name).Parse(tmpl); err != nil {
+				return fmt.Errorf("reward_owners[%d]: invalid %s: %w", i, name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func validateRepoMilestone(milestones map[string]Milestone) {
 	for _, milestone := range milestones {
 		if milestone.MaintainersID != 0 {
@@ -1414,6 +1494,9 @@ func (c *Configuration) Validate() error {
 	if err := validateTrigger(c.Triggers); err != nil {
 		return err
 	}
+	if err := validateRewardOwners(c.RewardOwners); err != nil {
+		return err
+	}
 	validateRepoMilestone(c.RepoMilestone)
 
 	return nil
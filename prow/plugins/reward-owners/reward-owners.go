@@ -17,9 +17,11 @@ limitations under the License.
 package rewardowners
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/sirupsen/logrus"
 
@@ -48,21 +50,66 @@ Next steps:
 
 4- Look over our [governance](https://github.com/kubernetes/community/blob/master/governance.md) docs now that you are actively involved in the maintenance of the project.
 `
+	defaultIssueTitleTemplate = "Welcome to the new owners of {{.Org}}/{{.Repo}}"
 )
 
+// RewardInfo contains the info provided to the reward message templates.
+type RewardInfo struct {
+	Org  string
+	Repo string
+	// Number is the number of the merged pull request modifying the owners.
+	Number int
+	// Users are the owners rewarded by the message, tagged and comma
+	// separated, e.g. "@alice, @bob".
+	Users string
+	// NewApprovers and NewReviewers are the logins of all the new approvers
+	// and the new reviewers that are not new approvers.
+	NewApprovers []string
+	NewReviewers []string
+	// OwnersFiles are the paths of the modified OWNERS and OWNERS_ALIASES files.
+	OwnersFiles []string
+}
+
 func init() {
 	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
 }
 
-func helpProvider(_ *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	rewardConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		opts := config.RewardOwnersFor(repo.Org, repo.Repo)
+		if opts.ReviewerMessageTemplate == "" && opts.ApproverMessageTemplate == "" {
+			continue
+		}
+		rewardConfig[repo.String()] = fmt.Sprintf("The reward-owners plugin is configured to reward the new reviewers with the template %q, the new approvers with the template %q, by the %q action.",
+			opts.ReviewerMessageTemplate, opts.ApproverMessageTemplate, actionOf(opts))
+	}
+
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		RewardOwners: []plugins.RewardOwners{
+			{
+				Repos:                   []string{"org/repo"},
+				ReviewerMessageTemplate: "Thanks to {{.Users}} for becoming reviewers of {{.Org}}/{{.Repo}}!",
+				ApproverMessageTemplate: "Thanks to {{.Users}} for becoming approvers of {{.Org}}/{{.Repo}}!",
+				Action:                  plugins.RewardOwnersActionComment,
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
 	pluginHelp := &pluginhelp.PluginHelp{
 		Description: fmt.Sprintf("The reward-owners plugin watches in %s and %s files for modifications and welcomes new approvers and reviewers.", ownersconfig.DefaultOwnersFile, ownersconfig.DefaultOwnersAliasesFile),
+		Config:      rewardConfig,
+		Snippet:     yamlSnippet,
 	}
 	return pluginHelp, nil
 }
 
 type githubClient interface {
+	AddLabel(org, repo string, number int, label string) error
 	CreateComment(owner, repo string, number int, comment string) error
+	CreateIssue(org, repo, title, body string, milestone int, labels, assignees []string) (int, error)
 	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
 }
 
@@ -93,10 +140,11 @@ func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
 		repo:         pre.Repo.Name,
 		repoFullName: pre.Repo.FullName,
 	}
-	return handle(pc.GitHubClient, pc.OwnersClient, pc.Logger, prInfo, pc.PluginConfig.OwnersFilenames)
+	opts := pc.PluginConfig.RewardOwnersFor(prInfo.org, prInfo.repo)
+	return handle(pc.GitHubClient, pc.OwnersClient, pc.Logger, prInfo, pc.PluginConfig.OwnersFilenames, opts)
 }
 
-func handle(ghc githubClient, oc ownersClient, log *logrus.Entry, info info, resolver ownersconfig.Resolver) error {
+func handle(ghc githubClient, oc ownersClient, log *logrus.Entry, info info, resolver ownersconfig.Resolver, opts plugins.RewardOwners) error {
 
 	// Get changes.
 	changes, err := ghc.GetPullRequestChanges(info.org, info.repo, info.number)
@@ -105,16 +153,15 @@ func handle(ghc githubClient, oc ownersClient, log *logrus.Entry, info info, res
 	}
 
 	// Check if OWNERS or OWNERS_ALIASES have been modified.
-	var ownersModified bool
+	var ownersFiles []string
 	filenames := resolver(info.org, info.repo)
 	for _, change := range changes {
 		if (filepath.Base(change.Filename) == filenames.Owners || change.Filename == filenames.OwnersAliases) &&
 			change.Status != github.PullRequestFileRemoved {
-			ownersModified = true
-			break
+			ownersFiles = append(ownersFiles, change.Filename)
 		}
 	}
-	if !ownersModified {
+	if len(ownersFiles) == 0 {
 		return nil
 	}
 
@@ -137,11 +184,105 @@ func handle(ghc githubClient, oc ownersClient, log *logrus.Entry, info info, res
 		return nil
 	}
 
-	// Tag users by prepending @ to their names.
-	taggedNewOwners := make([]string, newOwners.Len())
-	for i, o := range sets.List(newOwners) {
-		taggedNewOwners[i] = fmt.Sprintf("@%s", o)
+	if actionOf(opts) == plugins.RewardOwnersActionLabel {
+		return ghc.AddLabel(info.org, info.repo, info.number, opts.Label)
+	}
+
+	var message string
+	if opts.ReviewerMessageTemplate == "" && opts.ApproverMessageTemplate == "" {
+		message = fmt.Sprintf(RewardMessage, tagUsers(sets.List(newOwners)))
+	} else {
+		newApprovers := headRepo.AllApprovers().Difference(baseRepo.AllApprovers())
+		newReviewers := headRepo.AllReviewers().Difference(baseRepo.AllReviewers()).Difference(newApprovers)
+		rewardInfo := RewardInfo{
+			Org:          info.org,
+			Repo:         info.repo,
+			Number:       info.number,
+			NewApprovers: sets.List(newApprovers),
+			NewReviewers: sets.List(newReviewers),
+			OwnersFiles:  ownersFiles,
+		}
+		message, err = rewardMessage(opts, rewardInfo)
+		if err != nil {
+			return err
+		}
+	}
+	if message == "" {
+		log.Debug("No message for the new owners, exiting.")
+		return nil
+	}
+
+	if actionOf(opts) == plugins.RewardOwnersActionIssue {
+		titleTemplate := opts.IssueTitleTemplate
+		if titleTemplate == "" {
+			titleTemplate = defaultIssueTitleTemplate
+		}
+		title, err := executeTemplate("issue title", titleTemplate, RewardInfo{Org: info.org, Repo: info.repo, Number: info.number})
+		if err != nil {
+			return err
+		}
+		_, err = ghc.CreateIssue(info.org, info.repo, title, message, 0, nil, sets.List(newOwners))
+		return err
+	}
+
+	return ghc.CreateComment(info.org, info.repo, info.number, message)
+}
+
+// rewardMessage renders the messages for the new approvers and the new
+// reviewers. A role gets no message if it has no new owners, and the default
+// message if it has no template.
+func rewardMessage(opts plugins.RewardOwners, rewardInfo RewardInfo) (string, error) {
+	var messages []string
+	for _, role := range []struct {
+		name     string
+		template string
+		users    []string
+	}{
+		{name: "approver", template: opts.ApproverMessageTemplate, users: rewardInfo.NewApprovers},
+		{name: "reviewer", template: opts.ReviewerMessageTemplate, users: rewardInfo.NewReviewers},
+	} {
+		if len(role.users) == 0 {
+			continue
+		}
+		rewardInfo.Users = tagUsers(role.users)
+		if role.template == "" {
+			messages = append(messages, fmt.Sprintf(RewardMessage, rewardInfo.Users))
+			continue
+		}
+		message, err := executeTemplate(role.name+" message", role.template, rewardInfo)
+		if err != nil {
+			return "", err
+		}
+		messages = append(messages, message)
+	}
+
+	return strings.Join(messages, "\n\n"), nil
+}
+
+func executeTemplate(name, text string, data RewardInfo) (string, error) {
+	parsedTemplate, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the %s template: %w", name, err)
 	}
+	var buf bytes.Buffer
+	if err := parsedTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute the %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
 
-	return ghc.CreateComment(info.org, info.repo, info.number, fmt.Sprintf(RewardMessage, strings.Join(taggedNewOwners, ", ")))
+// tagUsers tags users by prepending @ to their names.
+func tagUsers(users []string) string {
+	tagged := make([]string, len(users))
+	for i, u := range users {
+		tagged[i] = fmt.Sprintf("@%s", u)
+	}
+	return strings.Join(tagged, ", ")
+}
+
+func actionOf(opts plugins.RewardOwners) string {
+	if opts.Action == "" {
+		return plugins.RewardOwnersActionComment
+	}
+	return opts.Action
 }
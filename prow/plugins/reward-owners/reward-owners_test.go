@@ -17,13 +17,17 @@ limitations under the License.
 package rewardowners
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"k8s.io/test-infra/prow/github/fakegithub"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/pkg/layeredsets"
 
+	"k8s.io/test-infra/prow/plugins"
 	"k8s.io/test-infra/prow/plugins/ownersconfig"
 	"k8s.io/test-infra/prow/repoowners"
 
@@ -62,6 +66,9 @@ func (f *fakeRepoOwners) RequiredReviewers(path string) sets.Set[string] { retur
 func (f *fakeRepoOwners) TopLevelApprovers() sets.Set[string]            { return nil }
 
 func (f *fakeRepoOwners) AllApprovers() sets.Set[string] {
+	if approvers, ok := approversBySha[f.sha]; ok {
+		return approvers
+	}
 	return ownersBySha[f.sha]
 }
 
@@ -70,13 +77,25 @@ func (f *fakeRepoOwners) AllOwners() sets.Set[string] {
 }
 
 func (f *fakeRepoOwners) AllReviewers() sets.Set[string] {
+	if reviewers, ok := reviewersBySha[f.sha]; ok {
+		return reviewers
+	}
 	return ownersBySha[f.sha]
 }
 
 var ownersBySha = map[string]sets.Set[string]{
-	"base":         sets.New[string]("alice", "bob"),
-	"add cole":     sets.New[string]("alice", "bob", "cole"),
-	"remove alice": sets.New[string]("bob"),
+	"base":                          sets.New[string]("alice", "bob"),
+	"add cole":                      sets.New[string]("alice", "bob", "cole"),
+	"remove alice":                  sets.New[string]("bob"),
+	"add reviewer cole, dave, erin": sets.New[string]("alice", "bob", "cole", "dave", "erin"),
+}
+
+var approversBySha = map[string]sets.Set[string]{
+	"add reviewer cole, dave, erin": sets.New[string]("alice", "bob", "erin"),
+}
+
+var reviewersBySha = map[string]sets.Set[string]{
+	"add reviewer cole, dave, erin": sets.New[string]("alice", "bob", "cole", "dave", "erin"),
 }
 
 func makeChanges(files []string) map[string]string {
@@ -185,7 +204,7 @@ func testHandle(clients localgit.Clients, t *testing.T) {
 				repoFullName: "org/repo",
 			}
 
-			if err := handle(fghc, oc, logrus.WithField("plugin", PluginName), prInfo, ownersconfig.FakeResolver); err != nil {
+			if err := handle(fghc, oc, logrus.WithField("plugin", PluginName), prInfo, ownersconfig.FakeResolver, plugins.RewardOwners{}); err != nil {
 				t.Fatalf("Handle PR: %v", err)
 			}
 			numComments := len(fghc.IssueCommentsAdded)
@@ -200,3 +219,105 @@ func testHandle(clients localgit.Clients, t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWithConfig(t *testing.T) {
+	const (
+		reviewerTemplate = "Welcome reviewers {{.Users}} of {{.Org}}/{{.Repo}} in {{range .OwnersFiles}}{{.}}{{end}}"
+		approverTemplate = "Welcome approvers {{.Users}} to #{{.Number}}"
+	)
+	var tests = []struct {
+		name           string
+		opts           plugins.RewardOwners
+		sha            string
+		expectComments []string
+		expectLabels   []string
+		expectIssue    *github.Issue
+	}{
+		{
+			name: "separate messages for reviewers and approvers",
+			opts: plugins.RewardOwners{
+				ReviewerMessageTemplate: reviewerTemplate,
+				ApproverMessageTemplate: approverTemplate,
+			},
+			sha: "add reviewer cole, dave, erin",
+			expectComments: []string{
+				"org/repo#1:Welcome approvers @erin to #1\n\nWelcome reviewers @cole, @dave of org/repo in OWNERS",
+			},
+		},
+		{
+			name: "no template for the role",
+			opts: plugins.RewardOwners{
+				ApproverMessageTemplate: approverTemplate,
+			},
+			sha:            "add cole",
+			expectComments: []string{"org/repo#1:Welcome approvers @cole to #1"},
+		},
+		{
+			name: "default message without a template for the new approvers",
+			opts: plugins.RewardOwners{
+				ReviewerMessageTemplate: reviewerTemplate,
+			},
+			sha:            "add cole",
+			expectComments: []string{"org/repo#1:" + fmt.Sprintf(RewardMessage, "@cole")},
+		},
+		{
+			name: "default message without a template for the new reviewers",
+			opts: plugins.RewardOwners{
+				ApproverMessageTemplate: approverTemplate,
+			},
+			sha: "add reviewer cole, dave, erin",
+			expectComments: []string{
+				"org/repo#1:Welcome approvers @erin to #1\n\n" + fmt.Sprintf(RewardMessage, "@cole, @dave"),
+			},
+		},
+		{
+			name: "label action",
+			opts: plugins.RewardOwners{
+				Action: plugins.RewardOwnersActionLabel,
+				Label:  "new-owners",
+			},
+			sha:          "add cole",
+			expectLabels: []string{"org/repo#1:new-owners"},
+		},
+		{
+			name: "issue action",
+			opts: plugins.RewardOwners{
+				ApproverMessageTemplate: approverTemplate,
+				Action:                  plugins.RewardOwnersActionIssue,
+			},
+			sha: "add cole",
+			expectIssue: &github.Issue{
+				ID:        1,
+				Title:     "Welcome to the new owners of org/repo",
+				Body:      "Welcome approvers @cole to #1",
+				Assignees: []github.User{{Name: "cole"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fghc := newFakeGitHubClient(makeChanges([]string{"OWNERS"}), 1)
+			prInfo := info{
+				base:   github.PullRequestBranch{Ref: "master", SHA: "base"},
+				head:   github.PullRequestBranch{Ref: "master", SHA: test.sha},
+				number: 1,
+				org:    "org",
+				repo:   "repo",
+			}
+
+			if err := handle(fghc, &fakeOwnersClient{}, logrus.WithField("plugin", PluginName), prInfo, ownersconfig.FakeResolver, test.opts); err != nil {
+				t.Fatalf("Handle PR: %v", err)
+			}
+			if diff := cmp.Diff(test.expectComments, fghc.IssueCommentsAdded); diff != "" {
+				t.Errorf("comments mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectLabels, fghc.IssueLabelsAdded); diff != "" {
+				t.Errorf("labels mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectIssue, fghc.Issues[1]); diff != "" {
+				t.Errorf("issue mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
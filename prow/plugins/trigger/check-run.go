@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/kube"
+	"k8s.io/test-infra/prow/pjutil"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+// handleCheckRun re-runs the presubmit reported by a check run when a
+// trusted user asks for it from the GitHub UI. The check runs are created by
+// the GitHub Checks reporter of crier, which uses the name of the ProwJob as
+// their external ID.
+func handleCheckRun(c Client, trigger plugins.Trigger, cre github.CheckRunEvent) error {
+	switch cre.Action {
+	case github.CheckRunActionRerequested:
+	case github.CheckRunActionRequestedAction:
+		if cre.RequestedAction == nil || cre.RequestedAction.Identifier != pjutil.RerunCheckRunAction {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if cre.CheckRun.ExternalID == "" {
+		return nil
+	}
+
+	pj, err := c.ProwJobClient.Get(context.TODO(), cre.CheckRun.ExternalID, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		c.Logger.WithField("external-id", cre.CheckRun.ExternalID).Debug("Check run was not created for a ProwJob, skipping.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ProwJob %s: %w", cre.CheckRun.ExternalID, err)
+	}
+	org, repo := cre.Repo.Owner.Login, cre.Repo.Name
+	if pj.Spec.Type != prowapi.PresubmitJob || pj.Spec.Refs == nil || len(pj.Spec.Refs.Pulls) != 1 ||
+		pj.Spec.Refs.Org != org || pj.Spec.Refs.Repo != repo {
+		c.Logger.WithField("prowjob", pj.Name).Debug("Check run does not match a presubmit of the repository, skipping.")
+		return nil
+	}
+	number := pj.Spec.Refs.Pulls[0].Number
+
+	trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedOrg, cre.Sender.Login, org, repo)
+	if err != nil {
+		return fmt.Errorf("error checking trust of %s: %w", cre.Sender.Login, err)
+	}
+	if !trustedResponse.IsTrusted {
+		c.Logger.Infof("Ignoring re-run request of %s by untrusted user %s: %s", pj.Spec.Job, cre.Sender.Login, trustedResponse.Reason)
+		return nil
+	}
+
+	refGetter := config.NewRefGetterForGitHubPullRequest(c.GitHubClient, org, repo, number)
+	pr, err := refGetter.PullRequest()
+	if err != nil {
+		return err
+	}
+	if pr.State != github.PullRequestStateOpen {
+		c.Logger.Debugf("Pull request %d is %s, skipping.", number, pr.State)
+		return nil
+	}
+	baseSHA, err := refGetter.BaseSHA()
+	if err != nil {
+		return err
+	}
+	var toTest []config.Presubmit
+	for _, presubmit := range getPresubmits(c.Logger, c.GitClient, c.Config, org+"/"+repo, refGetter.BaseSHA, refGetter.HeadSHA) {
+		if presubmit.Name == pj.Spec.Job && presubmit.CouldRun(pr.Base.Ref) {
+			toTest = append(toTest, presubmit)
+		}
+	}
+	if len(toTest) == 0 {
+		c.Logger.Infof("Presubmit %s no longer runs on pull request %d, skipping.", pj.Spec.Job, number)
+		return nil
+	}
+	return RunRequestedWithLabels(c, pr, baseSHA, toTest, cre.GUID, map[string]string{kube.RetestLabel: "true"})
+}
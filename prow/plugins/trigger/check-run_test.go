@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/client/clientset/versioned/fake"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/github/fakegithub"
+	"k8s.io/test-infra/prow/kube"
+	"k8s.io/test-infra/prow/pjutil"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+func TestHandleCheckRun(t *testing.T) {
+	reportedJob := func(jobType prowapi.ProwJobType, repo string) *prowapi.ProwJob {
+		return &prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "reported", Namespace: "prowjobs"},
+			Spec: prowapi.ProwJobSpec{
+				Type: jobType,
+				Job:  "pull-job",
+				Refs: &prowapi.Refs{
+					Org:   "org",
+					Repo:  repo,
+					Pulls: []prowapi.Pull{{Number: 1, SHA: "cafe"}},
+				},
+			},
+		}
+	}
+	rerun := &github.CheckRunRequestedAction{Identifier: pjutil.RerunCheckRunAction}
+
+	testCases := []struct {
+		name            string
+		action          github.CheckRunEventAction
+		requestedAction *github.CheckRunRequestedAction
+		externalID      string
+		sender          string
+		prState         string
+		prowJob         *prowapi.ProwJob
+		expectedJobs    []string
+	}{
+		{
+			name:            "re-run action by a trusted user",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: rerun,
+			externalID:      "reported",
+			sender:          "trusted-member",
+			prowJob:         reportedJob(prowapi.PresubmitJob, "repo"),
+			expectedJobs:    []string{"pull-job"},
+		},
+		{
+			name:         "re-request by a trusted user",
+			action:       github.CheckRunActionRerequested,
+			externalID:   "reported",
+			sender:       "trusted-member",
+			prowJob:      reportedJob(prowapi.PresubmitJob, "repo"),
+			expectedJobs: []string{"pull-job"},
+		},
+		{
+			name:            "re-run action by an untrusted user",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: rerun,
+			externalID:      "reported",
+			sender:          "untrusted",
+			prowJob:         reportedJob(prowapi.PresubmitJob, "repo"),
+		},
+		{
+			name:            "unknown action",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: &github.CheckRunRequestedAction{Identifier: "something-else"},
+			externalID:      "reported",
+			sender:          "trusted-member",
+			prowJob:         reportedJob(prowapi.PresubmitJob, "repo"),
+		},
+		{
+			name:       "completed check run",
+			action:     github.CheckRunActionCompleted,
+			externalID: "reported",
+			sender:     "trusted-member",
+			prowJob:    reportedJob(prowapi.PresubmitJob, "repo"),
+		},
+		{
+			name:            "check run of another app",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: rerun,
+			externalID:      "not-a-prowjob",
+			sender:          "trusted-member",
+			prowJob:         reportedJob(prowapi.PresubmitJob, "repo"),
+		},
+		{
+			name:            "postsubmit",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: rerun,
+			externalID:      "reported",
+			sender:          "trusted-member",
+			prowJob:         reportedJob(prowapi.PostsubmitJob, "repo"),
+		},
+		{
+			name:            "presubmit of another repository",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: rerun,
+			externalID:      "reported",
+			sender:          "trusted-member",
+			prowJob:         reportedJob(prowapi.PresubmitJob, "other-repo"),
+		},
+		{
+			name:            "closed pull request",
+			action:          github.CheckRunActionRequestedAction,
+			requestedAction: rerun,
+			externalID:      "reported",
+			sender:          "trusted-member",
+			prState:         github.PullRequestStateClosed,
+			prowJob:         reportedJob(prowapi.PresubmitJob, "repo"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.prState == "" {
+				tc.prState = github.PullRequestStateOpen
+			}
+			g := fakegithub.NewFakeClient()
+			g.OrgMembers = map[string][]string{"org": {"trusted-member"}}
+			g.PullRequests = map[int]*github.PullRequest{
+				1: {
+					Number: 1,
+					State:  tc.prState,
+					Head:   github.PullRequestBranch{SHA: "cafe"},
+					Base: github.PullRequestBranch{
+						Ref:  "master",
+						Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+					},
+				},
+			}
+			fakeConfig := &config.Config{ProwConfig: config.ProwConfig{ProwJobNamespace: "prowjobs"}}
+			if err := fakeConfig.SetPresubmits(map[string][]config.Presubmit{
+				"org/repo": {{
+					JobBase:  config.JobBase{Name: "pull-job"},
+					Reporter: config.Reporter{Context: "pull-job"},
+				}},
+			}); err != nil {
+				t.Fatalf("failed to set presubmits: %v", err)
+			}
+			fakeProwJobClient := fake.NewSimpleClientset(tc.prowJob)
+			c := Client{
+				GitHubClient:  g,
+				ProwJobClient: fakeProwJobClient.ProwV1().ProwJobs("prowjobs"),
+				Config:        fakeConfig,
+				Logger:        logrus.WithField("plugin", PluginName),
+			}
+			event := github.CheckRunEvent{
+				Action:          tc.action,
+				CheckRun:        github.CheckRun{ExternalID: tc.externalID, Name: "pull-job"},
+				RequestedAction: tc.requestedAction,
+				Repo:            github.Repo{Owner: github.User{Login: "org"}, Name: "repo", FullName: "org/repo"},
+				Sender:          github.User{Login: tc.sender},
+				GUID:            "guid",
+			}
+			if err := handleCheckRun(c, plugins.Trigger{}, event); err != nil {
+				t.Fatalf("Didn't expect error: %v", err)
+			}
+
+			prowJobs, err := fakeProwJobClient.ProwV1().ProwJobs("prowjobs").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Failed to list ProwJobs: %v", err)
+			}
+			var createdJobs []string
+			for _, pj := range prowJobs.Items {
+				if pj.Name == tc.prowJob.Name {
+					continue
+				}
+				if pj.Labels[kube.RetestLabel] != "true" {
+					t.Errorf("Expected ProwJob %s to be labeled as a re-test.", pj.Name)
+				}
+				createdJobs = append(createdJobs, pj.Spec.Job)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, createdJobs); diff != "" {
+				t.Errorf("Created jobs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
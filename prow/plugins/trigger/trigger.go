@@ -75,6 +75,7 @@ func init() {
 	plugins.RegisterGenericCommentHandler(PluginName, handleGenericCommentEvent, helpProvider)
 	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
 	plugins.RegisterPushEventHandler(PluginName, handlePush, helpProvider)
+	plugins.RegisterCheckRunEventHandler(PluginName, handleCheckRunEvent, helpProvider)
 }
 
 func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
@@ -110,6 +111,7 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 <br>Trigger will not automatically start jobs for a PR in draft state, and if a PR is changed to draft it cancels pending jobs.
 <br>If jobs are not run automatically for a PR because it is not trusted or is in draft state, a trusted user can still start jobs manually via the '/test' command.
 <br>The '/retest' command can be used to rerun jobs that have reported failure.
+<br>Presubmits reported as GitHub check runs can also be rerun by trusted users through the 'Re-run' action of the check run.
 <br>Trigger starts postsubmit jobs when commits are pushed if the filters on the job match files and branches affected by that push.`,
 		Config:  configInfo,
 		Snippet: yamlSnippet,
@@ -172,6 +174,7 @@ type trustedPullRequestClient interface {
 
 type prowJobClient interface {
 	Create(context.Context, *prowapi.ProwJob, metav1.CreateOptions) (*prowapi.ProwJob, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*prowapi.ProwJob, error)
 	List(ctx context.Context, opts metav1.ListOptions) (*prowapi.ProwJobList, error)
 	Update(context.Context, *prowapi.ProwJob, metav1.UpdateOptions) (*prowapi.ProwJob, error)
 }
@@ -217,6 +220,10 @@ func handlePush(pc plugins.Agent, pe github.PushEvent) error {
 	return handlePE(getClient(pc), pe)
 }
 
+func handleCheckRunEvent(pc plugins.Agent, cre github.CheckRunEvent) error {
+	return handleCheckRun(getClient(pc), pc.PluginConfig.TriggerFor(cre.Repo.Owner.Login, cre.Repo.Name), cre)
+}
+
 // TrustedUserResponse is a response from TrustedUser. It contains the boolean response for trust as well
 // a reason for denial if the user is not trusted.
 type TrustedUserResponse struct {
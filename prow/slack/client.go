@@ -72,6 +72,7 @@ type Client struct {
 
 const (
 	chatPostMessage = "https://slack.com/api/chat.postMessage"
+	chatUpdate      = "https://slack.com/api/chat.update"
 
 	botName      = "prow"
 	botIconEmoji = ":prow:"
@@ -111,28 +112,32 @@ func (sl *Client) urlValues() *url.Values {
 	return &uv
 }
 
-func (sl *Client) postMessage(url string, uv *url.Values) error {
+type apiResponse struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+	// Channel and TS identify the posted or updated message.
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+func (sl *Client) postMessage(url string, uv *url.Values) (*apiResponse, error) {
 	resp, err := http.PostForm(url, *uv)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	apiResponse := struct {
-		Ok    bool   `json:"ok"`
-		Error string `json:"error"`
-	}{}
-
+	var apiResponse apiResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return fmt.Errorf("API returned invalid JSON (%q): %w", string(body), err)
+		return nil, fmt.Errorf("API returned invalid JSON (%q): %w", string(body), err)
 	}
 
 	if resp.StatusCode != 200 || !apiResponse.Ok {
-		return fmt.Errorf("request failed: %s", apiResponse.Error)
+		return nil, fmt.Errorf("request failed: %s", apiResponse.Error)
 	}
 
-	return nil
+	return &apiResponse, nil
 }
 
 // WriteMessage adds text to channel
@@ -146,8 +151,69 @@ func (sl *Client) WriteMessage(text, channel string) error {
 	uv.Add("channel", channel)
 	uv.Add("text", text)
 
-	if err := sl.postMessage(chatPostMessage, uv); err != nil {
+	if _, err := sl.postMessage(chatPostMessage, uv); err != nil {
 		return fmt.Errorf("failed to post message to %s: %w", channel, err)
 	}
 	return nil
 }
+
+// Message is a message posted or updated by the client.
+type Message struct {
+	Channel string
+	// Text is the content of the message, or its fallback text for the
+	// notifications when it has Blocks.
+	Text string
+	// Blocks is the JSON array of the Block Kit blocks of the message, if any.
+	Blocks string
+	// ThreadTS is the timestamp of the message to reply to in a thread, if any.
+	ThreadTS string
+}
+
+func (msg *Message) addTo(uv *url.Values) {
+	uv.Add("text", msg.Text)
+	if msg.Blocks != "" {
+		uv.Add("blocks", msg.Blocks)
+	}
+	if msg.ThreadTS != "" {
+		uv.Add("thread_ts", msg.ThreadTS)
+	}
+}
+
+// PostMessage posts the message and returns the ID of its channel and its
+// timestamp, which identify it for later updates or replies.
+func (sl *Client) PostMessage(msg Message) (string, string, error) {
+	sl.log("PostMessage", msg)
+	if sl.fake {
+		return msg.Channel, "", nil
+	}
+
+	var uv = sl.urlValues()
+	uv.Add("channel", msg.Channel)
+	msg.addTo(uv)
+
+	resp, err := sl.postMessage(chatPostMessage, uv)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to post message to %s: %w", msg.Channel, err)
+	}
+	return resp.Channel, resp.TS, nil
+}
+
+// UpdateMessage replaces the content of the message posted at the timestamp
+// in the channel, which must be the ID returned by PostMessage.
+func (sl *Client) UpdateMessage(channelID, timestamp string, msg Message) error {
+	sl.log("UpdateMessage", channelID, timestamp, msg)
+	if sl.fake {
+		return nil
+	}
+
+	uv := &url.Values{}
+	uv.Add("token", string(sl.tokenGenerator()))
+	uv.Add("channel", channelID)
+	uv.Add("ts", timestamp)
+	msg.addTo(uv)
+
+	if _, err := sl.postMessage(chatUpdate, uv); err != nil {
+		return fmt.Errorf("failed to update message %s in %s: %w", timestamp, channelID, err)
+	}
+	return nil
+}
@@ -51,7 +51,7 @@ func (s *Spyglass) ListArtifacts(ctx context.Context, src string) ([]string, err
 		gcsKey = fmt.Sprintf("%s://%s", keyType, key)
 	}
 
-	artifactNames, err := s.StorageArtifactFetcher.artifacts(ctx, gcsKey)
+	artifactNames, err := s.StorageArtifactFetcher.Artifacts(ctx, gcsKey)
 	// Don't care errors that are not supposed logged as http errors, for example
 	// context cancelled error due to user cancelled request.
 	if err != nil && err != context.Canceled {
@@ -137,6 +137,12 @@ func (lens Lens) Body(artifacts []api.Artifact, resourceDir string, data string,
 	return buf.String()
 }
 
+// GetJVD parses the JUnit artifacts and groups their test results by status,
+// so that other components can summarize the results the way the lens does.
+func GetJVD(artifacts []api.Artifact) JVD {
+	return Lens{}.getJvd(artifacts)
+}
+
 func (lens Lens) getJvd(artifacts []api.Artifact) JVD {
 	type testResults struct {
 		// Group results based on their full path name
@@ -131,7 +131,7 @@ func (af *StorageArtifactFetcher) newStorageJobSource(storagePath string) (*stor
 // If no scheme is given we assume GS, e.g.:
 // * test-bucket/logs/sig-flexing/example-ci-run/403 or
 // * gs://test-bucket/logs/sig-flexing/example-ci-run/403
-func (af *StorageArtifactFetcher) artifacts(ctx context.Context, key string) ([]string, error) {
+func (af *StorageArtifactFetcher) Artifacts(ctx context.Context, key string) ([]string, error) {
 	src, err := af.newStorageJobSource(key)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get GCS job source from %s: %w", key, err)
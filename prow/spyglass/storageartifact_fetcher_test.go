@@ -247,7 +247,7 @@ func TestArtifacts_ListGCS(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(nested *testing.T) {
-			actualArtifacts, err := testAf.artifacts(context.Background(), tc.source)
+			actualArtifacts, err := testAf.Artifacts(context.Background(), tc.source)
 			if err != nil {
 				nested.Fatalf("Failed to get artifact names: %v", err)
 			}
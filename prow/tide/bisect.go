@@ -0,0 +1,283 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// batchCulpritRecord is the history action recorded when bisection finds a
+// PR breaking a batch.
+const batchCulpritRecord = "BATCH_CULPRIT"
+
+// batchCulprit is a PR that was found to break a batch by bisecting it.
+type batchCulprit struct {
+	pr CodeReviewCommon
+	// baseSHA is the SHA of the base branch the batch was tested on.
+	baseSHA string
+	// batch contains the PRs of the failed batch that was bisected.
+	batch []CodeReviewCommon
+	// failedContexts are the required contexts that failed when testing the
+	// culprit alone on top of the base branch.
+	failedContexts []string
+}
+
+func (bc *batchCulprit) key() string {
+	return fmt.Sprintf("%s@%s:%s", prKey(&bc.pr), bc.pr.HeadRefOID, bc.baseSHA)
+}
+
+// statusDescription describes why the culprit is not merged, for the tide
+// status context.
+func (bc *batchCulprit) statusDescription() string {
+	return fmt.Sprintf("Breaks batch testing: %s failed.", strings.Join(bc.failedContexts, ", "))
+}
+
+func (bc *batchCulprit) String() string {
+	return fmt.Sprintf("PR #%d breaks the batch %v: %s failed", bc.pr.Number, prNumbers(bc.batch), strings.Join(bc.failedContexts, ", "))
+}
+
+// batchBisection is the progress of the bisection of the failed batches of a
+// subpool.
+type batchBisection struct {
+	culprits []batchCulprit
+	// subBatches are the sub-batches that must be tested next.
+	subBatches [][]CodeReviewCommon
+	// bisecting are the failed batches whose culprit is not found yet.
+	bisecting [][]CodeReviewCommon
+}
+
+// holds returns whether the merge of a passing batch must be held because it
+// is a sub-batch of a batch being bisected: merging it would change the base
+// branch, and restart the bisection before the culprit is found.
+func (b *batchBisection) holds(batch []CodeReviewCommon) bool {
+	numbers := sets.New[int](prNumbers(batch)...)
+	for _, bisecting := range b.bisecting {
+		if sets.New[int](prNumbers(bisecting)...).IsSuperset(numbers) {
+			return true
+		}
+	}
+	return false
+}
+
+// bisectFailedBatches bisects the failed batches of the subpool, to find the
+// PRs breaking them.
+//
+// Bisection is stateless: it is driven by the batch jobs tested on the current
+// base SHA, so it starts over whenever the base branch changes. The merges of
+// the passing sub-batches are held until the culprit is found for that reason.
+// Sub-batches are tested as batch jobs, even when they contain a single PR, so
+// that their results are not reported on the PRs.
+func (c *syncController) bisectFailedBatches(sp subpool) batchBisection {
+	results := make(map[string]*batchResult)
+	var failed []*batchResult
+	for _, res := range c.accumulateBatchResults(sp) {
+		results[batchKey(res.prs)] = res
+		if len(res.prs) > 1 && len(res.failedContexts) > 0 {
+			failed = append(failed, res)
+		}
+	}
+	// Start with the largest batches, as the smaller failed batches are
+	// usually their sub-batches.
+	sort.Slice(failed, func(i, j int) bool {
+		if len(failed[i].prs) != len(failed[j].prs) {
+			return len(failed[i].prs) > len(failed[j].prs)
+		}
+		return batchKey(failed[i].prs) < batchKey(failed[j].prs)
+	})
+
+	var res batchBisection
+	culprits := sets.New[int]()
+	subBatches := sets.New[string]()
+	for _, batch := range failed {
+		if culprits.HasAny(prNumbers(batch.prs)...) {
+			// The failure of this batch is already explained.
+			continue
+		}
+		culprit, failedContexts, toTest, inProgress := bisectBatch(batch.prs, results)
+		if inProgress {
+			res.bisecting = append(res.bisecting, batch.prs)
+		}
+		if culprit != nil {
+			culprits.Insert(culprit.Number)
+			res.culprits = append(res.culprits, batchCulprit{
+				pr:             *culprit,
+				baseSHA:        sp.sha,
+				batch:          sortedByNumber(batch.prs),
+				failedContexts: failedContexts,
+			})
+		}
+		for _, subBatch := range toTest {
+			if key := batchKey(subBatch); !subBatches.Has(key) {
+				subBatches.Insert(key)
+				res.subBatches = append(res.subBatches, subBatch)
+			}
+		}
+	}
+	return res
+}
+
+// bisectBatch walks down the bisection of a failed batch: the batch is split in
+// two halves and the first failing half is bisected in turn, until a single PR
+// fails. It returns either that PR and its failed contexts, or the halves that
+// must be tested to go on. Both halves are tested at the same time, speculating
+// that either of them could be the failing one.
+// Nothing is returned while halves are pending, or when both halves pass, which
+// means that the batch only fails when all of its PRs are combined, or flaked.
+// The bisection is in progress until either a culprit is found or both halves
+// pass.
+func bisectBatch(batch []CodeReviewCommon, results map[string]*batchResult) (culprit *CodeReviewCommon, failedContexts []string, toTest [][]CodeReviewCommon, inProgress bool) {
+	prs := sortedByNumber(batch)
+	for len(prs) > 1 {
+		var failing *batchResult
+		var untested [][]CodeReviewCommon
+		pending := false
+		for _, half := range [][]CodeReviewCommon{prs[:len(prs)/2], prs[len(prs)/2:]} {
+			res, ok := results[batchKey(half)]
+			switch {
+			case ok && len(res.failedContexts) > 0:
+				if failing == nil {
+					failing = res
+				}
+			case !ok || res.state == failureState:
+				// Either the half was never tested, or some of its required
+				// presubmits are missing.
+				untested = append(untested, half)
+			case res.state == pendingState:
+				pending = true
+			}
+		}
+		if failing == nil {
+			return nil, nil, untested, pending || len(untested) > 0
+		}
+		if len(failing.prs) == 1 {
+			return &failing.prs[0], failing.failedContexts, nil, false
+		}
+		prs = sortedByNumber(failing.prs)
+	}
+	return nil, nil, nil, false
+}
+
+// triggerSubBatches triggers the required presubmits of the sub-batches of a
+// bisection.
+func (c *syncController) triggerSubBatches(sp subpool, subBatches [][]CodeReviewCommon) error {
+	var errs []error
+	for _, subBatch := range subBatches {
+		presubmits, err := c.presubmitsForBatch(subBatch, sp.org, sp.repo, sp.sha, sp.branch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed getting presubmits for PRs %v: %w", prNumbers(subBatch), err))
+			continue
+		}
+		if err := c.triggerJobs(sp, presubmits, subBatch, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// updateBatchCulprits stores the culprits found in a subpool, recording the
+// ones that were not known yet in the history.
+func (c *syncController) updateBatchCulprits(sp subpool, culprits []batchCulprit) {
+	key := poolKey(sp.org, sp.repo, sp.branch)
+	c.batchCulpritsLock.Lock()
+	defer c.batchCulpritsLock.Unlock()
+
+	known := sets.New[string]()
+	for _, culprit := range c.batchCulprits[key] {
+		known.Insert(culprit.key())
+	}
+	for _, culprit := range culprits {
+		if known.Has(culprit.key()) {
+			continue
+		}
+		sp.log.WithFields(culprit.pr.logFields()).WithFields(logrus.Fields{
+			"batch":           prNumbers(culprit.batch),
+			"failed-contexts": culprit.failedContexts,
+		}).Info("Bisection found a PR breaking a batch.")
+		c.History.Record(key, batchCulpritRecord, sp.sha, culprit.String(), prMeta(culprit.pr), sp.TenantIDs())
+	}
+
+	if c.batchCulprits == nil {
+		c.batchCulprits = map[string][]batchCulprit{}
+	}
+	if len(culprits) == 0 {
+		delete(c.batchCulprits, key)
+	} else {
+		c.batchCulprits[key] = culprits
+	}
+}
+
+// batchCulpritsByPR forgets the culprits of the pools that are gone, and
+// returns the remaining ones by PR key.
+func (c *syncController) batchCulpritsByPR(pools map[string]*subpool) map[string]batchCulprit {
+	c.batchCulpritsLock.Lock()
+	defer c.batchCulpritsLock.Unlock()
+
+	res := map[string]batchCulprit{}
+	for key, culprits := range c.batchCulprits {
+		if _, ok := pools[key]; !ok {
+			delete(c.batchCulprits, key)
+			continue
+		}
+		for _, culprit := range culprits {
+			res[prKey(&culprit.pr)] = culprit
+		}
+	}
+	return res
+}
+
+// withoutBatchCulprits filters out the culprits from the PRs.
+func withoutBatchCulprits(prs []CodeReviewCommon, culprits []batchCulprit) []CodeReviewCommon {
+	if len(culprits) == 0 {
+		return prs
+	}
+	numbers := sets.New[int]()
+	for _, culprit := range culprits {
+		numbers.Insert(culprit.pr.Number)
+	}
+	var res []CodeReviewCommon
+	for _, pr := range prs {
+		if !numbers.Has(pr.Number) {
+			res = append(res, pr)
+		}
+	}
+	return res
+}
+
+// batchKey identifies a batch by the numbers of its PRs.
+func batchKey(prs []CodeReviewCommon) string {
+	numbers := prNumbers(prs)
+	sort.Ints(numbers)
+	var res []string
+	for _, number := range numbers {
+		res = append(res, strconv.Itoa(number))
+	}
+	return strings.Join(res, "|")
+}
+
+func sortedByNumber(prs []CodeReviewCommon) []CodeReviewCommon {
+	res := make([]CodeReviewCommon, len(prs))
+	copy(res, prs)
+	sort.Slice(res, func(i, j int) bool { return res[i].Number < res[j].Number })
+	return res
+}
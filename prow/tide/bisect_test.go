@@ -0,0 +1,360 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/tide/history"
+)
+
+func bisectTestPRs(numbers ...int) []CodeReviewCommon {
+	var res []CodeReviewCommon
+	for _, number := range numbers {
+		res = append(res, CodeReviewCommon{Number: number, HeadRefOID: "head"})
+	}
+	return res
+}
+
+func batchKeys(batches [][]CodeReviewCommon) []string {
+	var res []string
+	for _, batch := range batches {
+		res = append(res, batchKey(batch))
+	}
+	return res
+}
+
+func TestBisectBatch(t *testing.T) {
+	failure := func(numbers ...int) *batchResult {
+		return &batchResult{prs: bisectTestPRs(numbers...), state: failureState, failedContexts: []string{"foo"}}
+	}
+	success := func(numbers ...int) *batchResult {
+		return &batchResult{prs: bisectTestPRs(numbers...), state: successState}
+	}
+	pending := func(numbers ...int) *batchResult {
+		return &batchResult{prs: bisectTestPRs(numbers...), state: pendingState}
+	}
+
+	tests := []struct {
+		name           string
+		batch          []int
+		results        []*batchResult
+		wantCulprit    int
+		wantSubBatches []string
+		wantInProgress bool
+	}{
+		{
+			name:           "bisection starts by testing both halves",
+			batch:          []int{4, 3, 2, 1},
+			wantSubBatches: []string{"1|2", "3|4"},
+			wantInProgress: true,
+		},
+		{
+			name:           "odd batches are split with the larger half last",
+			batch:          []int{1, 2, 3},
+			wantSubBatches: []string{"1", "2|3"},
+			wantInProgress: true,
+		},
+		{
+			name:           "both halves pending",
+			batch:          []int{1, 2, 3, 4},
+			results:        []*batchResult{pending(1, 2), pending(3, 4)},
+			wantInProgress: true,
+		},
+		{
+			name:           "missing half is tested",
+			batch:          []int{1, 2, 3, 4},
+			results:        []*batchResult{pending(1, 2)},
+			wantSubBatches: []string{"3|4"},
+			wantInProgress: true,
+		},
+		{
+			name:           "half with missing required presubmits is tested",
+			batch:          []int{1, 2, 3, 4},
+			results:        []*batchResult{success(1, 2), {prs: bisectTestPRs(3, 4), state: failureState}},
+			wantSubBatches: []string{"3|4"},
+			wantInProgress: true,
+		},
+		{
+			name:    "both halves pass",
+			batch:   []int{1, 2, 3, 4},
+			results: []*batchResult{success(1, 2), success(3, 4)},
+		},
+		{
+			name:           "failing half is bisected without waiting for the other one",
+			batch:          []int{1, 2, 3, 4},
+			results:        []*batchResult{pending(1, 2), failure(3, 4)},
+			wantSubBatches: []string{"3", "4"},
+			wantInProgress: true,
+		},
+		{
+			name:        "single failing PR is the culprit",
+			batch:       []int{1, 2, 3, 4},
+			results:     []*batchResult{success(1, 2), failure(3, 4), success(3), failure(4)},
+			wantCulprit: 4,
+		},
+		{
+			name:        "first culprit is found when both halves fail",
+			batch:       []int{1, 2, 3, 4},
+			results:     []*batchResult{failure(1, 2), failure(3, 4), failure(1), pending(2)},
+			wantCulprit: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			results := map[string]*batchResult{}
+			for _, res := range tc.results {
+				results[batchKey(res.prs)] = res
+			}
+			culprit, failedContexts, subBatches, inProgress := bisectBatch(bisectTestPRs(tc.batch...), results)
+			var gotCulprit int
+			if culprit != nil {
+				gotCulprit = culprit.Number
+				if diff := cmp.Diff([]string{"foo"}, failedContexts); diff != "" {
+					t.Errorf("failed contexts mismatch (-want +got):\n%s", diff)
+				}
+			}
+			if gotCulprit != tc.wantCulprit {
+				t.Errorf("expected culprit %d, got %d", tc.wantCulprit, gotCulprit)
+			}
+			if diff := cmp.Diff(tc.wantSubBatches, batchKeys(subBatches)); diff != "" {
+				t.Errorf("sub-batches mismatch (-want +got):\n%s", diff)
+			}
+			if inProgress != tc.wantInProgress {
+				t.Errorf("expected bisection in progress %t, got %t", tc.wantInProgress, inProgress)
+			}
+		})
+	}
+}
+
+func TestBatchBisectionHolds(t *testing.T) {
+	bisection := batchBisection{bisecting: [][]CodeReviewCommon{bisectTestPRs(1, 2, 3, 4)}}
+	tests := []struct {
+		name  string
+		batch []int
+		want  bool
+	}{
+		{name: "sub-batch", batch: []int{3, 4}, want: true},
+		{name: "single PR sub-batch", batch: []int{2}, want: true},
+		{name: "bisected batch", batch: []int{1, 2, 3, 4}, want: true},
+		{name: "other batch", batch: []int{4, 5}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := bisection.holds(bisectTestPRs(tc.batch...)); got != tc.want {
+				t.Errorf("expected holds to be %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBisectFailedBatches(t *testing.T) {
+	type prowjob struct {
+		prs   []int
+		job   string
+		state prowapi.ProwJobState
+	}
+	tests := []struct {
+		name           string
+		prowJobs       []prowjob
+		wantCulprits   map[int][]string
+		wantSubBatches []string
+		wantBisecting  []string
+	}{
+		{
+			name: "passing batch",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.SuccessState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.SuccessState},
+			},
+		},
+		{
+			name: "pending batch",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.SuccessState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.PendingState},
+			},
+		},
+		{
+			name: "batch is bisected as soon as a job fails",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.PendingState},
+			},
+			wantSubBatches: []string{"1|2", "3|4"},
+			wantBisecting:  []string{"1|2|3|4"},
+		},
+		{
+			name: "batch missing a job is not bisected",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.SuccessState},
+			},
+		},
+		{
+			name: "failed batch is bisected",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.SuccessState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.FailureState},
+			},
+			wantSubBatches: []string{"1|2", "3|4"},
+			wantBisecting:  []string{"1|2|3|4"},
+		},
+		{
+			name: "failed job is retried",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.SuccessState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.FailureState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.SuccessState},
+			},
+		},
+		{
+			name: "culprit is found",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2, 3, 4}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{1, 2, 3, 4}, job: "bar", state: prowapi.FailureState},
+				{prs: []int{1, 2}, job: "foo", state: prowapi.SuccessState},
+				{prs: []int{1, 2}, job: "bar", state: prowapi.SuccessState},
+				{prs: []int{3, 4}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{3, 4}, job: "bar", state: prowapi.SuccessState},
+				{prs: []int{3}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{3}, job: "bar", state: prowapi.SuccessState},
+				{prs: []int{4}, job: "foo", state: prowapi.PendingState},
+				{prs: []int{4}, job: "bar", state: prowapi.SuccessState},
+			},
+			wantCulprits: map[int][]string{3: {"foo"}},
+		},
+		{
+			name: "failed batches explained by a culprit are not bisected",
+			prowJobs: []prowjob{
+				{prs: []int{1, 2}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{1, 2}, job: "bar", state: prowapi.SuccessState},
+				{prs: []int{1}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{1}, job: "bar", state: prowapi.FailureState},
+				{prs: []int{1, 3, 4}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{1, 3, 4}, job: "bar", state: prowapi.SuccessState},
+				{prs: []int{3, 4, 5}, job: "foo", state: prowapi.FailureState},
+				{prs: []int{3, 4, 5}, job: "bar", state: prowapi.SuccessState},
+			},
+			wantCulprits:   map[int][]string{1: {"bar", "foo"}},
+			wantSubBatches: []string{"3", "4|5"},
+			wantBisecting:  []string{"3|4|5"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var pjs []prowapi.ProwJob
+			for _, pj := range tc.prowJobs {
+				npj := prowapi.ProwJob{
+					Spec: prowapi.ProwJobSpec{
+						Job:     pj.job,
+						Context: pj.job,
+						Type:    prowapi.BatchJob,
+						Refs:    &prowapi.Refs{Org: "org", Repo: "repo", BaseSHA: "base"},
+					},
+					Status: prowapi.ProwJobStatus{State: pj.state},
+				}
+				for _, number := range pj.prs {
+					npj.Spec.Refs.Pulls = append(npj.Spec.Refs.Pulls, prowapi.Pull{Number: number, SHA: "head"})
+				}
+				pjs = append(pjs, npj)
+			}
+			cfg := func() *config.Config {
+				return &config.Config{
+					JobConfig: config.JobConfig{
+						PresubmitsStatic: map[string][]config.Presubmit{
+							"org/repo": {
+								{AlwaysRun: true, Reporter: config.Reporter{Context: "foo"}},
+								{AlwaysRun: true, Reporter: config.Reporter{Context: "bar"}},
+							},
+						},
+					},
+				}
+			}
+			c := &syncController{
+				config:       cfg,
+				provider:     newGitHubProvider(logrus.WithContext(context.Background()), nil, nil, cfg, nil, false),
+				changedFiles: &changedFilesAgent{},
+				logger:       logrus.WithField("test", tc.name),
+			}
+			sp := subpool{org: "org", repo: "repo", sha: "base", prs: bisectTestPRs(1, 2, 3, 4, 5), pjs: pjs, log: logrus.WithField("test", tc.name)}
+
+			bisection := c.bisectFailedBatches(sp)
+			var gotCulprits map[int][]string
+			for _, culprit := range bisection.culprits {
+				if gotCulprits == nil {
+					gotCulprits = map[int][]string{}
+				}
+				gotCulprits[culprit.pr.Number] = culprit.failedContexts
+			}
+			if diff := cmp.Diff(tc.wantCulprits, gotCulprits); diff != "" {
+				t.Errorf("culprits mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantSubBatches, batchKeys(bisection.subBatches)); diff != "" {
+				t.Errorf("sub-batches mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantBisecting, batchKeys(bisection.bisecting)); diff != "" {
+				t.Errorf("bisected batches mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateBatchCulprits(t *testing.T) {
+	hist, err := history.New(100, nil, "")
+	if err != nil {
+		t.Fatalf("failed to create history client: %v", err)
+	}
+	c := &syncController{History: hist}
+	sp := subpool{org: "org", repo: "repo", branch: "main", sha: "base", log: logrus.WithField("test", t.Name())}
+	culprit := batchCulprit{pr: CodeReviewCommon{NameWithOwner: "org/repo", Number: 1, HeadRefOID: "head"}, baseSHA: "base", batch: bisectTestPRs(1, 2), failedContexts: []string{"foo"}}
+
+	// The culprit is found by the next syncs too, but only recorded once.
+	c.updateBatchCulprits(sp, []batchCulprit{culprit})
+	c.updateBatchCulprits(sp, []batchCulprit{culprit})
+
+	records := hist.AllRecords()["org/repo:main"]
+	if len(records) != 1 {
+		t.Fatalf("expected a single record, got %d", len(records))
+	}
+	if records[0].Action != batchCulpritRecord || records[0].Err != "PR #1 breaks the batch [1 2]: foo failed" {
+		t.Errorf("unexpected record %+v", records[0])
+	}
+
+	got := c.batchCulpritsByPR(map[string]*subpool{"org/repo:main": &sp})
+	if _, ok := got["org/repo#1"]; !ok || len(got) != 1 {
+		t.Errorf("expected the culprit org/repo#1, got %v", got)
+	}
+	if got := c.batchCulpritsByPR(nil); len(got) != 0 {
+		t.Errorf("expected the culprits of removed pools to be forgotten, got %v", got)
+	}
+
+	su := &statusUpdate{batchCulprits: map[string]batchCulprit{"org/repo#1": culprit}}
+	if _, ok := su.batchCulprit(&culprit.pr, "base"); !ok {
+		t.Error("expected the PR to be a batch culprit")
+	}
+	if _, ok := su.batchCulprit(&culprit.pr, "new-base"); ok {
+		t.Error("expected the PR not to be a batch culprit once the base changed")
+	}
+}
@@ -29,6 +29,7 @@ import (
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/git/types"
+	"k8s.io/test-infra/prow/gitlab"
 	"k8s.io/test-infra/prow/tide/blockers"
 
 	githubql "github.com/shurcooL/githubv4"
@@ -107,6 +108,7 @@ type CodeReviewCommon struct {
 
 	GitHub *PullRequest
 	Gerrit *gerrit.ChangeInfo
+	GitLab *gitlab.MergeRequest
 }
 
 func (crc *CodeReviewCommon) logFields() logrus.Fields {
@@ -141,6 +143,22 @@ func (crc *CodeReviewCommon) GitHubCommits() *Commits {
 	return &crc.GitHub.Commits
 }
 
+// labels returns the names of the labels of the PR, for the code review
+// providers that support labels, namely GitHub and GitLab.
+func (crc *CodeReviewCommon) labels() []string {
+	switch {
+	case crc.GitHub != nil:
+		labels := make([]string, 0, len(crc.GitHub.Labels.Nodes))
+		for _, l := range crc.GitHub.Labels.Nodes {
+			labels = append(labels, string(l.Name))
+		}
+		return labels
+	case crc.GitLab != nil:
+		return crc.GitLab.Labels
+	}
+	return nil
+}
+
 // Regexp used to compile regular expressions and use it in CommitTemplate.
 func (CodeReviewCommon) Regexp(pattern string) *regexp.Regexp {
 	return regexp.MustCompile(pattern)
@@ -245,8 +263,61 @@ func CodeReviewCommonFromGerrit(gci *gerrit.ChangeInfo, instance string) *CodeRe
 	return crc
 }
 
+// CodeReviewCommonFromGitLab derives CodeReviewCommon struct from GitLab
+// MergeRequest struct, by extracting shared fields among different code review
+// providers.
+//
+// GitLab projects can be nested in subgroups, so the org is the top-level group
+// of the project and the repo is the rest of its path, such as "group" and
+// "subgroup/project". That way "org/repo" is always the full path of the
+// project and can't be mistaken for the path of a subgroup.
+func CodeReviewCommonFromGitLab(mr *gitlab.MergeRequest, project string) *CodeReviewCommon {
+	if mr == nil {
+		return nil
+	}
+	// Make a copy
+	mrCopy := *mr
+
+	mergeable := string(githubql.MergeableStateUnknown)
+	if mr.HasConflicts {
+		mergeable = string(githubql.MergeableStateConflicting)
+	} else if mr.MergeStatus == gitlab.MergeStatusCanBeMerged {
+		mergeable = string(githubql.MergeableStateMergeable)
+	}
+	org, repo := splitGitLabProject(project)
+	crc := &CodeReviewCommon{
+		NameWithOwner: project,
+		Number:        mr.IID,
+		Org:           org,
+		Repo:          repo,
+		BaseRefPrefix: "refs/heads/",
+		BaseRefName:   mr.TargetBranch,
+		HeadRefName:   mr.SourceBranch,
+		HeadRefOID:    mr.SHA,
+		Title:         mr.Title,
+		Body:          mr.Description,
+		AuthorLogin:   mr.Author.Username,
+		Mergeable:     mergeable,
+		UpdatedAtTime: mr.UpdatedAt,
+
+		GitLab: &mrCopy,
+	}
+
+	return crc
+}
+
+// splitGitLabProject splits the full path of a GitLab project into its
+// top-level group and the rest of its path.
+func splitGitLabProject(project string) (org, repo string) {
+	org, repo, found := strings.Cut(project, "/")
+	if !found {
+		return "", project
+	}
+	return org, repo
+}
+
 // provider is the interface implemented by each source code
-// providers, such as GitHub and Gerrit.
+// providers, such as GitHub, Gerrit and GitLab.
 type provider interface {
 	Query() (map[string]CodeReviewCommon, error)
 	blockers() (blockers.Blockers, error)
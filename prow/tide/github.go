@@ -553,6 +553,13 @@ func (m *mergeChecker) isAllowedToMerge(crc *CodeReviewCommon) (string, error) {
 // prMergeMethod figures out merge method based on tide config, this could be
 // overridden by GitHub labels.
 func (mc *mergeChecker) prMergeMethod(c config.Tide, crc *CodeReviewCommon) *types.PullRequestMergeType {
+	return prMergeMethodFromLabels(c, crc)
+}
+
+// prMergeMethodFromLabels figures out merge method based on tide config, this
+// could be overridden by the labels of the PR. It returns nil if the PR has
+// conflicting merge method labels.
+func prMergeMethodFromLabels(c config.Tide, crc *CodeReviewCommon) *types.PullRequestMergeType {
 	repo := config.OrgRepo{Org: crc.Org, Repo: crc.Repo}
 	method := c.OrgRepoBranchMergeMethod(repo, crc.BaseRefName)
 	squashLabel := c.SquashLabel
@@ -560,24 +567,22 @@ func (mc *mergeChecker) prMergeMethod(c config.Tide, crc *CodeReviewCommon) *typ
 	mergeLabel := c.MergeLabel
 	if squashLabel != "" || rebaseLabel != "" || mergeLabel != "" {
 		labelCount := 0
-		if labels := crc.GitHubLabels(); labels != nil {
-			for _, prlabel := range labels.Nodes {
-				switch string(prlabel.Name) {
-				case "":
-					continue
-				case squashLabel:
-					method = types.MergeSquash
-					labelCount++
-				case rebaseLabel:
-					method = types.MergeRebase
-					labelCount++
-				case mergeLabel:
-					method = types.MergeMerge
-					labelCount++
-				}
-				if labelCount > 1 {
-					return nil
-				}
+		for _, prlabel := range crc.labels() {
+			switch prlabel {
+			case "":
+				continue
+			case squashLabel:
+				method = types.MergeSquash
+				labelCount++
+			case rebaseLabel:
+				method = types.MergeRebase
+				labelCount++
+			case mergeLabel:
+				method = types.MergeMerge
+				labelCount++
+			}
+			if labelCount > 1 {
+				return nil
 			}
 		}
 	}
@@ -0,0 +1,559 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/git/types"
+	"k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/gitlab"
+	"k8s.io/test-infra/prow/io"
+	"k8s.io/test-infra/prow/kube"
+	"k8s.io/test-infra/prow/tide/blockers"
+	"k8s.io/test-infra/prow/tide/history"
+)
+
+// gitlabProjectCacheTTL is how long the merge settings of the GitLab projects
+// are cached.
+const gitlabProjectCacheTTL = time.Hour
+
+// NewGitLabController makes a Controller for merging the merge requests of a
+// GitLab instance.
+//
+// There is no status controller for GitLab, the merge requests that are not
+// merged are not given a tide status.
+func NewGitLabController(
+	mgr manager,
+	cfg config.Getter,
+	gc git.ClientFactory,
+	glc gitlab.Client,
+	maxRecordsPerPool int,
+	opener io.Opener,
+	historyURI string,
+	logger *logrus.Entry,
+) (*Controller, error) {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	hist, err := history.New(maxRecordsPerPool, opener, historyURI)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing history client from %q: %w", historyURI, err)
+	}
+
+	ctx := context.Background()
+	// Shared fields
+	statusUpdate := &statusUpdate{
+		dontUpdateStatus: &threadSafePRSet{},
+		newPoolPending:   make(chan bool),
+	}
+
+	provider := newGitLabProvider(logger, cfg, glc, gc, mgr.GetClient())
+	syncCtrl, err := newSyncController(ctx, logger, mgr, provider, cfg, gc, hist, false, statusUpdate)
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{syncCtrl: syncCtrl}, nil
+}
+
+// Enforcing interface implementation check at compile time
+var _ provider = (*GitLabProvider)(nil)
+
+// GitLabProvider implements provider, used by Tide Controller for
+// interacting directly with GitLab.
+//
+// The org of the merge requests is the top-level group of their project and
+// the repo is the rest of its path, such as "group" and "subgroup/project", so
+// the "org/repo" keys of the config are the full paths of the projects.
+type GitLabProvider struct {
+	cfg         config.Getter
+	glc         gitlab.Client
+	gc          git.ClientFactory
+	pjclientset ctrlruntimeclient.Client
+
+	projectsLock sync.Mutex
+	projects     map[string]cachedGitLabProject
+
+	logger *logrus.Entry
+}
+
+type cachedGitLabProject struct {
+	project *gitlab.Project
+	fetched time.Time
+}
+
+func newGitLabProvider(
+	logger *logrus.Entry,
+	cfg config.Getter,
+	glc gitlab.Client,
+	gc git.ClientFactory,
+	pjclientset ctrlruntimeclient.Client,
+) *GitLabProvider {
+	return &GitLabProvider{
+		logger:      logger,
+		cfg:         cfg,
+		glc:         glc,
+		gc:          gc,
+		pjclientset: pjclientset,
+		projects:    map[string]cachedGitLabProject{},
+	}
+}
+
+// Query returns the open merge requests selected by the GitLab queries.
+func (p *GitLabProvider) Query() (map[string]CodeReviewCommon, error) {
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	prs := make(map[string]CodeReviewCommon)
+	var errs []error
+	for i, query := range p.cfg().Tide.GitLab.Queries {
+		// GitLab can only filter on labels that are all required, the
+		// alternatives are filtered afterwards.
+		var labels []string
+		for _, l := range query.Labels {
+			if !strings.Contains(l, ",") {
+				labels = append(labels, l)
+			}
+		}
+		opts := gitlab.ListMergeRequestsOptions{
+			State:         gitlab.MergeRequestStateOpened,
+			Labels:        labels,
+			Milestone:     query.Milestone,
+			ExcludeDrafts: true,
+		}
+
+		for _, project := range query.Projects {
+			i, query, project := i, query, project
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				mrs, err := p.glc.ListMergeRequests(project, opts)
+
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					p.logger.WithField("project", project).WithError(err).Warn("Failed to list merge requests.")
+					errs = append(errs, fmt.Errorf("query %d, project %s: %w", i, project, err))
+					return
+				}
+				for _, mr := range mrs {
+					if !gitlabQueryMatches(query, &mr) {
+						continue
+					}
+					crc := CodeReviewCommonFromGitLab(&mr, project)
+					prs[prKey(crc)] = *crc
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	return prs, utilerrors.NewAggregate(errs)
+}
+
+// gitlabQueryMatches checks the criteria of the query that GitLab cannot filter
+// on.
+func gitlabQueryMatches(q config.GitLabTideQuery, mr *gitlab.MergeRequest) bool {
+	labels := sets.New[string](mr.Labels...)
+	for _, l := range q.Labels {
+		if !labels.HasAny(strings.Split(l, ",")...) {
+			return false
+		}
+	}
+	if labels.HasAny(q.MissingLabels...) {
+		return false
+	}
+	if len(q.IncludedBranches) > 0 && !sets.New[string](q.IncludedBranches...).Has(mr.TargetBranch) {
+		return false
+	}
+	return !sets.New[string](q.ExcludedBranches...).Has(mr.TargetBranch)
+}
+
+func (p *GitLabProvider) blockers() (blockers.Blockers, error) {
+	// This is not supported yet, so return an empty blocker for now.
+	return blockers.Blockers{}, nil
+}
+
+// project gets the merge settings of a project, which rarely change.
+func (p *GitLabProvider) project(path string) (*gitlab.Project, error) {
+	p.projectsLock.Lock()
+	defer p.projectsLock.Unlock()
+
+	if cached, ok := p.projects[path]; ok && time.Since(cached.fetched) < gitlabProjectCacheTTL {
+		return cached.project, nil
+	}
+	project, err := p.glc.GetProject(path)
+	if err != nil {
+		return nil, err
+	}
+	p.projects[path] = cachedGitLabProject{project: project, fetched: time.Now()}
+	return project, nil
+}
+
+func (p *GitLabProvider) isAllowedToMerge(crc *CodeReviewCommon) (string, error) {
+	if crc.Mergeable == string(githubql.MergeableStateConflicting) {
+		return "PR has a merge conflict.", nil
+	}
+	mergeMethod := p.prMergeMethod(crc)
+	if mergeMethod == nil {
+		return "PR has conflicting merge method override labels", nil
+	}
+	project, err := p.project(crc.NameWithOwner)
+	if err != nil {
+		return "", fmt.Errorf("error getting project data: %w", err)
+	}
+	if !gitlabMergeMethodAllowed(project, *mergeMethod) {
+		return fmt.Sprintf("Merge type %q disallowed by project settings", *mergeMethod), nil
+	}
+	return "", nil
+}
+
+// gitlabMergeMethodAllowed tells whether the merge method can be used with the
+// merge settings of the project. The project settings decide whether merge
+// commits are created, Tide can only decide whether commits are squashed.
+func gitlabMergeMethodAllowed(project *gitlab.Project, method types.PullRequestMergeType) bool {
+	if method == types.MergeSquash {
+		return project.SquashOption != gitlab.SquashOptionNever
+	}
+	if project.SquashOption == gitlab.SquashOptionAlways {
+		return false
+	}
+	switch method {
+	case types.MergeMerge:
+		return project.MergeMethod == gitlab.MergeMethodMerge || project.MergeMethod == gitlab.MergeMethodRebaseMerge
+	case types.MergeRebase:
+		return project.MergeMethod == gitlab.MergeMethodFastForward
+	case types.MergeIfNecessary:
+		return true
+	}
+	return false
+}
+
+// GetRef gets the latest revision of a branch, the ref is in the form of
+// "heads/<branch>".
+func (p *GitLabProvider) GetRef(org, repo, ref string) (string, error) {
+	branch, err := p.glc.GetBranch(org+"/"+repo, strings.TrimPrefix(ref, "heads/"))
+	if err != nil {
+		return "", err
+	}
+	return branch.Commit.ID, nil
+}
+
+// headContexts gets the status contexts for the commit with OID ==
+// pr.HeadRefOID.
+//
+// These are the commit statuses of GitLab, such as the GitLab CI jobs, and the
+// latest Prow jobs tested against the commit. Prow jobs take precedence over
+// commit statuses with the same name, as they might be reported late.
+func (p *GitLabProvider) headContexts(crc *CodeReviewCommon) ([]Context, error) {
+	statuses, err := p.glc.ListCommitStatuses(crc.NameWithOwner, crc.HeadRefOID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the commit statuses: %w", err)
+	}
+
+	// The repo label of the Prow jobs can't hold the path of projects in
+	// subgroups, so the refs are checked instead.
+	selector := map[string]string{
+		kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+		kube.PullLabel:        strconv.Itoa(crc.Number),
+	}
+	var pjs prowapi.ProwJobList
+	if err := p.pjclientset.List(context.Background(), &pjs, ctrlruntimeclient.MatchingLabels(selector)); err != nil {
+		return nil, fmt.Errorf("cannot list prowjobs with selector %v: %w", selector, err)
+	}
+
+	// keep track of latest prowjobs only
+	latestPjs := make(map[string]*prowapi.ProwJob)
+	for _, pj := range pjs.Items {
+		pj := pj
+		refs := pj.Spec.Refs
+		if refs == nil || refs.Org != crc.Org || refs.Repo != crc.Repo || len(refs.Pulls) == 0 || refs.Pulls[0].SHA != crc.HeadRefOID {
+			continue
+		}
+		if exist, ok := latestPjs[pj.Spec.Context]; ok && exist.CreationTimestamp.After(pj.CreationTimestamp.Time) {
+			continue
+		}
+		latestPjs[pj.Spec.Context] = &pj
+	}
+
+	var res []Context
+	for _, status := range statuses {
+		if _, ok := latestPjs[status.Name]; ok {
+			continue
+		}
+		if context, ok := gitlabStatusToContext(status); ok {
+			res = append(res, context)
+		}
+	}
+	for _, pj := range latestPjs {
+		res = append(res, Context{
+			Context:     githubql.String(pj.Spec.Context),
+			Description: githubql.String(config.ContextDescriptionWithBaseSha(pj.Status.Description, pj.Spec.Refs.BaseSHA)),
+			State:       prowJobStateToStatusState(pj.Status.State),
+		})
+	}
+	return res, nil
+}
+
+// gitlabStatusToContext converts a GitLab commit status into a Context. The
+// statuses of manual and skipped jobs are dropped, they don't block the merge.
+func gitlabStatusToContext(status gitlab.CommitStatus) (Context, bool) {
+	context := Context{
+		Context:     githubql.String(status.Name),
+		Description: githubql.String(status.Description),
+	}
+	switch status.Status {
+	case gitlab.CommitStatusSuccess:
+		context.State = githubql.StatusStateSuccess
+	case gitlab.CommitStatusFailed, gitlab.CommitStatusCanceled:
+		context.State = githubql.StatusStateFailure
+		if status.AllowFailure {
+			context.State = githubql.StatusStateSuccess
+		}
+	case gitlab.CommitStatusSkipped, gitlab.CommitStatusManual:
+		return Context{}, false
+	default:
+		context.State = githubql.StatusStatePending
+	}
+	return context, true
+}
+
+func prowJobStateToStatusState(state prowapi.ProwJobState) githubql.StatusState {
+	switch state {
+	case prowapi.SuccessState:
+		return githubql.StatusStateSuccess
+	case prowapi.FailureState, prowapi.AbortedState:
+		return githubql.StatusStateFailure
+	case prowapi.ErrorState:
+		return githubql.StatusStateError
+	default:
+		return githubql.StatusStatePending
+	}
+}
+
+func (p *GitLabProvider) mergePRs(sp subpool, prs []CodeReviewCommon, _ *threadSafePRSet) ([]CodeReviewCommon, error) {
+	var merged []CodeReviewCommon
+	var failed []int
+	var errs []error
+	log := sp.log.WithField("merge-targets", prNumbers(prs))
+	commitTemplates := p.cfg().Tide.MergeCommitTemplate(config.OrgRepo{Org: sp.org, Repo: sp.repo})
+
+	for i, pr := range prs {
+		log := log.WithFields(pr.logFields())
+		mergeMethod := p.prMergeMethod(&pr)
+		if mergeMethod == nil {
+			err := fmt.Errorf("multiple merge method labels found for %s/%s!%d", sp.org, sp.repo, pr.Number)
+			log.WithError(err).Error("Multiple merge method labels are not supported.")
+			errs = append(errs, err)
+			failed = append(failed, pr.Number)
+			continue
+		}
+
+		opts := p.prepareMergeOptions(commitTemplates, pr, *mergeMethod)
+		keepTrying, err := tryMerge(func() error {
+			_, err := p.glc.AcceptMergeRequest(pr.NameWithOwner, pr.Number, opts)
+			return toMergeError(err)
+		})
+		if err != nil {
+			// These are user errors, shouldn't be printed as tide errors
+			log.WithError(err).Debug("Merge failed.")
+		} else {
+			log.Info("Merged.")
+			merged = append(merged, pr)
+		}
+		if !keepTrying {
+			break
+		}
+		// If we successfully merged this PR and have more to merge, sleep to give
+		// GitLab time to recalculate mergeability.
+		if err == nil && i+1 < len(prs) {
+			sleep(time.Second * 5)
+		}
+	}
+
+	// In case of flaky tests, the Prow jobs of a PR might have failed even if
+	// the batch passed, so explain why the PR was merged.
+	if len(prs) > 1 {
+		for _, pr := range merged {
+			msg := fmt.Sprintf("This merge request was merged by Tide as part of the batch %s, which passed all the required tests.", gitlabBatchRefs(prs))
+			if err := p.glc.CreateMergeRequestNote(pr.NameWithOwner, pr.Number, msg); err != nil {
+				log.WithFields(pr.logFields()).WithError(err).Warn("Failed commenting after batch merge.")
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return merged, nil
+	}
+
+	// Construct a more informative error.
+	var batch string
+	if len(prs) > 1 {
+		batch = fmt.Sprintf(" from batch %v", prNumbers(prs))
+		if len(merged) > 0 {
+			batch = fmt.Sprintf("%s, partial merge %v", batch, prNumbers(merged))
+		}
+	}
+	return merged, fmt.Errorf("failed merging %v%s: %w", failed, batch, utilerrors.NewAggregate(errs))
+}
+
+func gitlabBatchRefs(prs []CodeReviewCommon) string {
+	refs := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		refs = append(refs, fmt.Sprintf("!%d", pr.Number))
+	}
+	return strings.Join(refs, ", ")
+}
+
+// prepareMergeOptions prepares the merge of a merge request. The head SHA is
+// always set, so that GitLab refuses to merge a merge request that changed
+// after being tested.
+func (p *GitLabProvider) prepareMergeOptions(commitTemplates config.TideMergeCommitTemplate, pr CodeReviewCommon, mergeMethod types.PullRequestMergeType) gitlab.AcceptMergeRequestOptions {
+	squash := mergeMethod == types.MergeSquash
+	opts := gitlab.AcceptMergeRequestOptions{
+		SHA:    pr.HeadRefOID,
+		Squash: &squash,
+	}
+
+	var message []string
+	if commitTemplates.Title != nil {
+		var b bytes.Buffer
+		if err := commitTemplates.Title.Execute(&b, pr); err != nil {
+			p.logger.Errorf("error executing commit title template: %v", err)
+		} else {
+			message = append(message, b.String())
+		}
+	}
+	if commitTemplates.Body != nil {
+		var b bytes.Buffer
+		if err := commitTemplates.Body.Execute(&b, pr); err != nil {
+			p.logger.Errorf("error executing commit body template: %v", err)
+		} else {
+			message = append(message, b.String())
+		}
+	}
+	if len(message) > 0 {
+		if squash {
+			opts.SquashCommitMessage = strings.Join(message, "\n\n")
+		} else {
+			opts.MergeCommitMessage = strings.Join(message, "\n\n")
+		}
+	}
+	return opts
+}
+
+// toMergeError converts the errors of GitLab into the errors handled by
+// tryMerge.
+func toMergeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch gitlab.StatusCode(err) {
+	case http.StatusConflict:
+		// The SHA doesn't match the head of the merge request.
+		return github.ModifiedHeadError(err.Error())
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return github.UnauthorizedToPushError(err.Error())
+	case http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusUnprocessableEntity:
+		// The merge request can't be merged, because of conflicts, unmet merge
+		// checks or because it's not rebased for fast-forward merges.
+		return github.UnmergablePRError(err.Error())
+	}
+	return err
+}
+
+// GetTideContextPolicy gets context policy defined by users + requirements from
+// prow jobs.
+func (p *GitLabProvider) GetTideContextPolicy(org, repo, branch string, baseSHAGetter config.RefGetter, crc *CodeReviewCommon) (contextChecker, error) {
+	return p.cfg().GetTideContextPolicy(p.gc, org, repo, branch, baseSHAGetter, crc.HeadRefOID)
+}
+
+func (p *GitLabProvider) prMergeMethod(crc *CodeReviewCommon) *types.PullRequestMergeType {
+	return prMergeMethodFromLabels(p.cfg().Tide, crc)
+}
+
+func (p *GitLabProvider) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	return p.cfg().GetPresubmits(p.gc, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
+func (p *GitLabProvider) GetChangedFiles(org, repo string, number int) ([]string, error) {
+	diffs, err := p.glc.ListMergeRequestDiffs(org+"/"+repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed get merge request diffs: %v", err)
+	}
+	files := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		files = append(files, d.NewPath)
+	}
+	return files, nil
+}
+
+func (p *GitLabProvider) refsForJob(sp subpool, prs []CodeReviewCommon) (prowapi.Refs, error) {
+	project, err := p.project(sp.org + "/" + sp.repo)
+	if err != nil {
+		return prowapi.Refs{}, fmt.Errorf("error getting project data: %w", err)
+	}
+	refs := prowapi.Refs{
+		Org:      sp.org,
+		Repo:     sp.repo,
+		RepoLink: project.WebURL,
+		BaseRef:  sp.branch,
+		BaseSHA:  sp.sha,
+		BaseLink: fmt.Sprintf("%s/-/commit/%s", project.WebURL, sp.sha),
+		CloneURI: project.HTTPURLToRepo,
+	}
+	for _, pr := range prs {
+		refs.Pulls = append(
+			refs.Pulls,
+			prowapi.Pull{
+				Number:     pr.Number,
+				Title:      pr.Title,
+				Author:     pr.AuthorLogin,
+				SHA:        pr.HeadRefOID,
+				HeadRef:    pr.HeadRefName,
+				Ref:        fmt.Sprintf("refs/merge-requests/%d/head", pr.Number),
+				Link:       pr.GitLab.WebURL,
+				CommitLink: fmt.Sprintf("%s/-/commit/%s", project.WebURL, pr.HeadRefOID),
+			},
+		)
+	}
+	return refs, nil
+}
+
+func (p *GitLabProvider) labelsAndAnnotations(instance string, jobLabels, jobAnnotations map[string]string, changes ...CodeReviewCommon) (labels, annotations map[string]string) {
+	labels, annotations = jobLabels, jobAnnotations
+	return
+}
+
+func (p *GitLabProvider) jobIsRequiredByTide(ps *config.Presubmit, crc *CodeReviewCommon) bool {
+	return ps.ContextRequired() || ps.RunBeforeMerge
+}
@@ -0,0 +1,420 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/git/types"
+	"k8s.io/test-infra/prow/gitlab"
+	"k8s.io/test-infra/prow/gitlab/fakegitlab"
+	"k8s.io/test-infra/prow/kube"
+)
+
+const testGitLabProject = "group/sub/project"
+
+func newTestGitLabServer() *fakegitlab.Server {
+	s := fakegitlab.NewServer()
+	s.Projects[testGitLabProject] = &gitlab.Project{
+		PathWithNamespace: testGitLabProject,
+		WebURL:            "https://gitlab.example.com/" + testGitLabProject,
+		HTTPURLToRepo:     "https://gitlab.example.com/" + testGitLabProject + ".git",
+		MergeMethod:       gitlab.MergeMethodMerge,
+		SquashOption:      gitlab.SquashOptionDefaultOff,
+	}
+	s.Projects["group/other"] = &gitlab.Project{PathWithNamespace: "group/other"}
+	s.Branches[testGitLabProject] = map[string]string{"main": "base-sha"}
+	return s
+}
+
+func newTestGitLabProvider(s *fakegitlab.Server, tide config.Tide, pjs ...runtime.Object) *GitLabProvider {
+	cfg := func() *config.Config {
+		return &config.Config{ProwConfig: config.ProwConfig{Tide: tide}}
+	}
+	glc := gitlab.NewClient(s.URL(), func() []byte { return []byte(fakegitlab.Token) })
+	return newGitLabProvider(logrus.WithField("test", "gitlab"), cfg, glc, nil, fakectrlruntimeclient.NewFakeClient(pjs...))
+}
+
+func TestGitLabQuery(t *testing.T) {
+	s := newTestGitLabServer()
+	defer s.Close()
+	mr := func(iid int, branch string, labels ...string) gitlab.MergeRequest {
+		return gitlab.MergeRequest{IID: iid, State: gitlab.MergeRequestStateOpened, TargetBranch: branch, SHA: "sha", Labels: labels, MergeStatus: gitlab.MergeStatusCanBeMerged}
+	}
+	s.MergeRequests[testGitLabProject] = []gitlab.MergeRequest{
+		mr(1, "main", "lgtm", "approved"),
+		mr(2, "main", "lgtm", "approved-by-owner"),
+		mr(3, "main", "lgtm"),
+		mr(4, "main", "lgtm", "approved", "do-not-merge"),
+		mr(5, "dev", "lgtm", "approved"),
+		{IID: 6, State: gitlab.MergeRequestStateOpened, TargetBranch: "main", Labels: []string{"lgtm", "approved"}, Draft: true},
+		{IID: 7, State: gitlab.MergeRequestStateMerged, TargetBranch: "main", Labels: []string{"lgtm", "approved"}},
+	}
+	s.MergeRequests["group/other"] = []gitlab.MergeRequest{mr(1, "main", "lgtm", "approved")}
+
+	p := newTestGitLabProvider(s, config.Tide{GitLab: &config.TideGitLabConfig{
+		Queries: []config.GitLabTideQuery{
+			{
+				Projects:         []string{testGitLabProject, "group/other"},
+				Labels:           []string{"lgtm", "approved,approved-by-owner"},
+				MissingLabels:    []string{"do-not-merge"},
+				ExcludedBranches: []string{"dev"},
+			},
+			{
+				Projects: []string{"group/missing"},
+			},
+		},
+	}})
+
+	got, err := p.Query()
+	if err == nil {
+		t.Error("Expected an error for the missing project.")
+	}
+	var gotKeys []string
+	for key := range got {
+		gotKeys = append(gotKeys, key)
+	}
+	sort.Strings(gotKeys)
+	wantKeys := []string{"group/other#1", "group/sub/project#1", "group/sub/project#2"}
+	if diff := cmp.Diff(wantKeys, gotKeys); diff != "" {
+		t.Errorf("Query() mismatch (-want +got):\n%s", diff)
+	}
+
+	crc := got["group/sub/project#1"]
+	if crc.Org != "group" || crc.Repo != "sub/project" || crc.BaseRefName != "main" || crc.Mergeable != string(githubql.MergeableStateMergeable) {
+		t.Errorf("Unexpected merge request: %+v", crc)
+	}
+}
+
+func TestGitLabGetRef(t *testing.T) {
+	s := newTestGitLabServer()
+	defer s.Close()
+	p := newTestGitLabProvider(s, config.Tide{})
+
+	got, err := p.GetRef("group", "sub/project", "heads/main")
+	if err != nil {
+		t.Fatalf("GetRef() error = %v", err)
+	}
+	if got != "base-sha" {
+		t.Errorf("GetRef() = %q, want %q", got, "base-sha")
+	}
+}
+
+func TestGitLabIsAllowedToMerge(t *testing.T) {
+	tests := []struct {
+		name         string
+		mergeMethod  string
+		squashOption string
+		mergeType    types.PullRequestMergeType
+		// mergeTypeKey defaults to the path of the project.
+		mergeTypeKey string
+		labels       []string
+		hasConflicts bool
+		want         string
+	}{
+		{
+			name:        "merge",
+			mergeMethod: gitlab.MergeMethodMerge,
+			mergeType:   types.MergeMerge,
+		},
+		{
+			name:         "conflict",
+			mergeMethod:  gitlab.MergeMethodMerge,
+			mergeType:    types.MergeMerge,
+			hasConflicts: true,
+			want:         "PR has a merge conflict.",
+		},
+		{
+			name:        "rebase is not allowed for merge commits",
+			mergeMethod: gitlab.MergeMethodMerge,
+			mergeType:   types.MergeRebase,
+			want:        `Merge type "rebase" disallowed by project settings`,
+		},
+		{
+			name:        "rebase for fast-forward merges",
+			mergeMethod: gitlab.MergeMethodFastForward,
+			mergeType:   types.MergeRebase,
+		},
+		{
+			name:         "squash label",
+			mergeMethod:  gitlab.MergeMethodMerge,
+			squashOption: gitlab.SquashOptionNever,
+			mergeType:    types.MergeMerge,
+			labels:       []string{"tide/merge-method-squash"},
+			want:         `Merge type "squash" disallowed by project settings`,
+		},
+		{
+			name:         "squash is required",
+			mergeMethod:  gitlab.MergeMethodMerge,
+			squashOption: gitlab.SquashOptionAlways,
+			mergeType:    types.MergeMerge,
+			want:         `Merge type "merge" disallowed by project settings`,
+		},
+		{
+			name:        "conflicting labels",
+			mergeMethod: gitlab.MergeMethodMerge,
+			mergeType:   types.MergeMerge,
+			labels:      []string{"tide/merge-method-squash", "tide/merge-method-rebase"},
+			want:        "PR has conflicting merge method override labels",
+		},
+		{
+			name:         "merge method of the top-level group",
+			mergeMethod:  gitlab.MergeMethodMerge,
+			mergeType:    types.MergeRebase,
+			mergeTypeKey: "group",
+			want:         `Merge type "rebase" disallowed by project settings`,
+		},
+		{
+			name:         "merge method of a subgroup doesn't apply",
+			mergeMethod:  gitlab.MergeMethodMerge,
+			mergeType:    types.MergeRebase,
+			mergeTypeKey: "group/sub",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestGitLabServer()
+			defer s.Close()
+			s.Projects[testGitLabProject].MergeMethod = tc.mergeMethod
+			s.Projects[testGitLabProject].SquashOption = tc.squashOption
+			mergeTypeKey := tc.mergeTypeKey
+			if mergeTypeKey == "" {
+				mergeTypeKey = testGitLabProject
+			}
+			p := newTestGitLabProvider(s, config.Tide{
+				TideGitHubConfig: config.TideGitHubConfig{
+					MergeType:   map[string]config.TideOrgMergeType{mergeTypeKey: {MergeType: tc.mergeType}},
+					SquashLabel: "tide/merge-method-squash",
+					RebaseLabel: "tide/merge-method-rebase",
+				},
+			})
+
+			crc := CodeReviewCommonFromGitLab(&gitlab.MergeRequest{IID: 1, TargetBranch: "main", Labels: tc.labels, HasConflicts: tc.hasConflicts}, testGitLabProject)
+			got, err := p.isAllowedToMerge(crc)
+			if err != nil {
+				t.Fatalf("isAllowedToMerge() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("isAllowedToMerge() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGitLabHeadContexts(t *testing.T) {
+	s := newTestGitLabServer()
+	defer s.Close()
+	s.Statuses["head"] = []gitlab.CommitStatus{
+		{Name: "build", Status: gitlab.CommitStatusSuccess},
+		{Name: "lint", Status: gitlab.CommitStatusFailed, AllowFailure: true},
+		{Name: "deploy", Status: gitlab.CommitStatusManual},
+		{Name: "test", Status: gitlab.CommitStatusRunning},
+		{Name: "unit", Status: gitlab.CommitStatusFailed, Description: "reported late"},
+	}
+
+	now := time.Now()
+	pj := func(name, context, repo, sha string, state prowapi.ProwJobState, created time.Time) runtime.Object {
+		return &prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+					kube.PullLabel:        "1",
+				},
+			},
+			Spec: prowapi.ProwJobSpec{
+				Type:    prowapi.PresubmitJob,
+				Context: context,
+				Refs: &prowapi.Refs{
+					Org:     "group",
+					Repo:    repo,
+					BaseSHA: "base",
+					Pulls:   []prowapi.Pull{{Number: 1, SHA: sha}},
+				},
+			},
+			Status: prowapi.ProwJobStatus{State: state, Description: string(state)},
+		}
+	}
+	p := newTestGitLabProvider(s, config.Tide{},
+		pj("old", "unit", "sub/project", "head", prowapi.FailureState, now.Add(-time.Hour)),
+		pj("new", "unit", "sub/project", "head", prowapi.SuccessState, now),
+		pj("old-head", "e2e", "sub/project", "old-head", prowapi.SuccessState, now),
+		pj("other-subgroup", "e2e", "other/project", "head", prowapi.SuccessState, now),
+	)
+
+	got, err := p.headContexts(&CodeReviewCommon{NameWithOwner: testGitLabProject, Org: "group", Repo: "sub/project", Number: 1, HeadRefOID: "head"})
+	if err != nil {
+		t.Fatalf("headContexts() error = %v", err)
+	}
+	want := []Context{
+		{Context: "build", State: githubql.StatusStateSuccess},
+		{Context: "lint", State: githubql.StatusStateSuccess},
+		{Context: "test", State: githubql.StatusStatePending},
+		{Context: "unit", State: githubql.StatusStateSuccess, Description: githubql.String(config.ContextDescriptionWithBaseSha("success", "base"))},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("headContexts() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitLabMergePRs(t *testing.T) {
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	tests := []struct {
+		name        string
+		mergeErrors map[int]int
+		labels      map[int][]string
+		wantMerged  []int
+		wantSquash  []bool
+		wantNotes   []int
+		wantErr     bool
+	}{
+		{
+			name:       "batch",
+			labels:     map[int][]string{2: {"tide/merge-method-squash"}},
+			wantMerged: []int{1, 2},
+			wantSquash: []bool{false, true},
+			wantNotes:  []int{1, 2},
+		},
+		{
+			name:        "modified merge request",
+			mergeErrors: map[int]int{1: http.StatusConflict},
+			wantMerged:  []int{2},
+			wantSquash:  []bool{false},
+			wantNotes:   []int{2},
+		},
+		{
+			name:        "unauthorized stops the merges",
+			mergeErrors: map[int]int{1: http.StatusForbidden},
+		},
+		{
+			name:       "conflicting merge method labels",
+			labels:     map[int][]string{1: {"tide/merge-method-squash", "tide/merge-method-merge"}},
+			wantMerged: []int{2},
+			wantSquash: []bool{false},
+			wantNotes:  []int{2},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestGitLabServer()
+			defer s.Close()
+			s.MergeErrors[testGitLabProject] = tc.mergeErrors
+			var prs []CodeReviewCommon
+			for _, iid := range []int{1, 2} {
+				mr := gitlab.MergeRequest{IID: iid, State: gitlab.MergeRequestStateOpened, TargetBranch: "main", SHA: "sha", Labels: tc.labels[iid]}
+				s.MergeRequests[testGitLabProject] = append(s.MergeRequests[testGitLabProject], mr)
+				prs = append(prs, *CodeReviewCommonFromGitLab(&mr, testGitLabProject))
+			}
+			p := newTestGitLabProvider(s, config.Tide{
+				TideGitHubConfig: config.TideGitHubConfig{
+					SquashLabel: "tide/merge-method-squash",
+					MergeLabel:  "tide/merge-method-merge",
+				},
+			})
+
+			merged, err := p.mergePRs(subpool{org: "group", repo: "sub/project", log: logrus.WithField("test", tc.name)}, prs, nil)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("mergePRs() error = %v", err)
+			}
+
+			var gotMerged []int
+			for _, pr := range merged {
+				gotMerged = append(gotMerged, pr.Number)
+			}
+			var gotSquash []bool
+			for _, m := range s.Merges {
+				if m.Options.SHA != "sha" {
+					t.Errorf("Merge %d used SHA %q, want %q", m.IID, m.Options.SHA, "sha")
+				}
+				gotSquash = append(gotSquash, *m.Options.Squash)
+			}
+			var gotNotes []int
+			for _, n := range s.Notes {
+				gotNotes = append(gotNotes, n.IID)
+			}
+			if diff := cmp.Diff(tc.wantMerged, gotMerged); diff != "" {
+				t.Errorf("merged mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantSquash, gotSquash); diff != "" {
+				t.Errorf("squash mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNotes, gotNotes); diff != "" {
+				t.Errorf("notes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGitLabRefsForJob(t *testing.T) {
+	s := newTestGitLabServer()
+	defer s.Close()
+	p := newTestGitLabProvider(s, config.Tide{})
+
+	pr := CodeReviewCommonFromGitLab(&gitlab.MergeRequest{
+		IID:          1,
+		Title:        "Fix",
+		Author:       gitlab.User{Username: "user"},
+		SHA:          "head",
+		SourceBranch: "fix",
+		WebURL:       "https://gitlab.example.com/group/sub/project/-/merge_requests/1",
+	}, testGitLabProject)
+	got, err := p.refsForJob(subpool{org: "group", repo: "sub/project", branch: "main", sha: "base"}, []CodeReviewCommon{*pr})
+	if err != nil {
+		t.Fatalf("refsForJob() error = %v", err)
+	}
+	want := prowapi.Refs{
+		Org:      "group",
+		Repo:     "sub/project",
+		RepoLink: "https://gitlab.example.com/group/sub/project",
+		BaseRef:  "main",
+		BaseSHA:  "base",
+		BaseLink: "https://gitlab.example.com/group/sub/project/-/commit/base",
+		CloneURI: "https://gitlab.example.com/group/sub/project.git",
+		Pulls: []prowapi.Pull{{
+			Number:     1,
+			Title:      "Fix",
+			Author:     "user",
+			SHA:        "head",
+			HeadRef:    "fix",
+			Ref:        "refs/merge-requests/1/head",
+			Link:       "https://gitlab.example.com/group/sub/project/-/merge_requests/1",
+			CommitLink: "https://gitlab.example.com/group/sub/project/-/commit/head",
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("refsForJob() mismatch (-want +got):\n%s", diff)
+	}
+}
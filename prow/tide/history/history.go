@@ -21,13 +21,17 @@ package history
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	stdio "io"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -37,6 +41,28 @@ import (
 // Mock out time for unit testing.
 var now = time.Now
 
+const (
+	// maxPendingRecords bounds the records kept in memory while they can't be
+	// appended to the store. The oldest ones are dropped beyond that.
+	maxPendingRecords = 10000
+
+	// defaultQueryWindow is how far back the served queries without a start
+	// time go.
+	defaultQueryWindow = 7 * 24 * time.Hour
+	// maxQueryRange bounds the time range of the served queries, which read
+	// every segment of the days they span.
+	maxQueryRange = 31 * 24 * time.Hour
+)
+
+var droppedRecords = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "tidehistorydroppedrecords",
+	Help: "Count of Tide action history records dropped because they couldn't be appended to the store.",
+})
+
+func init() {
+	prometheus.MustRegister(droppedRecords)
+}
+
 // History uses a `*recordLog` per pool to store a record of recent actions that
 // Tide has taken. Using a log per pool ensure that history is retained
 // for inactive pools even if other pools are very active.
@@ -47,6 +73,15 @@ type History struct {
 
 	opener opener
 	path   string
+
+	// store persists the records evicted from the logs, if configured.
+	store Store
+	// failed are the records of the last failed append to the store. They are
+	// retried as a batch of their own so that the segments that were already
+	// written are rewritten identically.
+	failed []StoredRecord
+	// pending are the other records that are not in the store yet.
+	pending []StoredRecord
 }
 
 // opener has methods to read and write paths
@@ -172,6 +207,36 @@ func (h *History) addRecord(poolKey string, rec *Record) {
 		h.logs[poolKey] = newRecordLog(h.logSizeLimit)
 	}
 	h.logs[poolKey].add(rec)
+	if h.store != nil {
+		h.pending = append(h.pending, StoredRecord{Pool: poolKey, Record: rec})
+		h.limitPending()
+	}
+}
+
+// limitPending drops the oldest records that are not in the store yet beyond
+// maxPendingRecords. The caller must hold the lock.
+func (h *History) limitPending() {
+	excess := len(h.failed) + len(h.pending) - maxPendingRecords
+	if excess <= 0 {
+		return
+	}
+	fromFailed := excess
+	if fromFailed > len(h.failed) {
+		fromFailed = len(h.failed)
+	}
+	h.failed = h.failed[fromFailed:]
+	h.pending = h.pending[excess-fromFailed:]
+	droppedRecords.Add(float64(excess))
+	logrus.WithField("records", excess).Warn("Dropping action history records that couldn't be appended to the store.")
+}
+
+// SetStore configures a store persisting all the records, so that they can
+// still be queried once evicted from the size limited logs. Records are
+// appended to the store when the history is flushed.
+func (h *History) SetStore(store Store) {
+	h.Lock()
+	defer h.Unlock()
+	h.store = store
 }
 
 // ServeHTTP serves a JSON mapping from pool key -> sorted records for the pool.
@@ -186,8 +251,115 @@ func (h *History) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Query returns the records matching the query, newest first. The records
+// are queried from the store if one is configured, and from the logs
+// otherwise.
+func (h *History) Query(ctx context.Context, query Query) ([]StoredRecord, error) {
+	h.Lock()
+	store := h.store
+	var res []StoredRecord
+	if store == nil {
+		for key, log := range h.logs {
+			for _, rec := range log.toSlice() {
+				if rec := (StoredRecord{Pool: key, Record: rec}); query.matches(rec) {
+					res = append(res, rec)
+				}
+			}
+		}
+	} else {
+		// The failed and pending records are not in the store yet.
+		for _, records := range [][]StoredRecord{h.failed, h.pending} {
+			for _, rec := range records {
+				if query.matches(rec) {
+					res = append(res, rec)
+				}
+			}
+		}
+	}
+	h.Unlock()
+
+	if store != nil {
+		stored, err := store.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, stored...)
+	}
+	return newestFirst(res, query.Limit), nil
+}
+
+// ServeQuery serves a JSON list of the records matching the optional pool,
+// repo, pr, action, from, to and limit query parameters, newest first. The
+// from and to times are in RFC 3339 format. Queries span the last week by
+// default and at most a month, and PRs are looked up within a repo or pool.
+func (h *History) ServeQuery(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	records, err := h.Query(r.Context(), query)
+	if err != nil {
+		logrus.WithError(err).Error("Querying action history.")
+		http.Error(w, "failed to query the action history", http.StatusInternalServerError)
+		return
+	}
+	if records == nil {
+		records = []StoredRecord{}
+	}
+	b, err := json.Marshal(records)
+	if err != nil {
+		logrus.WithError(err).Error("Encoding JSON history.")
+		b = []byte("[]")
+	}
+	if _, err = w.Write(b); err != nil {
+		logrus.WithError(err).Debug("Writing JSON history response.")
+	}
+}
+
+func parseQuery(values url.Values) (Query, error) {
+	query := Query{
+		Pool:   values.Get("pool"),
+		Repo:   values.Get("repo"),
+		Action: values.Get("action"),
+	}
+	for param, field := range map[string]*int{"pr": &query.PR, "limit": &query.Limit} {
+		if v := values.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid %s %q: %w", param, v, err)
+			}
+			*field = n
+		}
+	}
+	for param, field := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if v := values.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid %s %q: %w", param, v, err)
+			}
+			*field = t
+		}
+	}
+	if query.PR != 0 && query.repo() == "" {
+		return Query{}, errors.New("pr requires a repo or pool")
+	}
+	end := query.To
+	if end.IsZero() {
+		end = now()
+	}
+	if query.From.IsZero() {
+		query.From = end.Add(-defaultQueryWindow)
+	}
+	if end.Sub(query.From) > maxQueryRange {
+		return Query{}, fmt.Errorf("the time range can't exceed %v", maxQueryRange)
+	}
+	return query, nil
+}
+
 // Flush writes the action history to persistent storage if configured to do so.
 func (h *History) Flush() {
+	h.flushStore()
 	if h.path == "" {
 		return
 	}
@@ -205,6 +377,49 @@ func (h *History) Flush() {
 	}
 }
 
+// flushStore appends the failed and pending records to the store. They are
+// kept to be retried on the next flush if that fails.
+func (h *History) flushStore() {
+	h.Lock()
+	store, failed, pending := h.store, h.failed, h.pending
+	h.failed, h.pending = nil, nil
+	h.Unlock()
+	if store == nil {
+		return
+	}
+
+	if err := appendToStore(store, failed); err != nil {
+		h.Lock()
+		h.failed = failed
+		h.pending = append(pending, h.pending...)
+		h.limitPending()
+		h.Unlock()
+		return
+	}
+	if err := appendToStore(store, pending); err != nil {
+		h.Lock()
+		h.failed = pending
+		h.limitPending()
+		h.Unlock()
+	}
+}
+
+func appendToStore(store Store, records []StoredRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	start := time.Now()
+	log := logrus.WithField("records", len(records))
+	if err := store.Append(ctx, records); err != nil {
+		log.WithError(err).Error("Error appending action history to the store.")
+		return err
+	}
+	log.WithField("duration", time.Since(start).String()).Debug("Successfully appended action history to the store.")
+	return nil
+}
+
 // AllRecords generates a map from pool key -> sorted records for the pool.
 func (h *History) AllRecords() map[string][]*Record {
 	h.Lock()
@@ -217,6 +432,17 @@ func (h *History) AllRecords() map[string][]*Record {
 	return res
 }
 
+// PoolRecords returns the sorted records of a pool, newest first.
+func (h *History) PoolRecords(poolKey string) []*Record {
+	h.Lock()
+	defer h.Unlock()
+
+	if log, ok := h.logs[poolKey]; ok {
+		return log.toSlice()
+	}
+	return nil
+}
+
 // recordLog is a space efficient, limited size, append only list.
 type recordLog struct {
 	buff  []*Record
@@ -142,6 +142,12 @@ func TestHistory(t *testing.T) {
 		t.Errorf("Expected history \n%s, but got \n%s.", es, gs)
 		t.Logf("strs equal: %v.", string(es) == string(gs))
 	}
+	if got := hist.PoolRecords("pool B"); !reflect.DeepEqual(got, expected["pool B"]) {
+		t.Errorf("Expected records of pool B %v, but got %v.", expected["pool B"], got)
+	}
+	if got := hist.PoolRecords("pool E"); got != nil {
+		t.Errorf("Expected no records for an unknown pool, but got %v.", got)
+	}
 }
 
 const fakePath = "/some/random/path"
@@ -0,0 +1,312 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdio "io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/prow/io"
+	"k8s.io/test-infra/prow/io/providers"
+)
+
+// segmentDayLayout is the layout of the daily partitions of the segment store.
+const segmentDayLayout = "2006-01-02"
+
+// StoredRecord is a record of a pool, as kept by a Store.
+type StoredRecord struct {
+	Pool string `json:"pool"`
+	*Record
+}
+
+// Query selects stored records. Zero fields match any record.
+type Query struct {
+	// Pool is the key of the pool, e.g. "org/repo:branch".
+	Pool string
+	// Repo is the "org/repo" of the pools.
+	Repo string
+	// PR is the number of a PR targeted by the records.
+	PR     int
+	Action string
+	// From and To bound the time of the records, inclusively.
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of records returned, newest first.
+	Limit int
+}
+
+// repo returns the "org/repo" the query is restricted to, if any.
+func (q *Query) repo() string {
+	if q.Repo != "" {
+		return q.Repo
+	}
+	return poolRepo(q.Pool)
+}
+
+// poolRepo returns the "org/repo" of a pool key.
+func poolRepo(pool string) string {
+	repo, _, _ := strings.Cut(pool, ":")
+	return repo
+}
+
+func (q *Query) matches(rec StoredRecord) bool {
+	if q.Pool != "" && rec.Pool != q.Pool {
+		return false
+	}
+	if q.Repo != "" && !strings.HasPrefix(rec.Pool, q.Repo+":") {
+		return false
+	}
+	if q.Action != "" && rec.Action != q.Action {
+		return false
+	}
+	if !q.From.IsZero() && rec.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && rec.Time.After(q.To) {
+		return false
+	}
+	if q.PR != 0 {
+		for _, pull := range rec.Target {
+			if pull.Number == q.PR {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Store persists all the records of the pools, unlike the size limited record
+// logs of the History.
+type Store interface {
+	// Append stores new records.
+	Append(ctx context.Context, records []StoredRecord) error
+	// Query returns the stored records matching the query, newest first.
+	Query(ctx context.Context, query Query) ([]StoredRecord, error)
+}
+
+// storeOpener has methods to list, read and write paths.
+type storeOpener interface {
+	opener
+	Iterator(ctx context.Context, prefix, delimiter string) (io.ObjectIterator, error)
+}
+
+// segmentStore is an append only Store writing the records as JSON lines
+// segments, partitioned by day and repo: each append writes
+// <dir>/<YYYY-MM-DD>/<escaped org/repo>/<content hash>.jsonl objects, and
+// queries only read the days and repos they span. Segments are named after
+// their content, so retrying an append rewrites the segments that were
+// already written identically instead of duplicating their records.
+type segmentStore struct {
+	opener storeOpener
+	dir    string
+}
+
+// NewSegmentStore creates a Store keeping the records in JSON lines segments
+// under the gs://bucket/dir or s3://bucket/dir directory.
+func NewSegmentStore(opener io.Opener, dir string) (Store, error) {
+	if _, _, _, err := providers.ParseStoragePath(dir); err != nil {
+		return nil, err
+	}
+	return &segmentStore{opener: opener, dir: strings.TrimSuffix(dir, "/")}, nil
+}
+
+func (s *segmentStore) Append(ctx context.Context, records []StoredRecord) error {
+	var partitions []string
+	byPartition := map[string][]StoredRecord{}
+	for _, rec := range records {
+		partition := path.Join(rec.Time.UTC().Format(segmentDayLayout), url.PathEscape(poolRepo(rec.Pool)))
+		if _, ok := byPartition[partition]; !ok {
+			partitions = append(partitions, partition)
+		}
+		byPartition[partition] = append(byPartition[partition], rec)
+	}
+	for _, partition := range partitions {
+		if err := s.writeSegment(ctx, partition, byPartition[partition]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *segmentStore) writeSegment(ctx context.Context, partition string, records []StoredRecord) error {
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	for _, rec := range records {
+		if err := encoder.Encode(rec); err != nil {
+			return fmt.Errorf("marshal records of %s: %w", partition, err)
+		}
+	}
+	segment := fmt.Sprintf("%s/%s/%x.jsonl", s.dir, partition, sha256.Sum256(content.Bytes()))
+	writer, err := s.opener.Writer(ctx, segment)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", segment, err)
+	}
+	if _, err := writer.Write(content.Bytes()); err != nil {
+		io.LogClose(writer)
+		return fmt.Errorf("write %s: %w", segment, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", segment, err)
+	}
+	return nil
+}
+
+func (s *segmentStore) Query(ctx context.Context, query Query) ([]StoredRecord, error) {
+	days, err := s.days(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var res []StoredRecord
+	for _, day := range days {
+		prefix := day
+		if repo := query.repo(); repo != "" {
+			prefix += url.PathEscape(repo) + "/"
+		}
+		segments, err := s.list(ctx, prefix, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, segment := range segments {
+			records, err := s.readSegment(ctx, segment)
+			if err != nil {
+				return nil, err
+			}
+			for _, rec := range records {
+				if query.matches(rec) {
+					res = append(res, rec)
+				}
+			}
+		}
+	}
+	return newestFirst(res, query.Limit), nil
+}
+
+// days returns the directories of the days spanned by the query. They are
+// listed only if the query has no start time.
+func (s *segmentStore) days(ctx context.Context, query Query) ([]string, error) {
+	if query.From.IsZero() {
+		days, err := s.list(ctx, s.dir+"/", "/")
+		if err != nil {
+			return nil, err
+		}
+		var res []string
+		for _, day := range days {
+			if query.spansDay(path.Base(day)) {
+				res = append(res, day)
+			}
+		}
+		return res, nil
+	}
+	to := query.To
+	if to.IsZero() {
+		to = now()
+	}
+	var res []string
+	for day := query.From.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.Add(24 * time.Hour) {
+		res = append(res, s.dir+"/"+day.Format(segmentDayLayout)+"/")
+	}
+	return res, nil
+}
+
+// spansDay returns whether the time range of the query overlaps the day of a
+// partition.
+func (q *Query) spansDay(day string) bool {
+	start, err := time.Parse(segmentDayLayout, day)
+	if err != nil {
+		// Not a partition of the store.
+		return false
+	}
+	end := start.Add(24 * time.Hour)
+	return (q.From.IsZero() || q.From.Before(end)) && (q.To.IsZero() || !q.To.Before(start))
+}
+
+// list returns the full paths of the objects or directories under the prefix.
+func (s *segmentStore) list(ctx context.Context, prefix, delimiter string) ([]string, error) {
+	provider, bucket, _, err := providers.ParseStoragePath(prefix)
+	if err != nil {
+		return nil, err
+	}
+	it, err := s.opener.Iterator(ctx, prefix, delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+	var res []string
+	for {
+		attr, err := it.Next(ctx)
+		if errors.Is(err, stdio.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		if delimiter != "" && !attr.IsDir {
+			continue
+		}
+		name := attr.Name
+		if attr.IsDir && !strings.HasSuffix(name, "/") {
+			name += "/"
+		}
+		res = append(res, fmt.Sprintf("%s://%s/%s", provider, bucket, name))
+	}
+	return res, nil
+}
+
+func (s *segmentStore) readSegment(ctx context.Context, segment string) ([]StoredRecord, error) {
+	reader, err := s.opener.Reader(ctx, segment)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", segment, err)
+	}
+	defer io.LogClose(reader)
+	var res []StoredRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 10*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec StoredRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", segment, err)
+		}
+		res = append(res, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", segment, err)
+	}
+	return res, nil
+}
+
+// newestFirst sorts the records by descending time and applies the limit.
+func newestFirst(records []StoredRecord, limit int) []StoredRecord {
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.After(records[j].Time) })
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
@@ -0,0 +1,425 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	pkgio "k8s.io/test-infra/prow/io"
+)
+
+const fakeBucket = "gs://bucket/"
+
+// fakeStoreOpener keeps the objects of a bucket in memory.
+type fakeStoreOpener struct {
+	objects map[string]string
+}
+
+func (f *fakeStoreOpener) Reader(_ context.Context, path string) (io.ReadCloser, error) {
+	content, ok := f.objects[path]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+type fakeObjectWriter struct {
+	bytes.Buffer
+	commit func(string)
+}
+
+func (w *fakeObjectWriter) Close() error {
+	w.commit(w.String())
+	return nil
+}
+
+func (f *fakeStoreOpener) Writer(_ context.Context, path string, opts ...pkgio.WriterOptions) (io.WriteCloser, error) {
+	for _, opt := range opts {
+		if _, exists := f.objects[path]; exists && opt.PreconditionDoesNotExist != nil && *opt.PreconditionDoesNotExist {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+	}
+	return &fakeObjectWriter{commit: func(content string) { f.objects[path] = content }}, nil
+}
+
+type fakeObjectIterator []pkgio.ObjectAttributes
+
+func (it *fakeObjectIterator) Next(_ context.Context) (pkgio.ObjectAttributes, error) {
+	if len(*it) == 0 {
+		return pkgio.ObjectAttributes{}, io.EOF
+	}
+	attr := (*it)[0]
+	*it = (*it)[1:]
+	return attr, nil
+}
+
+func (f *fakeStoreOpener) Iterator(_ context.Context, prefix, delimiter string) (pkgio.ObjectIterator, error) {
+	relPrefix := strings.TrimPrefix(prefix, fakeBucket)
+	var names []string
+	for path := range f.objects {
+		names = append(names, strings.TrimPrefix(path, fakeBucket))
+	}
+	sort.Strings(names)
+
+	var res fakeObjectIterator
+	dirs := map[string]bool{}
+	for _, name := range names {
+		if !strings.HasPrefix(name, relPrefix) {
+			continue
+		}
+		if i := strings.Index(name[len(relPrefix):], delimiter); delimiter != "" && i >= 0 {
+			dir := name[:len(relPrefix)+i+1]
+			if !dirs[dir] {
+				dirs[dir] = true
+				res = append(res, pkgio.ObjectAttributes{Name: dir, IsDir: true})
+			}
+			continue
+		}
+		res = append(res, pkgio.ObjectAttributes{Name: name, ObjName: name[strings.LastIndex(name, "/")+1:]})
+	}
+	return &res, nil
+}
+
+func TestSegmentStore(t *testing.T) {
+	day1 := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	oldNow := now
+	defer func() { now = oldNow }()
+
+	record := func(pool, action string, t time.Time, prs ...int) StoredRecord {
+		rec := StoredRecord{Pool: pool, Record: &Record{Time: t, Action: action}}
+		for _, pr := range prs {
+			rec.Target = append(rec.Target, prowapi.Pull{Number: pr})
+		}
+		return rec
+	}
+	a := record("org/repo:main", "TRIGGER", day1, 1)
+	b := record("org/repo:main", "MERGE", day1.Add(time.Hour), 1)
+	c := record("org/other:main", "MERGE_BATCH", day1.Add(2*time.Hour), 1, 2)
+	d := record("org/repo:release", "MERGE", day2, 3)
+	e := record("org/repository:main", "TRIGGER", day2.Add(time.Hour), 4)
+
+	opener := &fakeStoreOpener{objects: map[string]string{}}
+	store := &segmentStore{opener: opener, dir: fakeBucket + "tide/history"}
+	now = func() time.Time { return day2 }
+	for _, records := range [][]StoredRecord{{a, b}, {c, d, e}, {c, d, e}} {
+		if err := store.Append(context.Background(), records); err != nil {
+			t.Fatalf("Failed to append records: %v", err)
+		}
+	}
+
+	// Appending the same records again rewrites the same segments.
+	var partitions []string
+	for segment := range opener.objects {
+		partitions = append(partitions, path.Dir(segment))
+	}
+	sort.Strings(partitions)
+	expectedPartitions := []string{
+		"gs://bucket/tide/history/2023-06-01/org%2Fother",
+		"gs://bucket/tide/history/2023-06-01/org%2Frepo",
+		"gs://bucket/tide/history/2023-06-02/org%2Frepo",
+		"gs://bucket/tide/history/2023-06-02/org%2Frepository",
+	}
+	if diff := cmp.Diff(expectedPartitions, partitions); diff != "" {
+		t.Errorf("Segment partitions mismatch (-want +got):\n%s", diff)
+	}
+
+	tcs := []struct {
+		name     string
+		query    Query
+		expected []StoredRecord
+	}{
+		{
+			name:     "all records",
+			expected: []StoredRecord{e, d, c, b, a},
+		},
+		{
+			name:     "by pool",
+			query:    Query{Pool: "org/repo:main"},
+			expected: []StoredRecord{b, a},
+		},
+		{
+			name:     "by repo",
+			query:    Query{Repo: "org/repo"},
+			expected: []StoredRecord{d, b, a},
+		},
+		{
+			name:     "by PR",
+			query:    Query{PR: 1},
+			expected: []StoredRecord{c, b, a},
+		},
+		{
+			name:     "by action",
+			query:    Query{Action: "MERGE"},
+			expected: []StoredRecord{d, b},
+		},
+		{
+			name:     "by time range",
+			query:    Query{From: day1.Add(time.Hour), To: day2},
+			expected: []StoredRecord{d, c, b},
+		},
+		{
+			name:     "by PR of a repo in a time range",
+			query:    Query{Repo: "org/other", PR: 1, From: day1, To: day2},
+			expected: []StoredRecord{c},
+		},
+		{
+			name:     "single day",
+			query:    Query{From: day2},
+			expected: []StoredRecord{e, d},
+		},
+		{
+			name:     "limited",
+			query:    Query{Limit: 2},
+			expected: []StoredRecord{e, d},
+		},
+		{
+			name:  "no match",
+			query: Query{Repo: "org/missing"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := store.Query(context.Background(), tc.query)
+			if err != nil {
+				t.Fatalf("Failed to query records: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, records); diff != "" {
+				t.Errorf("Records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHistoryQuery(t *testing.T) {
+	nowTime := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldNow := now
+	now = func() time.Time { return nowTime }
+	defer func() { now = oldNow }()
+	record := func(hist *History, pool, action string, pr int) {
+		nowTime = nowTime.Add(time.Minute)
+		hist.Record(pool, action, "sha", "", []prowapi.Pull{{Number: pr}}, nil)
+	}
+	actions := func(records []StoredRecord) []string {
+		var res []string
+		for _, rec := range records {
+			res = append(res, rec.Pool+" "+rec.Action)
+		}
+		return res
+	}
+
+	// Without a store, only the records of the logs can be queried.
+	hist, err := New(1, nil, "")
+	if err != nil {
+		t.Fatalf("Failed to create history client: %v", err)
+	}
+	record(hist, "org/repo:main", "TRIGGER", 1)
+	record(hist, "org/repo:main", "MERGE", 1)
+	records, err := hist.Query(context.Background(), Query{PR: 1})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if diff := cmp.Diff([]string{"org/repo:main MERGE"}, actions(records)); diff != "" {
+		t.Errorf("Records mismatch without a store (-want +got):\n%s", diff)
+	}
+
+	// With a store, the evicted records are kept, and the ones that are not
+	// flushed yet can be queried too.
+	hist, err = New(1, nil, "")
+	if err != nil {
+		t.Fatalf("Failed to create history client: %v", err)
+	}
+	opener := &fakeStoreOpener{objects: map[string]string{}}
+	hist.SetStore(&segmentStore{opener: opener, dir: fakeBucket + "history"})
+	record(hist, "org/repo:main", "TRIGGER", 1)
+	record(hist, "org/repo:main", "MERGE", 1)
+	hist.Flush()
+	record(hist, "org/repo:main", "TRIGGER", 2)
+	record(hist, "org/other:main", "TRIGGER", 1)
+	if len(opener.objects) != 1 {
+		t.Errorf("Expected a single segment to be flushed, got %d.", len(opener.objects))
+	}
+	records, err = hist.Query(context.Background(), Query{PR: 1})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	expected := []string{"org/other:main TRIGGER", "org/repo:main MERGE", "org/repo:main TRIGGER"}
+	if diff := cmp.Diff(expected, actions(records)); diff != "" {
+		t.Errorf("Records mismatch with a store (-want +got):\n%s", diff)
+	}
+}
+
+// fakeStore records the appended batches, unless it fails.
+type fakeStore struct {
+	fail     bool
+	appended [][]StoredRecord
+}
+
+func (f *fakeStore) Append(_ context.Context, records []StoredRecord) error {
+	if f.fail {
+		return errors.New("injected failure")
+	}
+	f.appended = append(f.appended, records)
+	return nil
+}
+
+func (f *fakeStore) Query(_ context.Context, _ Query) ([]StoredRecord, error) {
+	return nil, nil
+}
+
+func TestHistoryStoreRetries(t *testing.T) {
+	hist, err := New(1, nil, "")
+	if err != nil {
+		t.Fatalf("Failed to create history client: %v", err)
+	}
+	store := &fakeStore{fail: true}
+	hist.SetStore(store)
+	batchSizes := func() []int {
+		var res []int
+		for _, batch := range store.appended {
+			res = append(res, len(batch))
+		}
+		return res
+	}
+
+	// The records of a failed append are retried as is, before the new ones.
+	hist.Record("org/repo:main", "TRIGGER", "sha", "", []prowapi.Pull{{Number: 1}}, nil)
+	hist.Record("org/repo:main", "MERGE", "sha", "", []prowapi.Pull{{Number: 1}}, nil)
+	hist.Flush()
+	hist.Record("org/repo:main", "TRIGGER", "sha", "", []prowapi.Pull{{Number: 2}}, nil)
+	store.fail = false
+	hist.Flush()
+	if diff := cmp.Diff([]int{2, 1}, batchSizes()); diff != "" {
+		t.Errorf("Appended batches mismatch (-want +got):\n%s", diff)
+	}
+
+	// The oldest records are dropped beyond the limit.
+	store.fail, store.appended = true, nil
+	for i := 0; i < maxPendingRecords+2; i++ {
+		hist.Record("org/repo:main", "TRIGGER", "sha", "", []prowapi.Pull{{Number: i}}, nil)
+		if i == 0 {
+			hist.Flush()
+		}
+	}
+	store.fail = false
+	hist.Flush()
+	if diff := cmp.Diff([]int{maxPendingRecords}, batchSizes()); diff != "" {
+		t.Errorf("Appended batches mismatch (-want +got):\n%s", diff)
+	}
+	if first := store.appended[0][0].Target[0].Number; first != 2 {
+		t.Errorf("Expected the oldest kept record to target PR 2, got %d.", first)
+	}
+}
+
+func TestServeQuery(t *testing.T) {
+	hist, err := New(10, nil, "")
+	if err != nil {
+		t.Fatalf("Failed to create history client: %v", err)
+	}
+	hist.Record("org/repo:main", "MERGE", "sha", "", []prowapi.Pull{{Number: 1}}, nil)
+	hist.Record("org/repo:main", "TRIGGER", "sha", "", []prowapi.Pull{{Number: 2}}, nil)
+
+	tcs := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expected     []int
+	}{
+		{
+			name:         "all",
+			expectedCode: http.StatusOK,
+			expected:     []int{2, 1},
+		},
+		{
+			name:         "by PR",
+			query:        "?repo=org/repo&pr=1",
+			expectedCode: http.StatusOK,
+			expected:     []int{1},
+		},
+		{
+			name:         "before the default time range",
+			query:        "?to=" + time.Now().Add(-defaultQueryWindow).Format(time.RFC3339),
+			expectedCode: http.StatusOK,
+			expected:     []int{},
+		},
+		{
+			name:         "no match",
+			query:        "?action=MERGE_BATCH",
+			expectedCode: http.StatusOK,
+			expected:     []int{},
+		},
+		{
+			name:         "invalid PR",
+			query:        "?pr=one",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid time",
+			query:        "?to=yesterday",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "PR without a repo",
+			query:        "?pr=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "time range too large",
+			query:        "?from=2000-01-01T00:00:00Z",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			hist.ServeQuery(rr, httptest.NewRequest(http.MethodGet, "/history/query"+tc.query, nil))
+			if rr.Code != tc.expectedCode {
+				t.Fatalf("Expected status code %d, got %d.", tc.expectedCode, rr.Code)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			var records []StoredRecord
+			if err := json.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			prs := []int{}
+			for _, rec := range records {
+				prs = append(prs, rec.Target[0].Number)
+			}
+			if diff := cmp.Diff(tc.expected, prs); diff != "" {
+				t.Errorf("Records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/tide/history"
+)
+
+const (
+	// queueETAMerges is the number of recent merges of a pool used to
+	// estimate the merge time of its PRs.
+	queueETAMerges = 10
+	// queueETAGranularity is the rounding of the estimated merge time shown
+	// in the status context, so that it rarely changes between syncs.
+	queueETAGranularity = time.Hour
+)
+
+// QueuePosition is the position of a PR in the merge queue of its pool.
+type QueuePosition struct {
+	Org    string `json:"org"`
+	Repo   string `json:"repo"`
+	Branch string `json:"branch"`
+	Number int    `json:"number"`
+
+	// Position is the 1-based position of the PR in the queue. PRs are merged
+	// by priority, then oldest first.
+	Position int `json:"position"`
+	// PoolSize is the number of PRs in the queue.
+	PoolSize int `json:"poolSize"`
+	// Round is the 1-based number of the merge, either serial or batch, that
+	// is expected to merge the PR given the batch size limit.
+	Round int `json:"round"`
+	// ETA is the estimated merge time, from the interval between the recent
+	// merges of the pool. It is unset when the pool didn't merge enough PRs
+	// yet.
+	ETA *metav1.Time `json:"eta,omitempty"`
+}
+
+// statusDescription describes the position in the tide status context. The
+// ETA is rounded to the hour, as every change of the status context costs a
+// status update.
+func (qp *QueuePosition) statusDescription() string {
+	desc := fmt.Sprintf("In merge pool, position %d/%d", qp.Position, qp.PoolSize)
+	if qp.ETA != nil {
+		desc += fmt.Sprintf(", ETA ~%s UTC", qp.ETA.Time.UTC().Round(queueETAGranularity).Format("Jan 2 15:04"))
+	}
+	return desc + "."
+}
+
+// queuePositions computes the position of each PR of the subpool in its merge
+// queue.
+func queuePositions(sp subpool, priorities []config.TidePriority, batchSizeLimit int, records []*history.Record, now time.Time) []QueuePosition {
+	priority := func(pr CodeReviewCommon) int {
+		for i, p := range priorities {
+			if hasAllLabels(pr, p.Labels) {
+				return i
+			}
+		}
+		return len(priorities)
+	}
+	prs := sortedByNumber(sp.prs)
+	sort.SliceStable(prs, func(i, j int) bool { return priority(prs[i]) < priority(prs[j]) })
+
+	// A negative limit means that batches are disabled, and zero that their
+	// size is unlimited.
+	batchSize := batchSizeLimit
+	if batchSize < 0 {
+		batchSize = 1
+	}
+	lastMerge, interval := mergeInterval(records)
+
+	var res []QueuePosition
+	for i, pr := range prs {
+		pos := QueuePosition{
+			Org:      sp.org,
+			Repo:     sp.repo,
+			Branch:   sp.branch,
+			Number:   pr.Number,
+			Position: i + 1,
+			PoolSize: len(prs),
+			Round:    1,
+		}
+		if batchSize > 0 {
+			pos.Round = i/batchSize + 1
+		}
+		if interval > 0 {
+			// Count the rounds from now if the pool has been idle for longer
+			// than usual.
+			start := lastMerge
+			if now.Sub(lastMerge) > interval {
+				start = now
+			}
+			eta := metav1.NewTime(start.Add(time.Duration(pos.Round) * interval))
+			pos.ETA = &eta
+		}
+		res = append(res, pos)
+	}
+	return res
+}
+
+// mergeInterval returns the time of the last successful merge of a pool and
+// the average interval between its recent merges, from the newest first
+// records of the pool. The interval is zero if there are less than two
+// merges.
+func mergeInterval(records []*history.Record) (time.Time, time.Duration) {
+	var merges []time.Time
+	for _, record := range records {
+		if (record.Action != string(Merge) && record.Action != string(MergeBatch)) || record.Err != "" {
+			continue
+		}
+		merges = append(merges, record.Time)
+		if len(merges) == queueETAMerges {
+			break
+		}
+	}
+	if len(merges) < 2 {
+		return time.Time{}, 0
+	}
+	return merges[0], merges[0].Sub(merges[len(merges)-1]) / time.Duration(len(merges)-1)
+}
+
+// updateQueuePositions stores the queue positions of the PRs of a subpool.
+func (c *syncController) updateQueuePositions(sp subpool, positions []QueuePosition) {
+	key := poolKey(sp.org, sp.repo, sp.branch)
+	c.queueLock.Lock()
+	defer c.queueLock.Unlock()
+	if c.queue == nil {
+		c.queue = map[string][]QueuePosition{}
+	}
+	c.queue[key] = positions
+}
+
+// queuePositionsByPR forgets the queues of the pools that are gone, and
+// returns the queue positions by PR key.
+func (c *syncController) queuePositionsByPR(pools map[string]*subpool) map[string]QueuePosition {
+	c.queueLock.Lock()
+	defer c.queueLock.Unlock()
+
+	res := map[string]QueuePosition{}
+	for key, positions := range c.queue {
+		sp, ok := pools[key]
+		if !ok {
+			delete(c.queue, key)
+			continue
+		}
+		for _, pos := range positions {
+			for _, pr := range sp.prs {
+				if pr.Number == pos.Number {
+					res[prKey(&pr)] = pos
+					break
+				}
+			}
+		}
+	}
+	return res
+}
+
+// ServeQueue serves the queue positions of the PRs, filtered by the optional
+// org, repo, branch and number query parameters.
+func (c *syncController) ServeQueue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var number int
+	if n := query.Get("number"); n != "" {
+		var err error
+		if number, err = strconv.Atoi(n); err != nil {
+			http.Error(w, fmt.Sprintf("invalid number %q: %v", n, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	c.queueLock.Lock()
+	res := []QueuePosition{}
+	for _, positions := range c.queue {
+		for _, pos := range positions {
+			if (query.Get("org") != "" && pos.Org != query.Get("org")) ||
+				(query.Get("repo") != "" && pos.Repo != query.Get("repo")) ||
+				(query.Get("branch") != "" && pos.Branch != query.Get("branch")) ||
+				(number != 0 && pos.Number != number) {
+				continue
+			}
+			res = append(res, pos)
+		}
+	}
+	c.queueLock.Unlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if key1, key2 := poolKey(res[i].Org, res[i].Repo, res[i].Branch), poolKey(res[j].Org, res[j].Repo, res[j].Branch); key1 != key2 {
+			return key1 < key2
+		}
+		return res[i].Position < res[j].Position
+	})
+	b, err := json.Marshal(res)
+	if err != nil {
+		c.logger.WithError(err).Error("Encoding JSON.")
+		b = []byte("[]")
+	}
+	if _, err = w.Write(b); err != nil {
+		c.logger.WithError(err).Error("Writing JSON response.")
+	}
+}
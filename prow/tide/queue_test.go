@@ -0,0 +1,226 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/tide/history"
+)
+
+func TestQueuePositions(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	eta := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	pr := func(number int, labels ...string) CodeReviewCommon {
+		var pr PullRequest
+		pr.Number = githubql.Int(number)
+		for _, label := range labels {
+			pr.Labels.Nodes = append(pr.Labels.Nodes, struct{ Name githubql.String }{Name: githubql.String(label)})
+		}
+		return *CodeReviewCommonFromPullRequest(&pr)
+	}
+	merges := func(ago ...time.Duration) []*history.Record {
+		var res []*history.Record
+		for _, d := range ago {
+			res = append(res, &history.Record{Time: now.Add(-d), Action: string(Merge)})
+		}
+		return res
+	}
+
+	type position struct {
+		number, position, round int
+		eta                     *metav1.Time
+	}
+	tests := []struct {
+		name           string
+		prs            []CodeReviewCommon
+		priorities     []config.TidePriority
+		batchSizeLimit int
+		records        []*history.Record
+		want           []position
+	}{
+		{
+			name:           "oldest first, unlimited batches",
+			prs:            []CodeReviewCommon{pr(3), pr(1), pr(2)},
+			batchSizeLimit: 0,
+			want:           []position{{1, 1, 1, nil}, {2, 2, 1, nil}, {3, 3, 1, nil}},
+		},
+		{
+			name:           "batches disabled",
+			prs:            []CodeReviewCommon{pr(3), pr(1), pr(2)},
+			batchSizeLimit: -1,
+			want:           []position{{1, 1, 1, nil}, {2, 2, 2, nil}, {3, 3, 3, nil}},
+		},
+		{
+			name:           "priorities first",
+			prs:            []CodeReviewCommon{pr(1), pr(2, "kind/bug"), pr(3, "urgent"), pr(4, "kind/bug")},
+			priorities:     []config.TidePriority{{Labels: []string{"urgent"}}, {Labels: []string{"kind/bug"}}},
+			batchSizeLimit: 2,
+			want:           []position{{3, 1, 1, nil}, {2, 2, 1, nil}, {4, 3, 2, nil}, {1, 4, 2, nil}},
+		},
+		{
+			name:           "ETA from the recent merges",
+			prs:            []CodeReviewCommon{pr(1), pr(2)},
+			batchSizeLimit: 1,
+			records: append(
+				merges(10*time.Minute, 40*time.Minute, 70*time.Minute),
+				// Failed merges are ignored.
+				&history.Record{Time: now.Add(-20 * time.Minute), Action: string(MergeBatch), Err: "failed"},
+			),
+			want: []position{{1, 1, 1, eta(20 * time.Minute)}, {2, 2, 2, eta(50 * time.Minute)}},
+		},
+		{
+			name:           "ETA from now for idle pools",
+			prs:            []CodeReviewCommon{pr(1)},
+			batchSizeLimit: 1,
+			records:        merges(2*time.Hour, 3*time.Hour),
+			want:           []position{{1, 1, 1, eta(time.Hour)}},
+		},
+		{
+			name:           "no ETA without enough merges",
+			prs:            []CodeReviewCommon{pr(1)},
+			batchSizeLimit: 1,
+			records:        merges(time.Minute),
+			want:           []position{{1, 1, 1, nil}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sp := subpool{org: "org", repo: "repo", branch: "main", prs: tc.prs}
+			var want []QueuePosition
+			for _, pos := range tc.want {
+				want = append(want, QueuePosition{
+					Org:      "org",
+					Repo:     "repo",
+					Branch:   "main",
+					Number:   pos.number,
+					Position: pos.position,
+					PoolSize: len(tc.prs),
+					Round:    pos.round,
+					ETA:      pos.eta,
+				})
+			}
+			if diff := cmp.Diff(want, queuePositions(sp, tc.priorities, tc.batchSizeLimit, tc.records, now)); diff != "" {
+				t.Errorf("queue positions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQueuePositionStatusDescription(t *testing.T) {
+	pos := QueuePosition{Position: 4, PoolSize: 5, Round: 2}
+	if got, want := pos.statusDescription(), "In merge pool, position 4/5."; got != want {
+		t.Errorf("expected description %q, got %q", want, got)
+	}
+	for _, minute := range []int{3, 29} {
+		eta := metav1.NewTime(time.Date(2023, 6, 1, 12, minute, 0, 0, time.UTC))
+		pos.ETA = &eta
+		if got, want := pos.statusDescription(), "In merge pool, position 4/5, ETA ~Jun 1 12:00 UTC."; got != want {
+			t.Errorf("expected description %q, got %q", want, got)
+		}
+	}
+	eta := metav1.NewTime(time.Date(2023, 6, 1, 23, 40, 0, 0, time.UTC))
+	pos.ETA = &eta
+	if got, want := pos.statusDescription(), "In merge pool, position 4/5, ETA ~Jun 2 00:00 UTC."; got != want {
+		t.Errorf("expected description %q, got %q", want, got)
+	}
+}
+
+func TestServeQueue(t *testing.T) {
+	c := &syncController{logger: logrus.WithField("test", t.Name())}
+	c.updateQueuePositions(subpool{org: "org", repo: "repo", branch: "main"}, []QueuePosition{
+		{Org: "org", Repo: "repo", Branch: "main", Number: 2, Position: 1, PoolSize: 2, Round: 1},
+		{Org: "org", Repo: "repo", Branch: "main", Number: 1, Position: 2, PoolSize: 2, Round: 1},
+	})
+	c.updateQueuePositions(subpool{org: "org", repo: "other", branch: "main"}, []QueuePosition{
+		{Org: "org", Repo: "other", Branch: "main", Number: 1, Position: 1, PoolSize: 1, Round: 1},
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		want     []int
+	}{
+		{
+			name:     "all",
+			wantCode: http.StatusOK,
+			want:     []int{1, 2, 1},
+		},
+		{
+			name:     "by repo",
+			query:    "?org=org&repo=repo",
+			wantCode: http.StatusOK,
+			want:     []int{2, 1},
+		},
+		{
+			name:     "by number",
+			query:    "?repo=repo&number=1",
+			wantCode: http.StatusOK,
+			want:     []int{1},
+		},
+		{
+			name:     "no match",
+			query:    "?branch=release",
+			wantCode: http.StatusOK,
+			want:     []int{},
+		},
+		{
+			name:     "invalid number",
+			query:    "?number=one",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			c.ServeQueue(rr, httptest.NewRequest(http.MethodGet, "/queue"+tc.query, nil))
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected status code %d, got %d", tc.wantCode, rr.Code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var positions []QueuePosition
+			if err := json.Unmarshal(rr.Body.Bytes(), &positions); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			got := []int{}
+			for _, pos := range positions {
+				got = append(got, pos.Number)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("queue mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowscheme "k8s.io/test-infra/prow/client/clientset/versioned/scheme"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/git/types"
+	"k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/tide/history"
+)
+
+// SimulationSnapshot is a recorded state of the PRs and ProwJobs that Tide
+// syncs, used to simulate the actions Tide would take with a given config.
+type SimulationSnapshot struct {
+	// PullRequests are the open PRs as returned by the GitHub search of Tide.
+	// They can be the results of a wider search than the Tide queries, so that
+	// the PRs excluded by the queries are reported too.
+	PullRequests []PullRequest `json:"pullRequests"`
+	// BaseSHAs are the SHAs of the base branches, by "org/repo:branch" key.
+	BaseSHAs map[string]string `json:"baseSHAs"`
+	// ProwJobs are the presubmit and batch ProwJobs of the pools.
+	ProwJobs []prowapi.ProwJob `json:"prowJobs,omitempty"`
+	// Repos are the GitHub settings of the repos, by "org/repo" key, from
+	// which the allowed merge methods are taken. All merge methods are allowed
+	// for the repos that are missing.
+	Repos map[string]github.FullRepo `json:"repos,omitempty"`
+	// ChangedFiles are the files changed by the PRs, by "org/repo#number" key.
+	// They are only needed for the presubmits with run_if_changed or
+	// skip_if_only_changed.
+	ChangedFiles map[string][]string `json:"changedFiles,omitempty"`
+}
+
+// SimulatedPool is the outcome of the simulation of a subpool.
+type SimulatedPool struct {
+	Org     string `json:"org"`
+	Repo    string `json:"repo"`
+	Branch  string `json:"branch"`
+	BaseSHA string `json:"baseSHA,omitempty"`
+
+	SuccessPRs   []int `json:"successPRs,omitempty"`
+	PendingPRs   []int `json:"pendingPRs,omitempty"`
+	MissingPRs   []int `json:"missingPRs,omitempty"`
+	BatchPending []int `json:"batchPending,omitempty"`
+
+	// Action is the action Tide would take, it is empty when no PR of the
+	// subpool is in the pool.
+	Action  Action `json:"action,omitempty"`
+	Targets []int  `json:"targets,omitempty"`
+	// Jobs are the ProwJobs the action would trigger.
+	Jobs  []SimulatedJob `json:"jobs,omitempty"`
+	Error string         `json:"error,omitempty"`
+
+	// Excluded are the PRs of the subpool that are not in the pool.
+	Excluded []ExcludedPR `json:"excluded,omitempty"`
+}
+
+// SimulatedJob is a ProwJob that Tide would trigger.
+type SimulatedJob struct {
+	Name string              `json:"name"`
+	Type prowapi.ProwJobType `json:"type"`
+	PRs  []int               `json:"prs"`
+}
+
+// ExcludedPR is a PR that is not in the pool, with the reason why.
+type ExcludedPR struct {
+	Number int    `json:"number"`
+	Reason string `json:"reason"`
+}
+
+// Simulate computes the action Tide would take for each subpool of the
+// snapshot with the given config, without merging PRs nor triggering jobs.
+// The git client factory is only used to get the presubmits of the repos with
+// in-repo config enabled.
+//
+// The simulation doesn't check for merge conflicts between the PRs of a
+// batch, and it ignores the blocker issues.
+func Simulate(cfg config.Getter, gc git.ClientFactory, snapshot *SimulationSnapshot, logger *logrus.Entry) ([]SimulatedPool, error) {
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+	ghc := &simulationGitHubClient{snapshot: snapshot}
+	mergeChecker := &mergeChecker{config: cfg, ghc: ghc, cache: map[config.OrgRepo]map[types.PullRequestMergeType]bool{}}
+	provider := &simulationProvider{GitHubProvider: newGitHubProvider(logger, ghc, gc, cfg, mergeChecker, false)}
+	hist, err := history.New(1000, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	pjClient := newSimulationProwJobClient(snapshot.ProwJobs, map[string]ctrlruntimeclient.IndexerFunc{
+		cacheIndexName: cacheIndexFunc,
+		nonFailedBatchByNameBaseAndPullsIndexName: nonFailedBatchByNameBaseAndPullsIndexFunc,
+	})
+	c := &syncController{
+		ctx:           context.Background(),
+		logger:        logger.WithField("controller", "simulation"),
+		config:        cfg,
+		prowJobClient: pjClient,
+		provider:      provider,
+		pickNewBatch:  pickSimulatedBatch(provider),
+		changedFiles: &changedFilesAgent{
+			provider:        provider,
+			nextChangeCache: make(map[changeCacheKey][]string),
+		},
+		History: hist,
+		statusUpdate: &statusUpdate{
+			dontUpdateStatus: &threadSafePRSet{},
+			newPoolPending:   make(chan bool),
+		},
+	}
+
+	results := map[string]*SimulatedPool{}
+	resultFor := func(org, repo, branch string) *SimulatedPool {
+		key := poolKey(org, repo, branch)
+		if results[key] == nil {
+			results[key] = &SimulatedPool{Org: org, Repo: repo, Branch: branch, BaseSHA: snapshot.BaseSHAs[key]}
+		}
+		return results[key]
+	}
+
+	// Select the PRs matching the Tide queries, as the GitHub search does.
+	queryMap := cfg().Tide.Queries.QueryMap()
+	prs := make(map[string]CodeReviewCommon)
+	for i := range snapshot.PullRequests {
+		crc := CodeReviewCommonFromPullRequest(&snapshot.PullRequests[i])
+		cc, err := provider.GetTideContextPolicy(crc.Org, crc.Repo, crc.BaseRefName, ghc.baseSHAGetter(crc.Org, crc.Repo, crc.BaseRefName), crc)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up context checker for %s: %w", prKey(crc), err)
+		}
+		if matched, diff := closestQueryDiff(queryMap.ForRepo(config.OrgRepo{Org: crc.Org, Repo: crc.Repo}), crc, cc, false); !matched {
+			if diff == "" {
+				diff = " No Tide query matches."
+			}
+			resultFor(crc.Org, crc.Repo, crc.BaseRefName).exclude(crc.Number, "Not matching the Tide queries:"+diff)
+			continue
+		}
+		prs[prKey(crc)] = *crc
+	}
+
+	rawPools, err := c.dividePool(prs)
+	if err != nil {
+		return nil, err
+	}
+	var filteredPools []*subpool
+	for _, sp := range rawPools {
+		res := resultFor(sp.org, sp.repo, sp.branch)
+		if err := c.initSubpoolData(sp); err != nil {
+			return nil, fmt.Errorf("error initializing subpool %s: %w", poolKey(sp.org, sp.repo, sp.branch), err)
+		}
+		var toKeep []CodeReviewCommon
+		for _, pr := range sp.prs {
+			if reason := prFilterReason(provider, provider.isAllowedToMerge, sp, &pr); reason != "" {
+				res.exclude(pr.Number, reason)
+				continue
+			}
+			toKeep = append(toKeep, pr)
+		}
+		if len(toKeep) > 0 {
+			sp.prs = toKeep
+			filteredPools = append(filteredPools, sp)
+		}
+	}
+
+	for _, sp := range filteredPools {
+		res := resultFor(sp.org, sp.repo, sp.branch)
+		pool, err := c.syncSubpool(*sp, nil)
+		if err != nil {
+			res.Error = err.Error()
+		}
+		res.SuccessPRs = prNumbers(pool.SuccessPRs)
+		res.PendingPRs = prNumbers(pool.PendingPRs)
+		res.MissingPRs = prNumbers(pool.MissingPRs)
+		res.BatchPending = prNumbers(pool.BatchPending)
+		res.Action = pool.Action
+		res.Targets = prNumbers(pool.Target)
+		// Culprits of failed batches are filtered out when syncing.
+		for _, culprit := range c.batchCulprits[poolKey(sp.org, sp.repo, sp.branch)] {
+			res.exclude(culprit.pr.Number, culprit.statusDescription())
+		}
+	}
+
+	for _, pj := range pjClient.created {
+		if pj.Spec.Refs == nil {
+			continue
+		}
+		job := SimulatedJob{Name: pj.Spec.Job, Type: pj.Spec.Type}
+		for _, pull := range pj.Spec.Refs.Pulls {
+			job.PRs = append(job.PRs, pull.Number)
+		}
+		res := resultFor(pj.Spec.Refs.Org, pj.Spec.Refs.Repo, pj.Spec.Refs.BaseRef)
+		res.Jobs = append(res.Jobs, job)
+	}
+
+	var simulated []SimulatedPool
+	for _, res := range results {
+		for _, prs := range [][]int{res.SuccessPRs, res.PendingPRs, res.MissingPRs, res.BatchPending, res.Targets} {
+			sort.Ints(prs)
+		}
+		sort.Slice(res.Excluded, func(i, j int) bool { return res.Excluded[i].Number < res.Excluded[j].Number })
+		sort.SliceStable(res.Jobs, func(i, j int) bool { return res.Jobs[i].Name < res.Jobs[j].Name })
+		simulated = append(simulated, *res)
+	}
+	sort.Slice(simulated, func(i, j int) bool {
+		return poolKey(simulated[i].Org, simulated[i].Repo, simulated[i].Branch) < poolKey(simulated[j].Org, simulated[j].Repo, simulated[j].Branch)
+	})
+	return simulated, nil
+}
+
+func (sp *SimulatedPool) exclude(number int, reason string) {
+	sp.Excluded = append(sp.Excluded, ExcludedPR{Number: number, Reason: strings.TrimSpace(reason)})
+}
+
+// pickSimulatedBatch picks the first candidates as a new batch, assuming that
+// they merge cleanly together.
+func pickSimulatedBatch(provider provider) newBatchFunc {
+	return func(sp subpool, candidates []CodeReviewCommon, maxBatchSize int) ([]CodeReviewCommon, error) {
+		var res []CodeReviewCommon
+		for _, pr := range candidates {
+			if provider.prMergeMethod(&pr) == nil {
+				continue
+			}
+			res = append(res, pr)
+			if maxBatchSize > 0 && len(res) >= maxBatchSize {
+				break
+			}
+		}
+		return res, nil
+	}
+}
+
+// simulationProvider is a GitHub provider that doesn't merge PRs.
+type simulationProvider struct {
+	*GitHubProvider
+}
+
+func (p *simulationProvider) mergePRs(sp subpool, prs []CodeReviewCommon, _ *threadSafePRSet) ([]CodeReviewCommon, error) {
+	for _, pr := range prs {
+		if p.prMergeMethod(&pr) == nil {
+			return nil, fmt.Errorf("multiple merge method labels found for %s/%s#%d", sp.org, sp.repo, pr.Number)
+		}
+	}
+	return prs, nil
+}
+
+// simulationGitHubClient serves the GitHub data of a snapshot.
+type simulationGitHubClient struct {
+	snapshot *SimulationSnapshot
+}
+
+func (c *simulationGitHubClient) baseSHAGetter(org, repo, branch string) config.RefGetter {
+	return func() (string, error) {
+		return c.GetRef(org, repo, "heads/"+branch)
+	}
+}
+
+func (c *simulationGitHubClient) CreateStatus(string, string, string, github.Status) error {
+	return nil
+}
+
+func (c *simulationGitHubClient) GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error) {
+	return &github.CombinedStatus{}, nil
+}
+
+func (c *simulationGitHubClient) ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error) {
+	return &github.CheckRunList{}, nil
+}
+
+func (c *simulationGitHubClient) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
+	var changes []github.PullRequestChange
+	for _, file := range c.snapshot.ChangedFiles[fmt.Sprintf("%s/%s#%d", org, repo, number)] {
+		changes = append(changes, github.PullRequestChange{Filename: file})
+	}
+	return changes, nil
+}
+
+func (c *simulationGitHubClient) GetRef(org, repo, ref string) (string, error) {
+	branch := strings.TrimPrefix(ref, "heads/")
+	sha, ok := c.snapshot.BaseSHAs[poolKey(org, repo, branch)]
+	if !ok {
+		return "", fmt.Errorf("no base SHA recorded for %s", poolKey(org, repo, branch))
+	}
+	return sha, nil
+}
+
+func (c *simulationGitHubClient) GetRepo(owner, name string) (github.FullRepo, error) {
+	if repo, ok := c.snapshot.Repos[owner+"/"+name]; ok {
+		return repo, nil
+	}
+	var repo github.FullRepo
+	repo.AllowMergeCommit = true
+	repo.AllowSquashMerge = true
+	repo.AllowRebaseMerge = true
+	return repo, nil
+}
+
+func (c *simulationGitHubClient) Merge(string, string, int, github.MergeDetails) error {
+	return errors.New("merging is not supported by the simulation")
+}
+
+func (c *simulationGitHubClient) QueryWithGitHubAppsSupport(context.Context, interface{}, map[string]interface{}, string) error {
+	return errors.New("querying is not supported by the simulation")
+}
+
+// simulationProwJobClient serves the ProwJobs of a snapshot, and records the
+// ones that are created instead of creating them. Any other change of the
+// ProwJobs is not supported.
+type simulationProwJobClient struct {
+	lock       sync.Mutex
+	prowJobs   []prowapi.ProwJob
+	created    []prowapi.ProwJob
+	indexFuncs map[string]ctrlruntimeclient.IndexerFunc
+}
+
+func newSimulationProwJobClient(prowJobs []prowapi.ProwJob, indexFuncs map[string]ctrlruntimeclient.IndexerFunc) *simulationProwJobClient {
+	return &simulationProwJobClient{prowJobs: prowJobs, indexFuncs: indexFuncs}
+}
+
+func (c *simulationProwJobClient) List(_ context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
+	pjList, ok := list.(*prowapi.ProwJobList)
+	if !ok {
+		return errors.New("only ProwJobs can be listed")
+	}
+	listOpts := &ctrlruntimeclient.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	var indexFunc ctrlruntimeclient.IndexerFunc
+	var indexValue string
+	if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Empty() {
+		requirements := listOpts.FieldSelector.Requirements()
+		if len(requirements) > 1 {
+			return fmt.Errorf("at most one field selector requirement is supported, got %d", len(requirements))
+		}
+		if indexFunc, ok = c.indexFuncs[requirements[0].Field]; !ok {
+			return fmt.Errorf("no index with key %q found", requirements[0].Field)
+		}
+		indexValue = requirements[0].Value
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	pjList.Items = nil
+	for _, pj := range append(c.prowJobs, c.created...) {
+		pj := pj
+		if listOpts.Namespace != "" && pj.Namespace != listOpts.Namespace {
+			continue
+		}
+		if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(pj.Labels)) {
+			continue
+		}
+		if indexFunc != nil && !sets.New[string](indexFunc(&pj)...).Has(indexValue) {
+			continue
+		}
+		pjList.Items = append(pjList.Items, pj)
+	}
+	return nil
+}
+
+func (c *simulationProwJobClient) Create(_ context.Context, obj ctrlruntimeclient.Object, _ ...ctrlruntimeclient.CreateOption) error {
+	pj, ok := obj.(*prowapi.ProwJob)
+	if !ok {
+		return errors.New("only ProwJobs can be created")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.created = append(c.created, *pj.DeepCopy())
+	return nil
+}
+
+func (c *simulationProwJobClient) Get(_ context.Context, key ctrlruntimeclient.ObjectKey, obj ctrlruntimeclient.Object) error {
+	pj, ok := obj.(*prowapi.ProwJob)
+	if !ok {
+		return errors.New("only ProwJobs can be read")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, existing := range append(c.prowJobs, c.created...) {
+		if existing.Namespace == key.Namespace && existing.Name == key.Name {
+			existing.DeepCopyInto(pj)
+			return nil
+		}
+	}
+	return kerrors.NewNotFound(prowapi.Resource("prowjobs"), key.Name)
+}
+
+// errNotSupportedInSimulation is returned for the changes of the ProwJobs other
+// than their creation, which the simulation can't record.
+var errNotSupportedInSimulation = errors.New("not supported in simulation")
+
+func (c *simulationProwJobClient) Delete(context.Context, ctrlruntimeclient.Object, ...ctrlruntimeclient.DeleteOption) error {
+	return fmt.Errorf("deleting ProwJobs is %w", errNotSupportedInSimulation)
+}
+
+func (c *simulationProwJobClient) Update(context.Context, ctrlruntimeclient.Object, ...ctrlruntimeclient.UpdateOption) error {
+	return fmt.Errorf("updating ProwJobs is %w", errNotSupportedInSimulation)
+}
+
+func (c *simulationProwJobClient) Patch(context.Context, ctrlruntimeclient.Object, ctrlruntimeclient.Patch, ...ctrlruntimeclient.PatchOption) error {
+	return fmt.Errorf("patching ProwJobs is %w", errNotSupportedInSimulation)
+}
+
+func (c *simulationProwJobClient) DeleteAllOf(context.Context, ctrlruntimeclient.Object, ...ctrlruntimeclient.DeleteAllOfOption) error {
+	return fmt.Errorf("deleting ProwJobs is %w", errNotSupportedInSimulation)
+}
+
+func (c *simulationProwJobClient) Status() ctrlruntimeclient.StatusWriter {
+	return simulationStatusWriter{}
+}
+
+func (c *simulationProwJobClient) Scheme() *runtime.Scheme {
+	return prowscheme.Scheme
+}
+
+func (c *simulationProwJobClient) RESTMapper() meta.RESTMapper {
+	return meta.NewDefaultRESTMapper(nil)
+}
+
+// simulationStatusWriter rejects the updates of the status of the ProwJobs.
+type simulationStatusWriter struct{}
+
+func (simulationStatusWriter) Update(context.Context, ctrlruntimeclient.Object, ...ctrlruntimeclient.UpdateOption) error {
+	return fmt.Errorf("updating the status of ProwJobs is %w", errNotSupportedInSimulation)
+}
+
+func (simulationStatusWriter) Patch(context.Context, ctrlruntimeclient.Object, ctrlruntimeclient.Patch, ...ctrlruntimeclient.PatchOption) error {
+	return fmt.Errorf("patching the status of ProwJobs is %w", errNotSupportedInSimulation)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	githubql "github.com/shurcooL/githubv4"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+)
+
+func TestSimulate(t *testing.T) {
+	const pjNamespace = "pj-ns"
+	cfg := &config.Config{ProwConfig: config.ProwConfig{
+		ProwJobNamespace: pjNamespace,
+		Tide: config.Tide{
+			TideGitHubConfig: config.TideGitHubConfig{
+				Queries: config.TideQueries{{Repos: []string{"org/repo"}, Labels: []string{"lgtm"}}},
+			},
+		},
+	}}
+	if err := cfg.SetPresubmits(map[string][]config.Presubmit{
+		"org/repo": {{
+			JobBase:   config.JobBase{Name: "job"},
+			Reporter:  config.Reporter{Context: "job"},
+			AlwaysRun: true,
+		}},
+	}); err != nil {
+		t.Fatalf("failed to set presubmits: %v", err)
+	}
+
+	pr := func(number int, mergeable githubql.MergeableState, jobState githubql.StatusState, labels ...string) PullRequest {
+		pr := testPRWithLabels("org", "repo", "main", number, mergeable, labels)
+		pr.Commits.Nodes[0].Commit.Status.Contexts = []Context{{Context: "job", State: jobState}}
+		return *pr
+	}
+	passed := func(number int) prowapi.ProwJob {
+		return prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "passed", Namespace: pjNamespace},
+			Spec: prowapi.ProwJobSpec{
+				Type:    prowapi.PresubmitJob,
+				Job:     "job",
+				Context: "job",
+				Refs: &prowapi.Refs{
+					Org:     "org",
+					Repo:    "repo",
+					BaseRef: "main",
+					BaseSHA: "base",
+					Pulls:   []prowapi.Pull{{Number: number, SHA: "SHA"}},
+				},
+			},
+			Status: prowapi.ProwJobStatus{State: prowapi.SuccessState},
+		}
+	}
+	excluded := []ExcludedPR{
+		{Number: 3, Reason: "Not matching the Tide queries: Needs lgtm label."},
+		{Number: 4, Reason: "Context job is failure."},
+		{Number: 5, Reason: "PR has a merge conflict."},
+	}
+	excludedPRs := []PullRequest{
+		pr(3, githubql.MergeableStateMergeable, githubql.StatusStateSuccess),
+		pr(4, githubql.MergeableStateMergeable, githubql.StatusStateFailure, "lgtm"),
+		pr(5, githubql.MergeableStateConflicting, githubql.StatusStateSuccess, "lgtm"),
+	}
+
+	tests := []struct {
+		name     string
+		prs      []PullRequest
+		prowJobs []prowapi.ProwJob
+		expected []SimulatedPool
+	}{
+		{
+			name:     "merge the PR that passed",
+			prs:      append([]PullRequest{pr(1, githubql.MergeableStateMergeable, githubql.StatusStateSuccess, "lgtm")}, excludedPRs...),
+			prowJobs: []prowapi.ProwJob{passed(1)},
+			expected: []SimulatedPool{{
+				Org:        "org",
+				Repo:       "repo",
+				Branch:     "main",
+				BaseSHA:    "base",
+				SuccessPRs: []int{1},
+				Action:     Merge,
+				Targets:    []int{1},
+				Excluded:   excluded,
+			}},
+		},
+		{
+			name: "trigger a batch",
+			prs: append([]PullRequest{
+				pr(1, githubql.MergeableStateMergeable, githubql.StatusStateSuccess, "lgtm"),
+				pr(2, githubql.MergeableStateMergeable, githubql.StatusStateSuccess, "lgtm"),
+			}, excludedPRs...),
+			expected: []SimulatedPool{{
+				Org:        "org",
+				Repo:       "repo",
+				Branch:     "main",
+				BaseSHA:    "base",
+				MissingPRs: []int{1, 2},
+				Action:     TriggerBatch,
+				Targets:    []int{1, 2},
+				Jobs:       []SimulatedJob{{Name: "job", Type: prowapi.BatchJob, PRs: []int{1, 2}}},
+				Excluded:   excluded,
+			}},
+		},
+		{
+			name: "only excluded PRs",
+			prs:  excludedPRs,
+			expected: []SimulatedPool{{
+				Org:      "org",
+				Repo:     "repo",
+				Branch:   "main",
+				BaseSHA:  "base",
+				Excluded: excluded,
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshot := &SimulationSnapshot{
+				PullRequests: tc.prs,
+				BaseSHAs:     map[string]string{"org/repo:main": "base"},
+				ProwJobs:     tc.prowJobs,
+			}
+			pools, err := Simulate(func() *config.Config { return cfg }, nil, snapshot, nil)
+			if err != nil {
+				t.Fatalf("failed to simulate: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, pools); diff != "" {
+				t.Errorf("simulated pools mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSimulationProwJobClient(t *testing.T) {
+	existing := prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Namespace: "prowjobs", Name: "existing"}}
+	c := newSimulationProwJobClient([]prowapi.ProwJob{existing}, nil)
+	ctx := context.Background()
+
+	var pj prowapi.ProwJob
+	if err := c.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "prowjobs", Name: "existing"}, &pj); err != nil {
+		t.Fatalf("failed to get the ProwJob: %v", err)
+	}
+	if diff := cmp.Diff(existing, pj); diff != "" {
+		t.Errorf("ProwJob mismatch (-want +got):\n%s", diff)
+	}
+	if err := c.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "prowjobs", Name: "missing"}, &pj); !kerrors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if err := c.Patch(ctx, &pj, ctrlruntimeclient.MergeFrom(existing.DeepCopy())); !errors.Is(err, errNotSupportedInSimulation) {
+		t.Errorf("expected patching not to be supported, got %v", err)
+	}
+	if err := c.Status().Update(ctx, &pj); !errors.Is(err, errNotSupportedInSimulation) {
+		t.Errorf("expected updating the status not to be supported, got %v", err)
+	}
+}
@@ -98,6 +98,12 @@ type statusUpdate struct {
 	poolPRs          map[string]CodeReviewCommon
 	baseSHAs         map[string]string
 	requiredContexts map[string][]string
+	// batchCulprits are the PRs that were found to break batches by
+	// bisection, by PR key.
+	batchCulprits map[string]batchCulprit
+	// queuePositions are the positions of the PRs in the merge queues, by
+	// PR key.
+	queuePositions map[string]QueuePosition
 	sync.Mutex
 	// dontUpdateStatus contains all PRs for which the Tide sync controller
 	// updated the status to success prior to merging. As the name suggests,
@@ -108,6 +114,29 @@ type statusUpdate struct {
 	newPoolPending chan bool
 }
 
+// batchCulprit returns the batch culprit for the PR, if bisection found that
+// it breaks batches on top of the base SHA.
+func (su *statusUpdate) batchCulprit(crc *CodeReviewCommon, baseSHA string) (batchCulprit, bool) {
+	su.Lock()
+	defer su.Unlock()
+	culprit, ok := su.batchCulprits[prKey(crc)]
+	if !ok || culprit.pr.HeadRefOID != crc.HeadRefOID || culprit.baseSHA != baseSHA {
+		return batchCulprit{}, false
+	}
+	return culprit, true
+}
+
+// inPoolDescription describes the position of a PR in the merge queue, if
+// known.
+func (su *statusUpdate) inPoolDescription(crc *CodeReviewCommon) string {
+	su.Lock()
+	defer su.Unlock()
+	if pos, ok := su.queuePositions[prKey(crc)]; ok {
+		return pos.statusDescription()
+	}
+	return statusInPool
+}
+
 func (sc *statusController) shutdown() {
 	close(sc.newPoolPending)
 	<-sc.shutDown
@@ -286,6 +315,11 @@ func (sc *statusController) expectedStatus(log *logrus.Entry, queryMap *config.Q
 		return github.StatusError, fmt.Sprintf(statusNotInPool, " "+reason), nil
 	}
 
+	if culprit, ok := sc.statusUpdate.batchCulprit(crc, baseSHA); ok {
+		log.WithField("failed-contexts", culprit.failedContexts).Debug("The PR breaks batch testing")
+		return github.StatusError, fmt.Sprintf(statusNotInPool, " "+culprit.statusDescription()), nil
+	}
+
 	cc, err := ccg()
 	if err != nil {
 		return "", "", fmt.Errorf("failed to set up context register: %w", err)
@@ -310,33 +344,7 @@ func (sc *statusController) expectedStatus(log *logrus.Entry, queryMap *config.Q
 		// at the time the status controller queried GitHub but not at the time the sync controller queried GitHub.
 		// We just fall through to check if there are missing jobs to avoid wasting api tokens by sending it to pending and then to success in the next
 		// sync or status controller iteration.
-		var hasFullfilledQuery bool
-
-		minDiffCount := -1
-		var minDiff string
-		for _, q := range queryMap.ForRepo(repo) {
-			diff, diffCount := requirementDiff(pr, &q, cc)
-			if diffCount == 0 {
-				hasFullfilledQuery = true
-				break
-			} else if sc.config().Tide.DisplayAllQueriesInStatus {
-				if diffCount >= 2000 {
-					// Query is for wrong branch
-					continue
-				}
-				if minDiff != "" {
-					minDiff = strings.TrimSuffix(minDiff, ".") + " OR"
-				}
-				minDiff += diff
-			} else if minDiffCount == -1 || diffCount < minDiffCount {
-				minDiffCount = diffCount
-				minDiff = diff
-			}
-		}
-		if sc.config().Tide.DisplayAllQueriesInStatus && minDiff == "" {
-			minDiff = " No Tide query for branch " + crc.BaseRefName + " found."
-		}
-
+		hasFullfilledQuery, minDiff := closestQueryDiff(queryMap.ForRepo(repo), crc, cc, sc.config().Tide.DisplayAllQueriesInStatus)
 		if !hasFullfilledQuery {
 			return github.StatusPending, fmt.Sprintf(statusNotInPool, minDiff), nil
 		}
@@ -357,7 +365,37 @@ func (sc *statusController) expectedStatus(log *logrus.Entry, queryMap *config.Q
 	if diff := cc.MissingRequiredContexts(passingUpToDateContexts); len(diff) > 0 {
 		return github.StatePending, retestingStatus(diff), nil
 	}
-	return github.StatusSuccess, statusInPool, nil
+	return github.StatusSuccess, sc.statusUpdate.inPoolDescription(crc), nil
+}
+
+// closestQueryDiff describes what the PR is missing to match the query of
+// its repo that it is the closest to meeting, or all of them if displayAll is
+// set. It returns whether the PR matches one of the queries instead.
+func closestQueryDiff(queries config.TideQueries, crc *CodeReviewCommon, cc contextChecker, displayAll bool) (bool, string) {
+	minDiffCount := -1
+	var minDiff string
+	for _, q := range queries {
+		diff, diffCount := requirementDiff(crc.GitHub, &q, cc)
+		if diffCount == 0 {
+			return true, ""
+		} else if displayAll {
+			if diffCount >= 2000 {
+				// Query is for wrong branch
+				continue
+			}
+			if minDiff != "" {
+				minDiff = strings.TrimSuffix(minDiff, ".") + " OR"
+			}
+			minDiff += diff
+		} else if minDiffCount == -1 || diffCount < minDiffCount {
+			minDiffCount = diffCount
+			minDiff = diff
+		}
+	}
+	if displayAll && minDiff == "" {
+		minDiff = " No Tide query for branch " + crc.BaseRefName + " found."
+	}
+	return false, minDiff
 }
 
 func retestingStatus(retested []string) string {
@@ -90,6 +90,16 @@ type syncController struct {
 
 	History *history.History
 
+	// batchCulprits are the PRs found to break batches by bisection in the
+	// last sync of each pool, by pool key.
+	batchCulprits     map[string][]batchCulprit
+	batchCulpritsLock sync.Mutex
+
+	// queue contains the positions of the PRs in the merge queue of each pool,
+	// by pool key.
+	queue     map[string][]QueuePosition
+	queueLock sync.Mutex
+
 	// Shared fields with status controller
 	statusUpdate *statusUpdate
 }
@@ -103,6 +113,7 @@ const (
 	Wait         Action = "WAIT"
 	Trigger      Action = "TRIGGER"
 	TriggerBatch Action = "TRIGGER_BATCH"
+	BisectBatch  Action = "BISECT_BATCH"
 	Merge        Action = "MERGE"
 	MergeBatch   Action = "MERGE_BATCH"
 	PoolBlocked  Action = "BLOCKED"
@@ -113,6 +124,7 @@ const (
 var recordableActions = map[Action]bool{
 	Trigger:      true,
 	TriggerBatch: true,
+	BisectBatch:  true,
 	Merge:        true,
 	MergeBatch:   true,
 }
@@ -317,6 +329,11 @@ func (c *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c.syncCtrl.ServeHTTP(w, r)
 }
 
+// ServeQueue serves the positions of the PRs in the merge queues.
+func (c *Controller) ServeQueue(w http.ResponseWriter, r *http.Request) {
+	c.syncCtrl.ServeQueue(w, r)
+}
+
 func (c *Controller) History() *history.History {
 	return c.syncCtrl.History
 }
@@ -545,6 +562,13 @@ func (c *syncController) Sync() error {
 	c.pools = pools
 	c.m.Unlock()
 
+	batchCulprits := c.batchCulpritsByPR(filteredPools)
+	queuePositions := c.queuePositionsByPR(filteredPools)
+	c.statusUpdate.Lock()
+	c.statusUpdate.batchCulprits = batchCulprits
+	c.statusUpdate.queuePositions = queuePositions
+	c.statusUpdate.Unlock()
+
 	c.History.Flush()
 	return utilerrors.NewAggregate(queryErrors)
 }
@@ -680,14 +704,20 @@ func filterSubpool(provider provider, mergeAllowed func(*CodeReviewCommon) (stri
 //
 // This function works for any source code provider.
 func filterPR(provider provider, mergeAllowed func(*CodeReviewCommon) (string, error), sp *subpool, pr *CodeReviewCommon) bool {
+	return prFilterReason(provider, mergeAllowed, sp, pr) != ""
+}
+
+// prFilterReason returns why a PR should be filtered out of the subpool, see
+// filterPR, or an empty string if it should be kept.
+func prFilterReason(provider provider, mergeAllowed func(*CodeReviewCommon) (string, error), sp *subpool, pr *CodeReviewCommon) string {
 	log := sp.log.WithFields(pr.logFields())
 	// Skip PRs that are known to be unmergeable.
 	if reason, err := mergeAllowed(pr); err != nil {
 		log.WithError(err).Error("Error checking PR mergeability.")
-		return true
+		return fmt.Sprintf("Error checking PR mergeability: %v.", err)
 	} else if reason != "" {
 		log.WithField("reason", reason).Debug("filtering out PR as it is not mergeable")
-		return true
+		return reason
 	}
 
 	// Filter out PRs with unsuccessful contexts unless the only unsuccessful
@@ -695,7 +725,7 @@ func filterPR(provider provider, mergeAllowed func(*CodeReviewCommon) (string, e
 	contexts, err := provider.headContexts(pr)
 	if err != nil {
 		log.WithError(err).Error("Getting head contexts.")
-		return true
+		return fmt.Sprintf("Error getting head contexts: %v.", err)
 	}
 	presubmitsHaveContext := func(context string) bool {
 		for _, job := range sp.presubmits[pr.Number] {
@@ -708,15 +738,18 @@ func filterPR(provider provider, mergeAllowed func(*CodeReviewCommon) (string, e
 	for _, ctx := range unsuccessfulContexts(contexts, sp.cc[pr.Number], log) {
 		if ctx.State != githubql.StatusStatePending {
 			log.WithField("context", ctx.Context).Debug("filtering out PR as unsuccessful context is not pending")
-			return true
+			if ctx.State == githubql.StatusStateExpected {
+				return fmt.Sprintf("Required context %s is missing.", ctx.Context)
+			}
+			return fmt.Sprintf("Context %s is %s.", ctx.Context, strings.ToLower(string(ctx.State)))
 		}
 		if !presubmitsHaveContext(string(ctx.Context)) {
 			log.WithField("context", ctx.Context).Debug("filtering out PR as unsuccessful context is not Prow-controlled")
-			return true
+			return fmt.Sprintf("Context %s is pending and not run by Prow.", ctx.Context)
 		}
 	}
 
-	return false
+	return ""
 }
 
 func baseSHAMap(subpoolMap map[string]*subpool) map[string]string {
@@ -821,12 +854,7 @@ func hasAllLabels(pr CodeReviewCommon, wantLabels []string) bool {
 	if len(wantLabels) == 0 {
 		return true
 	}
-	prLabels := sets.New[string]()
-	if labels := pr.GitHubLabels(); labels != nil {
-		for _, l2 := range labels.Nodes {
-			prLabels.Insert(string(l2.Name))
-		}
-	}
+	prLabels := sets.New[string](pr.labels()...)
 	for _, label := range wantLabels {
 		altLabels := strings.Split(label, ",")
 		if !prLabels.HasAny(altLabels...) {
@@ -841,7 +869,7 @@ func pickHighestPriorityPR(log *logrus.Entry, prs []CodeReviewCommon, cc map[int
 	var smallestPR CodeReviewCommon
 	for _, p := range append(priorities, config.TidePriority{}) {
 		for _, pr := range prs {
-			// This should only apply to GitHub PRs, for Gerrit this is always true.
+			// This should only apply to GitHub and GitLab PRs, for Gerrit this is always true.
 			if !hasAllLabels(pr, p.Labels) {
 				continue
 			}
@@ -871,6 +899,32 @@ func pickHighestPriorityPR(log *logrus.Entry, prs []CodeReviewCommon, cc map[int
 // we should consider this batch as failed so that takeAction can trigger a new
 // batch.
 func (c *syncController) accumulateBatch(sp subpool) (successBatch []CodeReviewCommon, pendingBatch []CodeReviewCommon) {
+	for _, res := range c.accumulateBatchResults(sp) {
+		switch res.state {
+		// Currently we only consider 1 pending batch and 1 success batch at a time.
+		// If more are somehow present they will be ignored.
+		case pendingState:
+			pendingBatch = res.prs
+		case successState:
+			successBatch = res.prs
+		}
+	}
+	return successBatch, pendingBatch
+}
+
+// batchResult is the overall state of the required presubmits of a batch.
+type batchResult struct {
+	prs   []CodeReviewCommon
+	state simpleState
+	// failedContexts are the required contexts for which all jobs failed.
+	// A batch can be in failureState without failed contexts, when some
+	// required presubmits were never triggered.
+	failedContexts []string
+}
+
+// accumulateBatchResults returns the result of every batch of the subpool
+// whose pull requests are still at the tested heads, by refs.
+func (c *syncController) accumulateBatchResults(sp subpool) map[string]*batchResult {
 	sp.log.Debug("accumulating PRs for batch testing")
 	prNums := make(map[int]CodeReviewCommon)
 	for _, pr := range sp.prs {
@@ -921,6 +975,7 @@ func (c *syncController) accumulateBatch(sp subpool) (successBatch []CodeReviewC
 		// Store the best result for this ref+context.
 		states[ref].jobStates[context] = getBetterSimpleState(states[ref].jobStates[context], jobState)
 	}
+	results := make(map[string]*batchResult)
 	for ref, state := range states {
 		if !state.validPulls {
 			continue
@@ -935,33 +990,29 @@ func (c *syncController) accumulateBatch(sp subpool) (successBatch []CodeReviewC
 			continue
 		}
 
-		overallState := successState
+		res := &batchResult{prs: state.prs, state: successState}
+		failedContexts := sets.New[string]()
 		for _, p := range requiredPresubmits {
 			if s, ok := state.jobStates[p.Context]; !ok {
 				// This could happen to jobs configured as `run_before_merge` as
 				// these jobs are triggered only by tide. There is no need to
 				// handle it differently as a new batch is expected in both cases.
-				overallState = failureState
+				res.state = failureState
 				sp.log.WithField("batch", ref).Debugf("batch invalid, required presubmit %s is missing", p.Context)
-				break
 			} else if s == failureState {
-				overallState = failureState
+				res.state = failureState
+				failedContexts.Insert(p.Context)
 				sp.log.WithField("batch", ref).Debugf("batch invalid, required presubmit %s is not passing", p.Context)
-				break
-			} else if s == pendingState && overallState == successState {
-				overallState = pendingState
+			} else if s == pendingState && res.state == successState {
+				res.state = pendingState
 			}
 		}
-		switch overallState {
-		// Currently we only consider 1 pending batch and 1 success batch at a time.
-		// If more are somehow present they will be ignored.
-		case pendingState:
-			pendingBatch = state.prs
-		case successState:
-			successBatch = state.prs
+		if failedContexts.Len() > 0 {
+			res.failedContexts = sets.List(failedContexts)
 		}
+		results[ref] = res
 	}
-	return successBatch, pendingBatch
+	return results
 }
 
 // prowJobsFromContexts constructs ProwJob objects from all successful presubmit contexts that include a baseSHA.
@@ -1350,6 +1401,12 @@ func tryMerge(mergeFunc func() error) (bool, error) {
 }
 
 func (c *syncController) trigger(sp subpool, presubmits []config.Presubmit, prs []CodeReviewCommon) error {
+	return c.triggerJobs(sp, presubmits, prs, len(prs) > 1)
+}
+
+// triggerJobs triggers the presubmits for the PRs, either as batch jobs or as
+// presubmit jobs, which require a single PR.
+func (c *syncController) triggerJobs(sp subpool, presubmits []config.Presubmit, prs []CodeReviewCommon, batch bool) error {
 	refs, err := c.provider.refsForJob(sp, prs)
 	if err != nil {
 		return fmt.Errorf("failed creating refs: %v", err)
@@ -1365,7 +1422,7 @@ func (c *syncController) trigger(sp subpool, presubmits []config.Presubmit, prs
 		}
 		triggeredContexts.Insert(string(ps.Context))
 		var spec prowapi.ProwJobSpec
-		if len(prs) == 1 {
+		if !batch {
 			spec = pjutil.PresubmitSpec(ps, refs)
 		} else {
 			if c.nonFailedBatchForJobAndRefsExists(ps.Name, &refs) {
@@ -1406,7 +1463,7 @@ func (c *syncController) nonFailedBatchForJobAndRefsExists(jobName string, refs
 	return len(pjs.Items) > 0
 }
 
-func (c *syncController) takeAction(sp subpool, batchPending, successes, pendings, missings, batchMerges []CodeReviewCommon, missingSerialTests map[int][]config.Presubmit) (Action, []CodeReviewCommon, error) {
+func (c *syncController) takeAction(sp subpool, batchPending, successes, pendings, missings, batchMerges []CodeReviewCommon, missingSerialTests map[int][]config.Presubmit, bisectSubBatches [][]CodeReviewCommon) (Action, []CodeReviewCommon, error) {
 	var merged []CodeReviewCommon
 	var err error
 	defer func() {
@@ -1432,6 +1489,14 @@ func (c *syncController) takeAction(sp subpool, batchPending, successes, pending
 	if len(sp.presubmits) == 0 {
 		return Wait, nil, nil
 	}
+	// If failed batches are being bisected, test the next sub-batches.
+	if len(bisectSubBatches) > 0 {
+		var targets []CodeReviewCommon
+		for _, subBatch := range bisectSubBatches {
+			targets = append(targets, subBatch...)
+		}
+		return BisectBatch, targets, c.triggerSubBatches(sp, bisectSubBatches)
+	}
 	// If we have no batch, trigger one.
 	if len(sp.prs) > 1 && len(batchPending) == 0 {
 		batch, presubmits, err := c.pickBatch(sp, sp.cc, c.pickNewBatch)
@@ -1656,6 +1721,35 @@ func (c *syncController) syncSubpool(sp subpool, blocks []blockers.Blocker) (Poo
 		"batch-pending": prNumbers(batchPending),
 	}).Info("Subpool accumulated.")
 
+	var bisection batchBisection
+	if c.config().Tide.BisectFailedBatches(config.OrgRepo{Org: sp.org, Repo: sp.repo}) {
+		bisection = c.bisectFailedBatches(sp)
+		c.updateBatchCulprits(sp, bisection.culprits)
+		if len(bisection.culprits) > 0 {
+			// PRs breaking batches must neither be merged nor picked for
+			// new batches, until they or the base branch change.
+			sp.prs = withoutBatchCulprits(sp.prs, bisection.culprits)
+			successes = withoutBatchCulprits(successes, bisection.culprits)
+		}
+		if len(batchMerge) > 0 && bisection.holds(batchMerge) {
+			sp.log.WithField("batch-passing", prNumbers(batchMerge)).Debug("Holding the merge of a bisected sub-batch until the culprit is found.")
+			batchMerge = nil
+		}
+		sp.log.WithFields(logrus.Fields{
+			"batch-culprits":     len(bisection.culprits),
+			"bisect-sub-batches": len(bisection.subBatches),
+		}).Debug("Failed batches bisected.")
+	}
+
+	orgRepo := config.OrgRepo{Org: sp.org, Repo: sp.repo}
+	c.updateQueuePositions(sp, queuePositions(
+		sp,
+		c.config().Tide.Priority,
+		c.config().Tide.BatchSizeLimit(orgRepo),
+		c.History.PoolRecords(poolKey(sp.org, sp.repo, sp.branch)),
+		time.Now(),
+	))
+
 	tenantIDs := sp.TenantIDs()
 	var act Action
 	var targets []CodeReviewCommon
@@ -1664,7 +1758,7 @@ func (c *syncController) syncSubpool(sp subpool, blocks []blockers.Blocker) (Poo
 	if len(blocks) > 0 {
 		act = PoolBlocked
 	} else {
-		act, targets, err = c.takeAction(sp, batchPending, successes, pendings, missings, batchMerge, missingSerialTests)
+		act, targets, err = c.takeAction(sp, batchPending, successes, pendings, missings, batchMerge, missingSerialTests, bisection.subBatches)
 		if err != nil {
 			errorString = err.Error()
 		}
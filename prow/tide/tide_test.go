@@ -1424,6 +1424,7 @@ func testTakeAction(clients localgit.Clients, t *testing.T) {
 		pendings        []int
 		nones           []int
 		batchMerges     []int
+		bisectBatches   [][]int
 		presubmits      map[int][]config.Presubmit
 		preExistingJobs []runtime.Object
 		mergeErrs       map[int]error
@@ -1505,6 +1506,26 @@ func testTakeAction(clients localgit.Clients, t *testing.T) {
 			triggered: 1,
 			action:    Trigger,
 		},
+		{
+			name: "failed batch bisected, should trigger sub-batches",
+
+			batchPending:  false,
+			successes:     []int{},
+			pendings:      []int{0},
+			nones:         []int{1},
+			batchMerges:   []int{},
+			bisectBatches: [][]int{{2}, {3, 4}},
+			presubmits: map[int][]config.Presubmit{
+				100: {
+					{Reporter: config.Reporter{Context: "foo"}},
+					{Reporter: config.Reporter{Context: "if-changed"}},
+				},
+			},
+			merged:           0,
+			triggered:        4,
+			triggeredBatches: 4,
+			action:           BisectBatch,
+		},
 		{
 			name: "no pending batch, should trigger batch",
 
@@ -1956,11 +1977,15 @@ func testTakeAction(clients localgit.Clients, t *testing.T) {
 				provider:        ghProvider,
 				nextChangeCache: make(map[changeCacheKey][]string),
 			}
+			var bisectSubBatches [][]CodeReviewCommon
+			for _, subBatch := range tc.bisectBatches {
+				bisectSubBatches = append(bisectSubBatches, genPulls(subBatch))
+			}
 			var batchPending []CodeReviewCommon
 			if tc.batchPending {
 				batchPending = []CodeReviewCommon{{}}
 			}
-			if act, _, _ := c.takeAction(sp, batchPending, genPulls(tc.successes), genPulls(tc.pendings), genPulls(tc.nones), genPulls(tc.batchMerges), sp.presubmits); act != tc.action {
+			if act, _, _ := c.takeAction(sp, batchPending, genPulls(tc.successes), genPulls(tc.pendings), genPulls(tc.nones), genPulls(tc.batchMerges), sp.presubmits, bisectSubBatches); act != tc.action {
 				t.Errorf("Wrong action. Got %v, wanted %v.", act, tc.action)
 			}
 